@@ -35,6 +35,9 @@ func main() {
 	themesPath := flag.String("themes", "themes.yaml", "Path to themes file")
 	showVersion := flag.Bool("version", false, "Show version and exit")
 	doUpdate := flag.Bool("update", false, "Check for updates and install if available")
+	doRollback := flag.Bool("rollback", false, "Restore the binary replaced by the last update")
+	requireSignature := flag.Bool("require-signature", false, "With -update, refuse an update whose signature cannot be verified")
+	channel := flag.String("channel", updater.ChannelStable, `With -update, the release channel to follow: "stable" or "beta"`)
 	flag.Parse()
 
 	if *showVersion {
@@ -42,8 +45,18 @@ func main() {
 		os.Exit(0)
 	}
 
+	if *doRollback {
+		if err := updater.Rollback(); err != nil {
+			fmt.Fprintf(os.Stderr, "Rollback failed: %s\n", err)
+			os.Exit(1)
+		}
+		fmt.Println("Restored the previous version. Restart the service to use it:")
+		fmt.Println("  sudo systemctl restart kibble")
+		os.Exit(0)
+	}
+
 	if *doUpdate {
-		runUpdate(version)
+		runUpdate(version, *channel, *requireSignature)
 		os.Exit(0)
 	}
 
@@ -70,15 +83,38 @@ func main() {
 
 	slog.Info("Starting Kibble", "version", version)
 
+	// A freshly updated binary that keeps failing to start is swapped back for the old one
+	if rolledBack, err := updater.CheckStartup(); err != nil {
+		slog.Warn("Failed to check update startup marker", "error", err)
+	} else if rolledBack {
+		if err := updater.RestartService(); err != nil {
+			slog.Error("Failed to restart after rollback", "error", err)
+		}
+		os.Exit(1)
+	}
+	time.AfterFunc(updater.StartupGracePeriod, func() {
+		if err := updater.MarkStartupOK(); err != nil {
+			slog.Warn("Failed to write update startup marker", "error", err)
+		}
+	})
+
 	// Initialize database
-	db, err := database.New(cfg.Database.Path)
+	dbSource := cfg.Database.Path
+	if cfg.Database.Driver == database.DriverPostgres {
+		dbSource = cfg.Database.DSN
+	}
+	db, err := database.New(cfg.Database.Driver, dbSource)
 	if err != nil {
 		slog.Error("Failed to initialize database", "error", err)
 		os.Exit(1)
 	}
 	defer db.Close()
 
-	slog.Info("Database initialized", "path", cfg.Database.Path)
+	if cfg.Database.Driver == database.DriverPostgres {
+		slog.Info("Database initialized", "driver", cfg.Database.Driver)
+	} else {
+		slog.Info("Database initialized", "path", cfg.Database.Path)
+	}
 
 	// Load color themes
 	themes, err := config.LoadThemes(*themesPath, kibble.ThemesYAML)
@@ -90,28 +126,35 @@ func main() {
 
 	// Initialize services
 	wikiClient := wikipedia.New()
-	aiClient := ai.NewClient(db, wikiClient)
-	sim := similarity.New(cfg.Similarity.Threshold, cfg.Similarity.NGramSize)
-	sc := scraper.New()
+	aiClient := ai.NewClient(db, db, wikiClient)
+	sim := similarity.New(cfg.Similarity.Threshold, cfg.Similarity.NGramSize, cfg.Similarity.EmbeddingThreshold)
+	sc := scraper.New(db)
 	sched := scheduler.New(db, aiClient, sim, sc)
 
 	// Build HTTP server
 	srv := server.New(cfg, db, aiClient, sim, sched, themes, version, buildTime)
+	sched.SetSnapshotWriter(srv)
 
 	// Start scheduler in background
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
-	go sched.Run(ctx)
+	schedDone := make(chan struct{})
+	go func() {
+		defer close(schedDone)
+		sched.Run(ctx)
+	}()
 
 	// Handle shutdown signals
 	sigCh := make(chan os.Signal, 1)
 	signal.Notify(sigCh, syscall.SIGINT, syscall.SIGTERM)
 
+	stopped := make(chan struct{})
 	go func() {
 		<-sigCh
 		slog.Info("Shutting down...")
 		cancel()
 		srv.Shutdown(context.Background())
+		close(stopped)
 	}()
 
 	// Start serving
@@ -120,15 +163,27 @@ func main() {
 		slog.Error("Server error", "error", err)
 		os.Exit(1)
 	}
+	<-stopped
+
+	// Let the scheduler finish the refreshes it was running, then deliver the
+	// notifications for content they stored before closing the database
+	select {
+	case <-schedDone:
+	case <-time.After(scheduler.DrainTimeout):
+		slog.Warn("Scheduler did not stop in time", "timeout", scheduler.DrainTimeout)
+	}
+	if sched.Drain(scheduler.DrainTimeout) {
+		slog.Info("Shutdown complete")
+	}
 }
 
-func runUpdate(currentVersion string) {
+func runUpdate(currentVersion, channel string, requireSignature bool) {
 	fmt.Printf("Kibble %s — checking for updates...\n", currentVersion)
 
 	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
 	defer cancel()
 
-	info, err := updater.CheckForUpdate(ctx, currentVersion)
+	info, err := updater.CheckForUpdate(ctx, currentVersion, channel)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Update check failed: %s\n", err)
 		os.Exit(1)
@@ -140,9 +195,12 @@ func runUpdate(currentVersion string) {
 
 	fmt.Printf("Update available: %s → %s\n", currentVersion, info.TagName)
 	fmt.Printf("Binary: %s (%s)\n", info.AssetName, updater.FormatBytes(info.AssetSize))
+	if notes := updater.NotesText(info.Body); notes != "" {
+		fmt.Printf("\nRelease notes:\n%s\n\n", notes)
+	}
 	fmt.Printf("Downloading...\n")
 
-	result, err := updater.DownloadAndInstall(ctx, info, currentVersion)
+	result, err := updater.DownloadAndInstall(ctx, info, currentVersion, requireSignature)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Installation failed: %s\n", err)
 		os.Exit(1)
@@ -10,3 +10,6 @@ var TemplateFS embed.FS
 
 //go:embed themes.yaml
 var ThemesYAML []byte
+
+//go:embed examples.yaml
+var ExamplesYAML []byte
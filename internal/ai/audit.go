@@ -0,0 +1,177 @@
+package ai
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+	"time"
+	"unicode/utf8"
+)
+
+const (
+	// auditMaxBytes is the size at which the audit log is rotated to "<path>.1".
+	auditMaxBytes = 10 << 20
+	// auditMaxChars caps the prompt and response text stored per entry.
+	auditMaxChars = 4000
+)
+
+// auditEntry is one line of the AI audit log.
+type auditEntry struct {
+	Timestamp  time.Time `json:"timestamp"`
+	Provider   string    `json:"provider"`
+	Model      string    `json:"model,omitempty"`
+	Prompt     string    `json:"prompt"`
+	Response   string    `json:"response,omitempty"`
+	TokensUsed int       `json:"tokens_used"`
+	DurationMs int64     `json:"duration_ms"`
+	Error      string    `json:"error,omitempty"`
+}
+
+// auditLogger appends a JSON line per provider Chat call to the file named by the
+// ai_audit_log setting. Auditing is off while the setting is empty.
+type auditLogger struct {
+	settings SettingsGetter
+	mu       sync.Mutex
+	// checkedPath and checkErr cache the last CheckAuditLogPath result, so
+	// the file is only inspected when the setting changes
+	checkedPath string
+	checkErr    error
+}
+
+// auditedProvider wraps a Provider so every Chat call is recorded in the audit log.
+type auditedProvider struct {
+	Provider
+	audit *auditLogger
+}
+
+func (p auditedProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	path, _ := p.audit.settings.GetSetting("ai_audit_log")
+	if path == "" {
+		return p.Provider.Chat(ctx, req)
+	}
+
+	start := time.Now()
+	resp, err := p.Provider.Chat(ctx, req)
+
+	prompts := make([]string, len(req.Messages))
+	for i, m := range req.Messages {
+		prompts[i] = m.Content
+	}
+	entry := auditEntry{
+		Timestamp:  start.UTC(),
+		Provider:   p.Name(),
+		Prompt:     truncateForAudit(strings.Join(prompts, "\n\n")),
+		DurationMs: time.Since(start).Milliseconds(),
+	}
+	if resp != nil {
+		entry.Model = resp.Model
+		entry.Response = truncateForAudit(resp.Content)
+		entry.TokensUsed = resp.TokensUsed
+	}
+	if err != nil {
+		entry.Error = err.Error()
+	}
+	p.audit.write(path, entry)
+
+	return resp, err
+}
+
+// write appends entry to the log at path, first rotating the file if the new line
+// would take it past auditMaxBytes. Failures are logged and otherwise ignored so
+// auditing never breaks generation.
+func (a *auditLogger) write(path string, entry auditEntry) {
+	line, err := json.Marshal(entry)
+	if err != nil {
+		slog.Warn("Failed to encode AI audit entry", "error", err)
+		return
+	}
+	line = append(line, '\n')
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	// Never append to or rotate a file that isn't an audit log, such as a
+	// database the setting was pointed at by mistake
+	if path != a.checkedPath {
+		a.checkedPath, a.checkErr = path, CheckAuditLogPath(path)
+		if a.checkErr != nil {
+			slog.Warn("Not writing AI audit log", "path", path, "error", a.checkErr)
+		}
+	}
+	if a.checkErr != nil {
+		return
+	}
+
+	if info, err := os.Stat(path); err == nil && info.Size()+int64(len(line)) > auditMaxBytes {
+		if err := os.Rename(path, path+".1"); err != nil {
+			slog.Warn("Failed to rotate AI audit log", "path", path, "error", err)
+		}
+	}
+
+	f, err := os.OpenFile(path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0600)
+	if err != nil {
+		slog.Warn("Failed to open AI audit log", "path", path, "error", err)
+		return
+	}
+	defer f.Close()
+	if _, err := f.Write(line); err != nil {
+		slog.Warn("Failed to write AI audit log", "path", path, "error", err)
+	}
+}
+
+// CheckAuditLogPath returns an error unless path is safe to use as the AI audit
+// log: a file that does not exist yet, an empty file, or an existing audit log.
+// The log is appended to and renamed when it rotates, so pointing it at any
+// other file would damage that file.
+func CheckAuditLogPath(path string) error {
+	f, err := os.Open(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	info, err := f.Stat()
+	if err != nil {
+		return err
+	}
+	if !info.Mode().IsRegular() {
+		return fmt.Errorf("%s is not a regular file", path)
+	}
+	if info.Size() == 0 {
+		return nil
+	}
+
+	// An entry's text is capped at auditMaxChars, so its first line is well under 1 MB
+	line, err := bufio.NewReader(io.LimitReader(f, 1<<20)).ReadBytes('\n')
+	if err != nil && err != io.EOF {
+		return err
+	}
+	var entry auditEntry
+	if json.Unmarshal(line, &entry) != nil || entry.Timestamp.IsZero() || entry.Provider == "" {
+		return fmt.Errorf("%s already exists and is not an AI audit log", path)
+	}
+	return nil
+}
+
+// truncateForAudit shortens s to auditMaxChars bytes without splitting a UTF-8 character.
+func truncateForAudit(s string) string {
+	if len(s) <= auditMaxChars {
+		return s
+	}
+	n := auditMaxChars
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	return s[:n] + "...[truncated]"
+}
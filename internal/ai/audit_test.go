@@ -0,0 +1,60 @@
+package ai
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestCheckAuditLogPath(t *testing.T) {
+	dir := t.TempDir()
+	write := func(name, content string) string {
+		path := filepath.Join(dir, name)
+		if err := os.WriteFile(path, []byte(content), 0600); err != nil {
+			t.Fatal(err)
+		}
+		return path
+	}
+
+	tests := []struct {
+		name    string
+		path    string
+		wantErr bool
+	}{
+		{"Missing file", filepath.Join(dir, "new.jsonl"), false},
+		{"Empty file", write("empty.jsonl", ""), false},
+		{"Audit log", write("audit.jsonl", `{"timestamp":"2026-01-02T03:04:05Z","provider":"gemini","prompt":"hi","tokens_used":3,"duration_ms":10}`+"\n"), false},
+		{"Database", write("kibble.db", "SQLite format 3\x00\x10\x00\x01\x01"), true},
+		{"Other JSON", write("config.json", `{"name":"kibble"}`+"\n"), true},
+		{"Directory", dir, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := CheckAuditLogPath(tt.path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("CheckAuditLogPath(%q) error = %v, wantErr %v", tt.path, err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestAuditWriteLeavesOtherFilesAlone(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "kibble.db")
+	data := make([]byte, auditMaxBytes)
+	copy(data, "SQLite format 3\x00")
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		t.Fatal(err)
+	}
+
+	a := &auditLogger{}
+	a.write(path, auditEntry{Timestamp: time.Now(), Provider: "gemini", Prompt: "hi"})
+
+	if _, err := os.Stat(path + ".1"); !os.IsNotExist(err) {
+		t.Errorf("write() rotated %s, want it left in place", path)
+	}
+	if info, err := os.Stat(path); err != nil || info.Size() != auditMaxBytes {
+		t.Errorf("write() changed %s (err %v), want it untouched", path, err)
+	}
+}
@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// ResponseCache persists AI responses for identical requests. database.DB implements it.
+type ResponseCache interface {
+	GetCachedAIResponse(key string, maxAge time.Duration) (content, model string, tokensUsed int, ok bool, err error)
+	PutCachedAIResponse(key, provider, model, content string, tokensUsed int, maxAge time.Duration) error
+}
+
+// cachedProvider wraps a Provider so identical requests within the
+// ai_cache_ttl_minutes window are answered from the cache instead of the model.
+// Caching is off while the setting is 0 or unset.
+type cachedProvider struct {
+	Provider
+	cache    ResponseCache
+	settings SettingsGetter
+}
+
+func (p cachedProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	ttl := p.ttl()
+	if ttl <= 0 || p.cache == nil {
+		return p.Provider.Chat(ctx, req)
+	}
+
+	key := p.cacheKey(req)
+	content, model, _, ok, err := p.cache.GetCachedAIResponse(key, ttl)
+	if err != nil {
+		slog.Warn("Failed to read AI response cache", "error", err)
+	}
+	if ok {
+		slog.Debug("AI response served from cache", "provider", p.Name(), "model", model)
+		// No tokens were spent on a cache hit, so none are reported
+		return &ChatResponse{Content: content, Model: model, Provider: p.Name()}, nil
+	}
+
+	resp, err := p.Provider.Chat(ctx, req)
+	if err != nil {
+		return resp, err
+	}
+	if err := p.cache.PutCachedAIResponse(key, p.Name(), resp.Model, resp.Content, resp.TokensUsed, ttl); err != nil {
+		slog.Warn("Failed to write AI response cache", "error", err)
+	}
+	return resp, nil
+}
+
+// ttl returns the ai_cache_ttl_minutes setting as a duration, or 0 when caching is off.
+func (p cachedProvider) ttl() time.Duration {
+	v, _ := p.settings.GetSetting("ai_cache_ttl_minutes")
+	n, err := strconv.Atoi(v)
+	if err != nil || n <= 0 {
+		return 0
+	}
+	return time.Duration(n) * time.Minute
+}
+
+// cacheKey hashes everything that can change a response: the provider, its
+// configured model, the sampling parameters, and every message.
+func (p cachedProvider) cacheKey(req ChatRequest) string {
+	model, _ := p.settings.GetSetting(p.Name() + "_model")
+
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00%g\x00%d\x00%t\x00", p.Name(), model, req.Temperature, req.MaxTokens, req.JSONMode)
+	for _, m := range req.Messages {
+		fmt.Fprintf(h, "%s\x00%d\x00%s\x00", m.Role, len(m.Content), m.Content)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
@@ -0,0 +1,62 @@
+package ai
+
+import (
+	"context"
+	"testing"
+	"time"
+)
+
+type mapSettings map[string]string
+
+func (m mapSettings) GetSetting(key string) (string, error) { return m[key], nil }
+
+type memCache map[string]string
+
+func (m memCache) GetCachedAIResponse(key string, maxAge time.Duration) (string, string, int, bool, error) {
+	content, ok := m[key]
+	return content, "test-model", 0, ok, nil
+}
+
+func (m memCache) PutCachedAIResponse(key, provider, model, content string, tokensUsed int, maxAge time.Duration) error {
+	m[key] = content
+	return nil
+}
+
+type countingProvider struct{ calls *int }
+
+func (p countingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	*p.calls++
+	return &ChatResponse{Content: req.Messages[0].Content + " reply", TokensUsed: 10, Model: "test-model", Provider: "gemini"}, nil
+}
+
+func (p countingProvider) Name() string { return "gemini" }
+
+func TestCachedProvider(t *testing.T) {
+	var calls int
+	settings := mapSettings{"ai_cache_ttl_minutes": "5"}
+	p := cachedProvider{Provider: countingProvider{&calls}, cache: memCache{}, settings: settings}
+	req := func(prompt string, temp float64) ChatRequest {
+		return ChatRequest{Messages: []Message{{Role: "user", Content: prompt}}, Temperature: temp}
+	}
+
+	first, _ := p.Chat(context.Background(), req("facts about owls", 0.7))
+	second, _ := p.Chat(context.Background(), req("facts about owls", 0.7))
+	if calls != 1 {
+		t.Fatalf("identical requests made %d provider calls, want 1", calls)
+	}
+	if second.Content != first.Content || second.TokensUsed != 0 {
+		t.Errorf("cache hit = %q (%d tokens), want %q (0 tokens)", second.Content, second.TokensUsed, first.Content)
+	}
+
+	p.Chat(context.Background(), req("facts about owls", 0.2))
+	p.Chat(context.Background(), req("facts about bats", 0.7))
+	if calls != 3 {
+		t.Errorf("changed prompt or temperature made %d total calls, want 3", calls)
+	}
+
+	settings["ai_cache_ttl_minutes"] = "0"
+	p.Chat(context.Background(), req("facts about owls", 0.7))
+	if calls != 4 {
+		t.Errorf("disabled cache made %d total calls, want 4", calls)
+	}
+}
@@ -33,7 +33,7 @@ func (c *ChutesProvider) Name() string { return "chutes" }
 func (c *ChutesProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	apiKey, err := c.settings.GetSetting("chutes_api_key")
 	if err != nil || strings.TrimSpace(apiKey) == "" {
-		return nil, fmt.Errorf("chutes API key not configured — set it in Settings")
+		return nil, &MissingKeyError{Provider: "chutes"}
 	}
 	apiKey = strings.TrimSpace(apiKey)
 
@@ -111,9 +111,11 @@ func (c *ChutesProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("parse chutes response: %w", err)
 	}
 
-	tokensUsed := 0
+	var tokensUsed, promptTokens, completionTokens int
 	if chatResp.Usage != nil {
 		tokensUsed = chatResp.Usage.TotalTokens
+		promptTokens = chatResp.Usage.PromptTokens
+		completionTokens = chatResp.Usage.CompletionTokens
 	}
 
 	content := ""
@@ -124,10 +126,12 @@ func (c *ChutesProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	slog.Info("Chutes request completed", "model", model, "elapsed", time.Since(start), "tokens", tokensUsed, "response_chars", len(content))
 
 	return &ChatResponse{
-		Content:    content,
-		TokensUsed: tokensUsed,
-		Model:      model,
-		Provider:   "chutes",
+		Content:          content,
+		TokensUsed:       tokensUsed,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		Model:            model,
+		Provider:         "chutes",
 	}, nil
 }
 
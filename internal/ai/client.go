@@ -1,11 +1,15 @@
 package ai
 
 import (
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"log/slog"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/thinkscotty/kibble/internal/feeds"
 	"github.com/thinkscotty/kibble/internal/wikipedia"
@@ -17,48 +21,128 @@ type Client struct {
 	gemini   *GeminiProvider
 	ollama   *OllamaProvider
 	chutes   *ChutesProvider
+	openai   *OpenAIProvider
 	settings SettingsGetter
 	wiki     *wikipedia.Client
+	audit    *auditLogger
+	cache    ResponseCache
 }
 
-// NewClient creates an AI client with all providers and optional Wikipedia client.
-func NewClient(sg SettingsGetter, wiki *wikipedia.Client) *Client {
+// NewClient creates an AI client with all providers, an optional response cache,
+// and an optional Wikipedia client.
+func NewClient(sg SettingsGetter, cache ResponseCache, wiki *wikipedia.Client) *Client {
 	return &Client{
 		gemini:   NewGeminiProvider(sg),
 		ollama:   NewOllamaProvider(sg),
 		chutes:   NewChutesProvider(sg),
+		openai:   NewOpenAIProvider(sg),
 		settings: sg,
 		wiki:     wiki,
+		audit:    &auditLogger{settings: sg},
+		cache:    cache,
 	}
 }
 
 // resolveProvider returns the correct provider based on per-topic override or global setting.
-// topicProvider: "" means use global default, "gemini", "ollama", or "chutes" selects that provider.
+// topicProvider: "" means a weighted pick from ai_provider_weights when set, else the global
+// default; "gemini", "ollama", "chutes", or "openai" selects that provider.
 func (c *Client) resolveProvider(topicProvider string) Provider {
 	provider := topicProvider
+	if provider == "" {
+		provider = c.weightedProvider()
+	}
 	if provider == "" {
 		provider, _ = c.settings.GetSetting("ai_provider")
 	}
 
+	var p Provider
 	switch provider {
 	case "ollama":
-		return c.ollama
+		p = c.ollama
 	case "chutes":
-		return c.chutes
+		p = c.chutes
+	case "openai":
+		p = c.openai
 	default:
-		return c.gemini
+		p = c.gemini
+	}
+	// Cache hits skip the audit log and cost nothing, since no request reaches
+	// the model. Each retried attempt is audited and costed separately.
+	return cachedProvider{
+		Provider: retryingProvider{
+			Provider: meteredProvider{
+				Provider: auditedProvider{Provider: p, audit: c.audit},
+				settings: c.settings,
+			},
+			settings: c.settings,
+		},
+		cache:    c.cache,
+		settings: c.settings,
 	}
 }
 
+// applyPromptPolicy wraps a generation prompt in the global_prompt_prefix and
+// global_prompt_suffix settings, so policy text reaches every fact, story, and
+// source discovery request whatever the topic's own instructions say.
+func (c *Client) applyPromptPolicy(prompt string) string {
+	prefix, _ := c.settings.GetSetting("global_prompt_prefix")
+	suffix, _ := c.settings.GetSetting("global_prompt_suffix")
+	return WrapPrompt(prompt, prefix, suffix)
+}
+
+// ContentProvider returns the provider name used for a content type (ContentFacts
+// or ContentNews): the topic's override, then the ai_provider_facts or
+// ai_provider_news setting. "" means the global ai_provider.
+func (c *Client) ContentProvider(contentType, topicProvider string) string {
+	if topicProvider != "" {
+		return topicProvider
+	}
+	p, _ := c.settings.GetSetting("ai_provider_" + contentType)
+	return p
+}
+
+// HasAPIKey reports whether the named provider has the API key it needs.
+// Ollama runs locally and needs none.
+func (c *Client) HasAPIKey(provider string) bool {
+	var key string
+	switch provider {
+	case "gemini":
+		key, _ = c.settings.GetSetting("gemini_api_key")
+	case "chutes":
+		key, _ = c.settings.GetSetting("chutes_api_key")
+	case "openai":
+		key, _ = c.settings.GetSetting("openai_api_key")
+	default:
+		return true
+	}
+	return strings.TrimSpace(key) != ""
+}
+
+// DiscoveryProvider returns the provider name used for source discovery: the
+// topic's discovery override, then the global discovery_provider setting, then
+// the topic's news provider. "" means the global ai_provider.
+func (c *Client) DiscoveryProvider(opts DiscoverOpts) string {
+	if opts.DiscoveryProvider != "" {
+		return opts.DiscoveryProvider
+	}
+	if p, _ := c.settings.GetSetting("discovery_provider"); p != "" {
+		return p
+	}
+	return c.ContentProvider(ContentNews, opts.AIProvider)
+}
+
 // GenerateFacts generates facts for a topic.
-// If the topic is marked as niche and a Wikipedia client is available,
-// it automatically performs research and uses a RAG-augmented prompt.
+// If the topic is marked as niche (and research isn't turned off for it) and a
+// Wikipedia client is available, it automatically performs research and uses a
+// RAG-augmented prompt, attaching the cited Wikipedia article to any fact
+// grounded in the research.
 // Returns: facts, tokensUsed, providerName, modelName, error.
-func (c *Client) GenerateFacts(ctx context.Context, opts FactsOpts) ([]string, int, string, string, error) {
-	provider := c.resolveProvider(opts.AIProvider)
+func (c *Client) GenerateFacts(ctx context.Context, opts FactsOpts) ([]GeneratedFact, int, string, string, error) {
+	provider := c.resolveProvider(c.ContentProvider(ContentFacts, opts.AIProvider))
 
 	var prompt string
-	if opts.IsNiche && c.wiki != nil {
+	var researchTitles []string
+	if opts.IsNiche && !opts.SkipResearch && c.wiki != nil {
 		researchCtx, err := c.ResearchTopic(ctx, provider, opts.Topic, opts.Description)
 		if err != nil {
 			slog.Warn("Wikipedia research failed, falling back to standard prompt", "topic", opts.Topic, "error", err)
@@ -68,8 +152,10 @@ func (c *Client) GenerateFacts(ctx context.Context, opts FactsOpts) ([]string, i
 				opts.Topic, opts.Description,
 				opts.CustomInstructions, opts.ToneInstructions,
 				opts.Count, opts.MinWords, opts.MaxWords,
+				opts.SeriesMode, opts.SeriesSoFar,
 				researchCtx,
 			)
+			researchTitles = ResearchTitles(researchCtx)
 		}
 	}
 	if prompt == "" {
@@ -77,31 +163,117 @@ func (c *Client) GenerateFacts(ctx context.Context, opts FactsOpts) ([]string, i
 			opts.Topic, opts.Description,
 			opts.CustomInstructions, opts.ToneInstructions,
 			opts.Count, opts.MinWords, opts.MaxWords,
+			opts.SeriesMode, opts.SeriesSoFar,
 		)
 	}
+	if opts.RequireVerifiable {
+		prompt += BuildVerifiableInstructions()
+	}
+	prompt += BuildFactsExclusion(opts.ExcludeFacts)
 
 	resp, err := provider.Chat(ctx, ChatRequest{
-		Messages:    []Message{{Role: "user", Content: prompt}},
-		Temperature: 0.9,
-		MaxTokens:   2048,
+		Messages:    []Message{{Role: "user", Content: c.applyPromptPolicy(prompt)}},
+		Temperature: cmp.Or(opts.Temperature, DefaultFactsTemperature),
+		MaxTokens:   cmp.Or(opts.MaxTokens, DefaultFactsMaxTokens),
 	})
 	if err != nil {
 		return nil, 0, provider.Name(), "", err
 	}
 
-	facts := ParseFactsFromText(resp.Content)
-	if len(facts) == 0 {
+	lines := ParseFactsFromText(resp.Content)
+	if len(lines) == 0 {
 		return nil, resp.TokensUsed, resp.Provider, resp.Model,
 			fmt.Errorf("empty response from %s: no parseable facts returned", provider.Name())
 	}
+
+	facts := make([]GeneratedFact, 0, len(lines))
+	for _, line := range lines {
+		content, confidence := ExtractFactConfidence(line)
+		content, cited := ExtractFactCitation(content)
+		fact := GeneratedFact{Content: content}
+		if opts.RequireVerifiable {
+			fact.Confidence = confidence
+		}
+		// Only keep citations that match an article we actually supplied
+		for _, title := range researchTitles {
+			if cited != "" && strings.EqualFold(cited, title) {
+				fact.SourceTitle = "Wikipedia – " + title
+				fact.SourceURL = wikipedia.ArticleURL(title)
+				break
+			}
+		}
+		facts = append(facts, fact)
+	}
+	if len(facts) < opts.Count {
+		slog.Info("AI returned fewer facts than requested", "topic", opts.Topic,
+			"requested", opts.Count, "returned", len(facts), "provider", resp.Provider)
+	}
 	return facts, resp.TokensUsed, resp.Provider, resp.Model, nil
 }
 
-// DiscoverSources uses AI to find news sources for a topic.
+// ScoreFactRelevance asks the AI to rate each fact's relevance to the topic.
+// Scores are returned on a 0–1 scale in the same order as facts.
+// Returns: scores, tokensUsed, error.
+func (c *Client) ScoreFactRelevance(ctx context.Context, opts FactsOpts, facts []string) ([]float64, int, error) {
+	if len(facts) == 0 {
+		return nil, 0, nil
+	}
+
+	provider := c.resolveProvider(c.ContentProvider(ContentFacts, opts.AIProvider))
+	resp, err := provider.Chat(ctx, ChatRequest{
+		Messages:    []Message{{Role: "user", Content: BuildRelevancePrompt(opts.Topic, opts.Description, facts)}},
+		Temperature: 0.1,
+		MaxTokens:   256,
+	})
+	if err != nil {
+		return nil, 0, err
+	}
+
+	lines := ParseFactsFromText(resp.Content) // reuse numbered-list parser
+	if len(lines) != len(facts) {
+		return nil, resp.TokensUsed, fmt.Errorf("expected %d relevance scores from %s, got %d", len(facts), provider.Name(), len(lines))
+	}
+
+	scores := make([]float64, len(lines))
+	for i, line := range lines {
+		n, err := strconv.ParseFloat(strings.TrimSuffix(strings.Fields(line)[0], "/10"), 64)
+		if err != nil {
+			return nil, resp.TokensUsed, fmt.Errorf("invalid relevance score %q from %s", line, provider.Name())
+		}
+		scores[i] = min(max(n/10, 0), 1)
+	}
+	return scores, resp.TokensUsed, nil
+}
+
+// SynthesizeOverview asks the facts provider for one paragraph summarizing a
+// topic's recent facts, rather than generating new ones.
+// Returns: overview, tokensUsed, providerName, modelName, error.
+func (c *Client) SynthesizeOverview(ctx context.Context, opts FactsOpts, facts []string) (string, int, string, string, error) {
+	provider := c.resolveProvider(c.ContentProvider(ContentFacts, opts.AIProvider))
+	prompt := BuildOverviewPrompt(opts.Topic, opts.Description, opts.ToneInstructions, facts)
+
+	resp, err := provider.Chat(ctx, ChatRequest{
+		Messages:    []Message{{Role: "user", Content: c.applyPromptPolicy(prompt)}},
+		Temperature: 0.5,
+		MaxTokens:   1024,
+	})
+	if err != nil {
+		return "", 0, provider.Name(), "", err
+	}
+
+	overview := strings.TrimSpace(resp.Content)
+	if overview == "" {
+		return "", resp.TokensUsed, resp.Provider, resp.Model, fmt.Errorf("empty response from %s", provider.Name())
+	}
+	return overview, resp.TokensUsed, resp.Provider, resp.Model, nil
+}
+
+// DiscoverSources uses AI to find news sources for a topic, using the discovery
+// provider rather than the topic's main provider when one is configured.
 // If the topic is marked as niche and a Wikipedia client is available,
 // it automatically performs research and uses a RAG-augmented prompt.
 func (c *Client) DiscoverSources(ctx context.Context, opts DiscoverOpts) ([]DiscoveredSource, int, string, string, error) {
-	provider := c.resolveProvider(opts.AIProvider)
+	provider := c.resolveProvider(c.DiscoveryProvider(opts))
 
 	suggested := feeds.FindRelevant(opts.TopicName, opts.Description)
 
@@ -118,7 +290,51 @@ func (c *Client) DiscoverSources(ctx context.Context, opts DiscoverOpts) ([]Disc
 	if prompt == "" {
 		prompt = BuildDiscoverPrompt(opts.TopicName, opts.Description, opts.SourcingInstructions, suggested, opts.CommunityDomains)
 	}
+	prompt = c.applyPromptPolicy(prompt)
 
+	sources, tokens, providerName, model, err := discoverWith(ctx, provider, prompt)
+	if hasSourceURL(sources) || ctx.Err() != nil {
+		return sources, tokens, providerName, model, err
+	}
+
+	fallback := c.discoveryFallback(provider.Name())
+	if fallback == "" {
+		return sources, tokens, providerName, model, err
+	}
+	slog.Warn("Source discovery found no sources, retrying with fallback provider",
+		"topic", opts.TopicName, "provider", provider.Name(), "fallback", fallback, "error", err)
+	sources, fallbackTokens, providerName, model, err := discoverWith(ctx, c.resolveProvider(fallback), prompt)
+	return sources, tokens + fallbackTokens, providerName, model, err
+}
+
+// discoveryFallback returns the discovery_fallback_provider setting, the
+// provider to retry source discovery with when primary finds nothing. It is ""
+// when unset, the same as primary, or missing its API key.
+func (c *Client) discoveryFallback(primary string) string {
+	fallback, _ := c.settings.GetSetting("discovery_fallback_provider")
+	if fallback == "" || fallback == primary {
+		return ""
+	}
+	if !c.HasAPIKey(fallback) {
+		slog.Warn("Discovery fallback provider has no API key, skipping", "provider", fallback)
+		return ""
+	}
+	return fallback
+}
+
+// hasSourceURL reports whether any discovered source has a URL.
+func hasSourceURL(sources []DiscoveredSource) bool {
+	for _, src := range sources {
+		if strings.TrimSpace(src.URL) != "" {
+			return true
+		}
+	}
+	return false
+}
+
+// discoverWith sends a source discovery prompt to provider and parses the
+// sources from its response.
+func discoverWith(ctx context.Context, provider Provider, prompt string) ([]DiscoveredSource, int, string, string, error) {
 	resp, err := provider.Chat(ctx, ChatRequest{
 		Messages:    []Message{{Role: "user", Content: prompt}},
 		Temperature: 0.7,
@@ -166,19 +382,19 @@ func (c *Client) SummarizeContent(ctx context.Context, opts SummarizeOpts) ([]Su
 		return nil, 0, "", "", nil
 	}
 
-	provider := c.resolveProvider(opts.AIProvider)
+	provider := c.resolveProvider(c.ContentProvider(ContentNews, opts.AIProvider))
 
 	prompt := BuildSummarizePrompt(
 		opts.TopicName, opts.ScrapedContent,
-		opts.SummarizingInstructions, opts.ToneInstructions,
+		opts.SummarizingInstructions, VoiceInstructions(opts.Voice, opts.ToneInstructions),
 		opts.MaxStories, opts.MinWords, opts.MaxWords,
-		opts.ExistingTitles,
+		opts.ExistingTitles, opts.RelaxedFiltering, opts.ContentFocus, opts.IncludeQuotes,
 	)
 
 	resp, err := provider.Chat(ctx, ChatRequest{
-		Messages:    []Message{{Role: "user", Content: prompt}},
-		Temperature: 0.7,
-		MaxTokens:   4096,
+		Messages:    []Message{{Role: "user", Content: c.applyPromptPolicy(prompt)}},
+		Temperature: cmp.Or(opts.Temperature, DefaultSummarizeTemperature),
+		MaxTokens:   cmp.Or(opts.MaxTokens, DefaultSummarizeMaxTokens),
 		JSONMode:    true,
 	})
 	if err != nil {
@@ -239,6 +455,12 @@ func (c *Client) TestChutesKey(ctx context.Context, apiKey string) error {
 	return TestChutesKey(ctx, apiKey, model)
 }
 
+// TestOpenAIKey verifies an OpenAI API key.
+func (c *Client) TestOpenAIKey(ctx context.Context, apiKey string) error {
+	model, _ := c.settings.GetSetting("openai_model")
+	return TestOpenAIKey(ctx, apiKey, model)
+}
+
 // GenerateSearchQueries asks the AI to produce search queries for researching a topic.
 func (c *Client) GenerateSearchQueries(ctx context.Context, provider Provider, topicName, description string) ([]string, error) {
 	prompt := fmt.Sprintf(
@@ -266,8 +488,17 @@ Make queries specific enough to find Wikipedia articles or authoritative sources
 	return queries, nil
 }
 
+// Limits for the Wikipedia research context built by ResearchTopic.
+const (
+	researchResultsPerQuery = 5    // search hits considered per query
+	researchMaxArticles     = 5    // article summaries included
+	researchMaxChars        = 4000 // stop adding summaries past this length
+)
+
 // ResearchTopic uses AI-generated search queries to find Wikipedia articles,
 // then fetches summaries to build a context block for RAG-augmented prompts.
+// Queries are searched concurrently and the combined results are ranked with
+// RankResearchTitles, so the context holds the most relevant articles.
 func (c *Client) ResearchTopic(ctx context.Context, provider Provider, topicName, description string) (string, error) {
 	if c.wiki == nil {
 		return "", fmt.Errorf("wikipedia client not available")
@@ -281,34 +512,41 @@ func (c *Client) ResearchTopic(ctx context.Context, provider Provider, topicName
 
 	slog.Debug("Researching niche topic", "topic", topicName, "queries", len(queries))
 
-	// Step 2: Search Wikipedia for each query
-	seen := make(map[string]bool)
-	var titles []string
-	for _, query := range queries {
-		results, err := c.wiki.Search(ctx, query, 3)
-		if err != nil {
-			slog.Debug("Wikipedia search failed", "query", query, "error", err)
-			continue
-		}
-		for _, r := range results {
-			if !seen[r.Title] {
-				seen[r.Title] = true
-				titles = append(titles, r.Title)
+	// Step 2: Search Wikipedia for every query at once. Each query's results keep
+	// their slot so ranking doesn't depend on which search finishes first.
+	results := make([][]wikipedia.SearchResult, len(queries))
+	sem := make(chan struct{}, c.wikiSearchConcurrency())
+	var wg sync.WaitGroup
+	for i, query := range queries {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			hits, err := c.wiki.Search(ctx, query, researchResultsPerQuery)
+			if err != nil {
+				slog.Debug("Wikipedia search failed", "query", query, "error", err)
+				return
 			}
-		}
+			results[i] = hits
+		}()
 	}
+	wg.Wait()
 
+	titles := RankResearchTitles(results)
 	if len(titles) == 0 {
 		return "", fmt.Errorf("no Wikipedia articles found for %q", topicName)
 	}
 
-	// Step 3: Fetch summaries for top 5 unique articles
-	if len(titles) > 5 {
-		titles = titles[:5]
-	}
-
+	// Step 3: Fetch summaries for the top-ranked articles, moving down the
+	// ranking when a summary can't be fetched
 	var sb strings.Builder
+	articles := 0
 	for _, title := range titles {
+		if articles == researchMaxArticles || sb.Len() > researchMaxChars {
+			break
+		}
 		summary, err := c.wiki.GetSummary(ctx, title)
 		if err != nil {
 			slog.Debug("Failed to get Wikipedia summary", "title", title, "error", err)
@@ -316,11 +554,7 @@ func (c *Client) ResearchTopic(ctx context.Context, provider Provider, topicName
 		}
 		sb.WriteString(summary)
 		sb.WriteString("\n\n")
-
-		// Cap at ~4000 characters
-		if sb.Len() > 4000 {
-			break
-		}
+		articles++
 	}
 
 	result := strings.TrimSpace(sb.String())
@@ -328,6 +562,38 @@ func (c *Client) ResearchTopic(ctx context.Context, provider Provider, topicName
 		return "", fmt.Errorf("no Wikipedia summaries retrieved for %q", topicName)
 	}
 
-	slog.Info("Wikipedia research complete", "topic", topicName, "articles", len(titles), "chars", len(result))
+	slog.Info("Wikipedia research complete", "topic", topicName, "articles", articles, "candidates", len(titles), "chars", len(result))
 	return result, nil
 }
+
+// wikiSearchConcurrency returns the wiki_search_concurrency setting, clamped to 1..8.
+func (c *Client) wikiSearchConcurrency() int {
+	v, _ := c.settings.GetSetting("wiki_search_concurrency")
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return 1
+	}
+	return min(n, 8)
+}
+
+// RankResearchTitles merges the Wikipedia results of several search queries into
+// a single list of unique titles, most relevant first. A title scores one point
+// for each query that returned it plus 1/rank for its position in that query's
+// results, so articles several queries agree on beat a single top hit. Ties keep
+// the order titles were first seen in.
+func RankResearchTitles(results [][]wikipedia.SearchResult) []string {
+	scores := make(map[string]float64)
+	var titles []string
+	for _, hits := range results {
+		for rank, r := range hits {
+			if _, ok := scores[r.Title]; !ok {
+				titles = append(titles, r.Title)
+			}
+			scores[r.Title] += 1 + 1/float64(rank+1)
+		}
+	}
+	slices.SortStableFunc(titles, func(a, b string) int {
+		return cmp.Compare(scores[b], scores[a])
+	})
+	return titles
+}
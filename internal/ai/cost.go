@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// ParseCostRates parses an ai_cost_per_1k_input or ai_cost_per_1k_output value:
+// "provider/model=price" entries separated by commas or newlines, where price is
+// US dollars per 1,000 tokens, e.g. "openai/gpt-4o-mini=0.00015, gemini=0.0003".
+// An entry without a model applies to all of that provider's models. An empty
+// value yields no rates. Unknown providers and prices that are not
+// non-negative numbers are errors.
+func ParseCostRates(s string) (map[string]float64, error) {
+	rates := make(map[string]float64)
+	for _, entry := range strings.FieldsFunc(s, func(r rune) bool { return r == ',' || r == '\n' }) {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		key, value, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected provider/model=price", entry)
+		}
+		key = strings.ToLower(strings.TrimSpace(key))
+		provider, _, _ := strings.Cut(key, "/")
+		switch provider {
+		case "gemini", "ollama", "chutes", "openai":
+		default:
+			return nil, fmt.Errorf("%q: unknown provider %q", entry, provider)
+		}
+		price, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+		if err != nil || price < 0 {
+			return nil, fmt.Errorf("%q: price must be a number of dollars, 0 or more", entry)
+		}
+		rates[key] = price
+	}
+	return rates, nil
+}
+
+// costRate returns the price per 1,000 tokens for a provider's model, preferring
+// a provider/model entry over a provider-wide one. It is 0 when neither is set.
+func costRate(rates map[string]float64, provider, model string) float64 {
+	provider = strings.ToLower(provider)
+	if price, ok := rates[provider+"/"+strings.ToLower(model)]; ok {
+		return price
+	}
+	return rates[provider]
+}
+
+// estimateCost returns the estimated cost in US dollars of a response, from the
+// ai_cost_per_1k_input and ai_cost_per_1k_output settings. Tokens the provider
+// did not split into prompt and completion are priced as output, so the
+// estimate errs high.
+func estimateCost(sg SettingsGetter, resp *ChatResponse) float64 {
+	v, _ := sg.GetSetting("ai_cost_per_1k_input")
+	inputRates, _ := ParseCostRates(v)
+	v, _ = sg.GetSetting("ai_cost_per_1k_output")
+	outputRates, _ := ParseCostRates(v)
+	if len(inputRates) == 0 && len(outputRates) == 0 {
+		return 0
+	}
+
+	prompt, completion := resp.PromptTokens, resp.CompletionTokens
+	if prompt+completion == 0 {
+		completion = resp.TokensUsed
+	}
+	return float64(prompt)/1000*costRate(inputRates, resp.Provider, resp.Model) +
+		float64(completion)/1000*costRate(outputRates, resp.Provider, resp.Model)
+}
+
+// Spend totals the estimated cost of the AI requests made with a context
+// returned by TrackSpend.
+type Spend struct {
+	mu  sync.Mutex
+	usd float64
+}
+
+type spendKey struct{}
+
+// TrackSpend returns a context that adds the estimated cost of every AI
+// request made with it to the returned Spend.
+func TrackSpend(ctx context.Context) (context.Context, *Spend) {
+	spend := &Spend{}
+	return context.WithValue(ctx, spendKey{}, spend), spend
+}
+
+// USD returns the estimated cost so far in US dollars.
+func (s *Spend) USD() float64 {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.usd
+}
+
+func (s *Spend) add(usd float64) {
+	s.mu.Lock()
+	s.usd += usd
+	s.mu.Unlock()
+}
+
+// meteredProvider wraps a Provider so the estimated cost of each response is
+// added to the Spend tracked by the request's context, if any.
+type meteredProvider struct {
+	Provider
+	settings SettingsGetter
+}
+
+func (p meteredProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	resp, err := p.Provider.Chat(ctx, req)
+	if spend, ok := ctx.Value(spendKey{}).(*Spend); ok && resp != nil {
+		spend.add(estimateCost(p.settings, resp))
+	}
+	return resp, err
+}
@@ -0,0 +1,81 @@
+package ai
+
+import (
+	"context"
+	"math"
+	"reflect"
+	"testing"
+)
+
+func TestParseCostRates(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    map[string]float64
+		wantErr bool
+	}{
+		{"Empty", "", map[string]float64{}, false},
+		{
+			"Models and providers",
+			"openai/gpt-4o-mini=0.00015\n Gemini = 0.0003 ,chutes/deepseek-ai/DeepSeek-V3=0.0002",
+			map[string]float64{"openai/gpt-4o-mini": 0.00015, "gemini": 0.0003, "chutes/deepseek-ai/deepseek-v3": 0.0002},
+			false,
+		},
+		{"Missing price", "openai/gpt-4o", nil, true},
+		{"Unknown provider", "anthropic=0.003", nil, true},
+		{"Negative price", "openai=-1", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseCostRates(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseCostRates(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseCostRates(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestMeteredProvider(t *testing.T) {
+	settings := mapSettings{
+		"ai_cost_per_1k_input":  "gemini=0.001, gemini/test-model=0.002",
+		"ai_cost_per_1k_output": "gemini=0.004",
+	}
+	resp := func(prompt, completion, total int) *ChatResponse {
+		return &ChatResponse{PromptTokens: prompt, CompletionTokens: completion, TokensUsed: total, Model: "test-model", Provider: "gemini"}
+	}
+
+	tests := []struct {
+		name string
+		resp *ChatResponse
+		want float64
+	}{
+		{"Split tokens use the model's input price", resp(1000, 500, 1500), 0.002 + 0.002},
+		{"Unsplit tokens priced as output", resp(0, 0, 2000), 0.008},
+		{"Other model uses provider price", &ChatResponse{PromptTokens: 1000, Model: "other", Provider: "gemini"}, 0.001},
+		{"Unpriced provider is free", &ChatResponse{PromptTokens: 1000, CompletionTokens: 1000, Provider: "ollama"}, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ctx, spend := TrackSpend(context.Background())
+			p := meteredProvider{Provider: fixedProvider{tt.resp}, settings: settings}
+			p.Chat(ctx, ChatRequest{})
+			p.Chat(ctx, ChatRequest{})
+			if got := spend.USD(); math.Abs(got-2*tt.want) > 1e-12 {
+				t.Errorf("spend after two requests = %v, want %v", got, 2*tt.want)
+			}
+		})
+	}
+}
+
+type fixedProvider struct{ resp *ChatResponse }
+
+func (p fixedProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	return p.resp, nil
+}
+
+func (p fixedProvider) Name() string { return p.resp.Provider }
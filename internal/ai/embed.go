@@ -0,0 +1,117 @@
+package ai
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+const (
+	openAIEmbeddingsURL         = "https://api.openai.com/v1/embeddings"
+	openAIDefaultEmbeddingModel = "text-embedding-3-small"
+	ollamaDefaultEmbeddingModel = "nomic-embed-text"
+)
+
+// Embed returns an embedding vector for text from the provider named by the
+// embedding_provider setting ("ollama" or "openai"), using the embedding_model
+// setting or that provider's default model. tokensUsed is 0 when the provider
+// does not report it.
+func (c *Client) Embed(ctx context.Context, text string) (vector []float32, tokensUsed int, err error) {
+	provider, _ := c.settings.GetSetting("embedding_provider")
+	model, _ := c.settings.GetSetting("embedding_model")
+	model = strings.TrimSpace(model)
+
+	if provider == "openai" {
+		return c.openai.Embed(ctx, cmp.Or(model, openAIDefaultEmbeddingModel), text)
+	}
+	return c.ollama.Embed(ctx, cmp.Or(model, ollamaDefaultEmbeddingModel), text)
+}
+
+// Embed requests an embedding from Ollama's /api/embeddings endpoint, which
+// does not report token usage.
+func (o *OllamaProvider) Embed(ctx context.Context, model, text string) ([]float32, int, error) {
+	baseURL, err := o.settings.GetSetting("ollama_url")
+	if err != nil || strings.TrimSpace(baseURL) == "" {
+		baseURL = "http://localhost:11434"
+	}
+	url := strings.TrimRight(strings.TrimSpace(baseURL), "/") + "/api/embeddings"
+
+	var resp struct {
+		Embedding []float32 `json:"embedding"`
+	}
+	if err := postEmbedding(ctx, o.httpClient, "ollama", url, "", map[string]string{"model": model, "prompt": text}, &resp); err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Embedding) == 0 {
+		return nil, 0, fmt.Errorf("ollama returned no embedding (is %s an embedding model?)", model)
+	}
+	return resp.Embedding, 0, nil
+}
+
+// Embed requests an embedding from the OpenAI embeddings API.
+func (o *OpenAIProvider) Embed(ctx context.Context, model, text string) ([]float32, int, error) {
+	apiKey, err := o.settings.GetSetting("openai_api_key")
+	if err != nil || strings.TrimSpace(apiKey) == "" {
+		return nil, 0, &MissingKeyError{Provider: "openai"}
+	}
+
+	var resp struct {
+		Data []struct {
+			Embedding []float32 `json:"embedding"`
+		} `json:"data"`
+		Usage struct {
+			TotalTokens int `json:"total_tokens"`
+		} `json:"usage"`
+	}
+	if err := postEmbedding(ctx, o.httpClient, "openai", openAIEmbeddingsURL, strings.TrimSpace(apiKey), map[string]string{"model": model, "input": text}, &resp); err != nil {
+		return nil, 0, err
+	}
+	if len(resp.Data) == 0 || len(resp.Data[0].Embedding) == 0 {
+		return nil, 0, fmt.Errorf("openai returned no embedding")
+	}
+	return resp.Data[0].Embedding, resp.Usage.TotalTokens, nil
+}
+
+// postEmbedding POSTs an embedding request as JSON and decodes the response
+// into out. Errors name the provider and status like chat requests do.
+func postEmbedding(ctx context.Context, client *http.Client, provider, url, apiKey string, body any, out any) error {
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if apiKey != "" {
+		req.Header.Set("Authorization", "Bearer "+apiKey)
+	}
+
+	resp, err := client.Do(req)
+	if err != nil {
+		return fmt.Errorf("%s embedding request failed: %w", provider, err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("read response: %w", err)
+	}
+	if resp.StatusCode != 200 {
+		errMsg := extractOllamaError(respBody)
+		if errMsg == "" {
+			errMsg = string(respBody)
+		}
+		return fmt.Errorf("%s returned status %d: %s", provider, resp.StatusCode, errMsg)
+	}
+	if err := json.Unmarshal(respBody, out); err != nil {
+		return fmt.Errorf("parse %s embedding response: %w", provider, err)
+	}
+	return nil
+}
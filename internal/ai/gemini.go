@@ -75,7 +75,7 @@ func (g *GeminiProvider) model() string {
 func (g *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
 	apiKey, err := g.settings.GetSetting("gemini_api_key")
 	if err != nil || apiKey == "" {
-		return nil, fmt.Errorf("gemini API key not configured — set it in Settings")
+		return nil, &MissingKeyError{Provider: "gemini"}
 	}
 
 	model := g.model()
@@ -125,9 +125,11 @@ func (g *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("parse gemini response: %w", err)
 	}
 
-	tokensUsed := 0
+	var tokensUsed, promptTokens, completionTokens int
 	if genResp.UsageMetadata != nil {
 		tokensUsed = genResp.UsageMetadata.TotalTokenCount
+		promptTokens = genResp.UsageMetadata.PromptTokenCount
+		completionTokens = genResp.UsageMetadata.CandidatesTokenCount
 	}
 
 	content := ""
@@ -136,10 +138,12 @@ func (g *GeminiProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	return &ChatResponse{
-		Content:    content,
-		TokensUsed: tokensUsed,
-		Model:      model,
-		Provider:   "gemini",
+		Content:          content,
+		TokensUsed:       tokensUsed,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		Model:            model,
+		Provider:         "gemini",
 	}, nil
 }
 
@@ -1,6 +1,7 @@
 package ai
 
 import (
+	"bufio"
 	"bytes"
 	"context"
 	"encoding/json"
@@ -15,12 +16,17 @@ import (
 // OpenAI-compatible request/response types for Ollama (unexported).
 
 type ollamaChatRequest struct {
-	Model          string          `json:"model"`
-	Messages       []ollamaMessage `json:"messages"`
-	Temperature    float64         `json:"temperature,omitempty"`
-	MaxTokens      int             `json:"max_tokens,omitempty"`
-	Stream         bool            `json:"stream"`
-	ResponseFormat *ollamaRespFmt  `json:"response_format,omitempty"`
+	Model          string               `json:"model"`
+	Messages       []ollamaMessage      `json:"messages"`
+	Temperature    float64              `json:"temperature,omitempty"`
+	MaxTokens      int                  `json:"max_tokens,omitempty"`
+	Stream         bool                 `json:"stream"`
+	StreamOptions  *ollamaStreamOptions `json:"stream_options,omitempty"`
+	ResponseFormat *ollamaRespFmt       `json:"response_format,omitempty"`
+}
+
+type ollamaStreamOptions struct {
+	IncludeUsage bool `json:"include_usage"`
 }
 
 type ollamaMessage struct {
@@ -42,6 +48,18 @@ type ollamaChoice struct {
 	Message ollamaMessage `json:"message"`
 }
 
+// ollamaStreamChunk is one event of a streamed response. Usage is only set on
+// the last chunk, and Error when the model fails mid-stream.
+type ollamaStreamChunk struct {
+	Choices []ollamaStreamChoice `json:"choices"`
+	Usage   *ollamaUsage         `json:"usage,omitempty"`
+	Error   json.RawMessage      `json:"error,omitempty"`
+}
+
+type ollamaStreamChoice struct {
+	Delta ollamaMessage `json:"delta"`
+}
+
 type ollamaUsage struct {
 	PromptTokens     int `json:"prompt_tokens"`
 	CompletionTokens int `json:"completion_tokens"`
@@ -67,6 +85,9 @@ type ollamaModelDetails struct {
 	QuantizationLevel string `json:"quantization_level"`
 }
 
+// ollamaProgressInterval is how often Chat logs a long generation's progress.
+const ollamaProgressInterval = 30 * time.Second
+
 // OllamaProvider implements Provider for Ollama's OpenAI-compatible API.
 type OllamaProvider struct {
 	httpClient *http.Client
@@ -83,7 +104,40 @@ func NewOllamaProvider(sg SettingsGetter) *OllamaProvider {
 
 func (o *OllamaProvider) Name() string { return "ollama" }
 
+// Chat sends a chat request and waits for the whole response. The response is
+// streamed from Ollama so a long generation is logged as it progresses.
 func (o *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	chunks, err := o.ChatStream(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	start := time.Now()
+	lastLog := start
+	received := 0
+	for chunk := range chunks {
+		if chunk.Err != nil {
+			return nil, chunk.Err
+		}
+		if chunk.Response != nil {
+			return chunk.Response, nil
+		}
+		received += len(chunk.Delta)
+		if time.Since(lastLog) >= ollamaProgressInterval {
+			slog.Info("Ollama still generating", "elapsed", time.Since(start).Round(time.Second), "response_chars", received)
+			lastLog = time.Now()
+		}
+	}
+	return nil, fmt.Errorf("ollama stream ended without a response: %w", ctx.Err())
+}
+
+// ChatStream sends a streaming chat request and returns a channel of chunks:
+// one per content delta as it arrives, then a final chunk holding the
+// aggregate response or the error that ended the stream, after which the
+// channel is closed. Errors before the stream starts, such as a refused
+// connection or a non-200 status, are returned directly. The caller must
+// drain the channel or cancel ctx.
+func (o *OllamaProvider) ChatStream(ctx context.Context, req ChatRequest) (<-chan StreamChunk, error) {
 	baseURL, err := o.settings.GetSetting("ollama_url")
 	if err != nil || strings.TrimSpace(baseURL) == "" {
 		baseURL = "http://localhost:11434"
@@ -108,11 +162,12 @@ func (o *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 	}
 
 	body := ollamaChatRequest{
-		Model:       model,
-		Messages:    msgs,
-		Temperature: req.Temperature,
-		MaxTokens:   req.MaxTokens,
-		Stream:      false,
+		Model:         model,
+		Messages:      msgs,
+		Temperature:   req.Temperature,
+		MaxTokens:     req.MaxTokens,
+		Stream:        true,
+		StreamOptions: &ollamaStreamOptions{IncludeUsage: true},
 	}
 
 	if req.JSONMode {
@@ -144,14 +199,10 @@ func (o *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		slog.Error("Ollama request failed", "url", url, "model", model, "elapsed", time.Since(start), "error", err)
 		return nil, fmt.Errorf("ollama request failed (model=%s, url=%s, elapsed=%s): %w", model, url, time.Since(start), err)
 	}
-	defer resp.Body.Close()
-
-	respBody, err := io.ReadAll(resp.Body)
-	if err != nil {
-		return nil, fmt.Errorf("read response: %w", err)
-	}
 
 	if resp.StatusCode != 200 {
+		defer resp.Body.Close()
+		respBody, _ := io.ReadAll(resp.Body)
 		errMsg := extractOllamaError(respBody)
 		if errMsg == "" {
 			errMsg = string(respBody)
@@ -160,29 +211,114 @@ func (o *OllamaProvider) Chat(ctx context.Context, req ChatRequest) (*ChatRespon
 		return nil, fmt.Errorf("ollama returned status %d: %s", resp.StatusCode, errMsg)
 	}
 
-	var chatResp ollamaChatResponse
-	if err := json.Unmarshal(respBody, &chatResp); err != nil {
-		return nil, fmt.Errorf("parse ollama response: %w", err)
-	}
+	chunks := make(chan StreamChunk, 16)
+	go func() {
+		defer close(chunks)
+		defer resp.Body.Close()
+
+		send := func(c StreamChunk) bool {
+			select {
+			case chunks <- c:
+				return true
+			case <-ctx.Done():
+				return false
+			}
+		}
 
-	tokensUsed := 0
-	if chatResp.Usage != nil {
-		tokensUsed = chatResp.Usage.TotalTokens
-	}
+		content, usage, err := readOllamaStream(resp, func(delta string) bool {
+			return send(StreamChunk{Delta: delta})
+		})
+		if err != nil {
+			send(StreamChunk{Err: err})
+			return
+		}
 
-	content := ""
-	if len(chatResp.Choices) > 0 {
-		content = chatResp.Choices[0].Message.Content
+		slog.Info("Ollama request completed", "model", model, "elapsed", time.Since(start), "tokens", usage.TotalTokens, "response_chars", len(content))
+		send(StreamChunk{Response: &ChatResponse{
+			Content:          content,
+			TokensUsed:       usage.TotalTokens,
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			Model:            model,
+			Provider:         "ollama",
+		}})
+	}()
+	return chunks, nil
+}
+
+// readOllamaStream reads a chat response body, calling onDelta with each piece
+// of content as it arrives, and returns the whole content and the token usage.
+// It accepts server-sent events ("data: {...}" lines ending with "data: [DONE]")
+// or bare newline-delimited JSON chunks, and a server that ignores the stream
+// flag and answers with one JSON body is read as a single delta. It stops early
+// with ctx's error if onDelta returns false.
+func readOllamaStream(resp *http.Response, onDelta func(string) bool) (string, ollamaUsage, error) {
+	ctx := resp.Request.Context()
+	if strings.HasPrefix(resp.Header.Get("Content-Type"), "application/json") {
+		respBody, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return "", ollamaUsage{}, fmt.Errorf("read response: %w", err)
+		}
+		var chatResp ollamaChatResponse
+		if err := json.Unmarshal(respBody, &chatResp); err != nil {
+			return "", ollamaUsage{}, fmt.Errorf("parse ollama response: %w", err)
+		}
+		content := ""
+		if len(chatResp.Choices) > 0 {
+			content = chatResp.Choices[0].Message.Content
+		}
+		var usage ollamaUsage
+		if chatResp.Usage != nil {
+			usage = *chatResp.Usage
+		}
+		if content != "" && !onDelta(content) {
+			return "", ollamaUsage{}, fmt.Errorf("ollama stream abandoned: %w", ctx.Err())
+		}
+		return content, usage, nil
 	}
 
-	slog.Info("Ollama request completed", "model", model, "elapsed", time.Since(start), "tokens", tokensUsed, "response_chars", len(content))
+	var sb strings.Builder
+	var usage ollamaUsage
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4<<20)
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		line = strings.TrimSpace(strings.TrimPrefix(line, "data:"))
+		if line == "" || strings.HasPrefix(line, ":") {
+			continue
+		}
+		if line == "[DONE]" {
+			break
+		}
 
-	return &ChatResponse{
-		Content:    content,
-		TokensUsed: tokensUsed,
-		Model:      model,
-		Provider:   "ollama",
-	}, nil
+		var chunk ollamaStreamChunk
+		if err := json.Unmarshal([]byte(line), &chunk); err != nil {
+			return "", ollamaUsage{}, fmt.Errorf("parse ollama stream chunk: %w", err)
+		}
+		if chunk.Error != nil {
+			msg := extractOllamaError([]byte(line))
+			if msg == "" {
+				msg = string(chunk.Error)
+			}
+			return "", ollamaUsage{}, fmt.Errorf("ollama stream error: %s", msg)
+		}
+		if chunk.Usage != nil {
+			usage = *chunk.Usage
+		}
+		for _, c := range chunk.Choices {
+			if c.Delta.Content == "" {
+				continue
+			}
+			sb.WriteString(c.Delta.Content)
+			if !onDelta(c.Delta.Content) {
+				return "", ollamaUsage{}, fmt.Errorf("ollama stream abandoned: %w", ctx.Err())
+			}
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return "", ollamaUsage{}, fmt.Errorf("read ollama stream: %w", err)
+	}
+	return sb.String(), usage, nil
 }
 
 // ListModels queries the Ollama server for available models.
@@ -0,0 +1,142 @@
+package ai
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+)
+
+func TestOllamaChatStream(t *testing.T) {
+	tests := []struct {
+		name        string
+		contentType string
+		body        string
+		wantDeltas  []string
+		wantTokens  int
+	}{
+		{
+			"Server-sent events",
+			"text/event-stream",
+			"data: {\"choices\":[{\"delta\":{\"role\":\"assistant\",\"content\":\"Owls \"}}]}\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"can't \"}}]}\n\n" +
+				": keep-alive\n\n" +
+				"data: {\"choices\":[{\"delta\":{\"content\":\"roll eyes.\"}}]}\n\n" +
+				"data: {\"choices\":[],\"usage\":{\"total_tokens\":17}}\n\n" +
+				"data: [DONE]\n\n",
+			[]string{"Owls ", "can't ", "roll eyes."},
+			17,
+		},
+		{
+			"Newline-delimited JSON",
+			"application/x-ndjson",
+			"{\"choices\":[{\"delta\":{\"content\":\"Owls \"}}]}\n" +
+				"{\"choices\":[{\"delta\":{\"content\":\"can't roll eyes.\"}}]}\n",
+			[]string{"Owls ", "can't roll eyes."},
+			0,
+		},
+		{
+			"Stream flag ignored",
+			"application/json",
+			`{"choices":[{"message":{"role":"assistant","content":"Owls can't roll eyes."}}],"usage":{"total_tokens":9}}`,
+			[]string{"Owls can't roll eyes."},
+			9,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+				reqBody, _ := io.ReadAll(r.Body)
+				if !strings.Contains(string(reqBody), `"stream":true`) {
+					t.Errorf("request did not ask for a stream: %s", reqBody)
+				}
+				w.Header().Set("Content-Type", tt.contentType)
+				io.WriteString(w, tt.body)
+			}))
+			defer srv.Close()
+			p := NewOllamaProvider(mapSettings{"ollama_url": srv.URL, "ollama_model": "test-model"})
+
+			chunks, err := p.ChatStream(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "owls"}}})
+			if err != nil {
+				t.Fatalf("ChatStream: %v", err)
+			}
+			var deltas []string
+			var final *ChatResponse
+			for c := range chunks {
+				if c.Err != nil {
+					t.Fatalf("stream error: %v", c.Err)
+				}
+				if c.Response != nil {
+					final = c.Response
+					continue
+				}
+				deltas = append(deltas, c.Delta)
+			}
+			if strings.Join(deltas, "|") != strings.Join(tt.wantDeltas, "|") {
+				t.Errorf("deltas = %q, want %q", deltas, tt.wantDeltas)
+			}
+			if final == nil || final.Content != "Owls can't roll eyes." || final.TokensUsed != tt.wantTokens {
+				t.Errorf("final response = %+v, want full content and %d tokens", final, tt.wantTokens)
+			}
+
+			resp, err := p.Chat(context.Background(), ChatRequest{Messages: []Message{{Role: "user", Content: "owls"}}})
+			if err != nil || resp.Content != "Owls can't roll eyes." || resp.Model != "test-model" {
+				t.Errorf("Chat = %+v, %v, want the aggregated content", resp, err)
+			}
+		})
+	}
+}
+
+func TestOllamaChatStreamErrors(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path == "/v1/chat/completions" {
+			w.Header().Set("Content-Type", "text/event-stream")
+			io.WriteString(w, "data: {\"choices\":[{\"delta\":{\"content\":\"Owls\"}}]}\n\n")
+			io.WriteString(w, "data: {\"error\":{\"message\":\"model crashed\",\"type\":\"api_error\"}}\n\n")
+			return
+		}
+		w.WriteHeader(http.StatusServiceUnavailable)
+		io.WriteString(w, `{"error":"overloaded"}`)
+	}))
+	defer srv.Close()
+
+	_, err := NewOllamaProvider(mapSettings{"ollama_url": srv.URL}).Chat(context.Background(), ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "model crashed") {
+		t.Errorf("mid-stream error = %v, want it to report the model error", err)
+	}
+
+	_, err = NewOllamaProvider(mapSettings{"ollama_url": srv.URL + "/down"}).ChatStream(context.Background(), ChatRequest{})
+	if err == nil || !strings.Contains(err.Error(), "status 503: overloaded") {
+		t.Errorf("status error = %v, want it returned before streaming", err)
+	}
+}
+
+func TestOllamaEmbed(t *testing.T) {
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.URL.Path != "/api/embeddings" {
+			t.Errorf("request path = %q, want /api/embeddings", r.URL.Path)
+		}
+		reqBody, _ := io.ReadAll(r.Body)
+		if !strings.Contains(string(reqBody), `"model":"nomic-embed-text"`) {
+			t.Errorf("request did not use the default model: %s", reqBody)
+		}
+		io.WriteString(w, `{"embedding":[0.5,-0.25,1]}`)
+	}))
+	defer srv.Close()
+
+	settings := mapSettings{"ollama_url": srv.URL + "/", "embedding_provider": "ollama"}
+	c := &Client{settings: settings, ollama: NewOllamaProvider(settings)}
+	vec, tokens, err := c.Embed(context.Background(), "Owls can't roll their eyes.")
+	if err != nil {
+		t.Fatalf("Embed: %v", err)
+	}
+	if len(vec) != 3 || vec[0] != 0.5 || vec[1] != -0.25 || vec[2] != 1 {
+		t.Errorf("Embed vector = %v, want [0.5 -0.25 1]", vec)
+	}
+	if tokens != 0 {
+		t.Errorf("Embed tokens = %d, want 0", tokens)
+	}
+}
@@ -0,0 +1,189 @@
+package ai
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"strings"
+	"time"
+)
+
+const (
+	openAIBaseURL      = "https://api.openai.com/v1/chat/completions"
+	openAIDefaultModel = "gpt-4o-mini"
+)
+
+// OpenAIProvider implements Provider for the OpenAI chat completions API.
+type OpenAIProvider struct {
+	httpClient *http.Client
+	settings   SettingsGetter
+}
+
+// NewOpenAIProvider creates an OpenAI provider.
+func NewOpenAIProvider(sg SettingsGetter) *OpenAIProvider {
+	return &OpenAIProvider{
+		httpClient: &http.Client{Timeout: 5 * time.Minute},
+		settings:   sg,
+	}
+}
+
+func (o *OpenAIProvider) Name() string { return "openai" }
+
+func (o *OpenAIProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	apiKey, err := o.settings.GetSetting("openai_api_key")
+	if err != nil || strings.TrimSpace(apiKey) == "" {
+		return nil, &MissingKeyError{Provider: "openai"}
+	}
+	apiKey = strings.TrimSpace(apiKey)
+
+	model, err := o.settings.GetSetting("openai_model")
+	if err != nil || strings.TrimSpace(model) == "" {
+		model = openAIDefaultModel
+	}
+	model = strings.TrimSpace(model)
+
+	if ctx.Err() != nil {
+		return nil, fmt.Errorf("openai request skipped (context already cancelled): %w", ctx.Err())
+	}
+
+	// The request and response types in ollama.go follow the OpenAI format
+	msgs := make([]ollamaMessage, len(req.Messages))
+	for i, m := range req.Messages {
+		msgs[i] = ollamaMessage{Role: m.Role, Content: m.Content}
+	}
+
+	body := ollamaChatRequest{
+		Model:       model,
+		Messages:    msgs,
+		Temperature: req.Temperature,
+		MaxTokens:   req.MaxTokens,
+		Stream:      false,
+	}
+
+	if req.JSONMode {
+		body.ResponseFormat = &ollamaRespFmt{Type: "json_object"}
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return nil, fmt.Errorf("marshal request: %w", err)
+	}
+
+	promptChars := 0
+	for _, m := range msgs {
+		promptChars += len(m.Content)
+	}
+
+	slog.Info("OpenAI request starting", "model", model, "prompt_chars", promptChars, "json_mode", req.JSONMode)
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", openAIBaseURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+apiKey)
+
+	start := time.Now()
+	resp, err := o.httpClient.Do(httpReq)
+	if err != nil {
+		slog.Error("OpenAI request failed", "model", model, "elapsed", time.Since(start), "error", err)
+		return nil, fmt.Errorf("openai request failed (model=%s, elapsed=%s): %w", model, time.Since(start), err)
+	}
+	defer resp.Body.Close()
+
+	respBody, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("read response: %w", err)
+	}
+
+	if resp.StatusCode != 200 {
+		errMsg := extractOllamaError(respBody)
+		if errMsg == "" {
+			errMsg = string(respBody)
+		}
+		slog.Error("OpenAI API error", "status", resp.StatusCode, "model", model, "error", errMsg)
+		return nil, fmt.Errorf("openai returned status %d: %s", resp.StatusCode, errMsg)
+	}
+
+	var chatResp ollamaChatResponse
+	if err := json.Unmarshal(respBody, &chatResp); err != nil {
+		return nil, fmt.Errorf("parse openai response: %w", err)
+	}
+
+	var tokensUsed, promptTokens, completionTokens int
+	if chatResp.Usage != nil {
+		tokensUsed = chatResp.Usage.TotalTokens
+		promptTokens = chatResp.Usage.PromptTokens
+		completionTokens = chatResp.Usage.CompletionTokens
+	}
+
+	content := ""
+	if len(chatResp.Choices) > 0 {
+		content = chatResp.Choices[0].Message.Content
+	}
+
+	slog.Info("OpenAI request completed", "model", model, "elapsed", time.Since(start), "tokens", tokensUsed, "response_chars", len(content))
+
+	return &ChatResponse{
+		Content:          content,
+		TokensUsed:       tokensUsed,
+		PromptTokens:     promptTokens,
+		CompletionTokens: completionTokens,
+		Model:            model,
+		Provider:         "openai",
+	}, nil
+}
+
+// TestOpenAIKey verifies an OpenAI API key by sending a minimal request.
+func TestOpenAIKey(ctx context.Context, apiKey, model string) error {
+	if strings.TrimSpace(apiKey) == "" {
+		return fmt.Errorf("API key is empty")
+	}
+	if strings.TrimSpace(model) == "" {
+		model = openAIDefaultModel
+	}
+
+	body := ollamaChatRequest{
+		Model:     strings.TrimSpace(model),
+		Messages:  []ollamaMessage{{Role: "user", Content: "Say hello in exactly one word."}},
+		MaxTokens: 10,
+		Stream:    false,
+	}
+
+	jsonData, err := json.Marshal(body)
+	if err != nil {
+		return fmt.Errorf("marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", openAIBaseURL, bytes.NewReader(jsonData))
+	if err != nil {
+		return fmt.Errorf("create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	httpReq.Header.Set("Authorization", "Bearer "+strings.TrimSpace(apiKey))
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("connection failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == 401 {
+		return fmt.Errorf("invalid API key (401 Unauthorized)")
+	}
+	if resp.StatusCode != 200 {
+		respBody, _ := io.ReadAll(resp.Body)
+		errMsg := extractOllamaError(respBody)
+		if errMsg == "" {
+			errMsg = string(respBody)
+		}
+		return fmt.Errorf("API returned status %d: %s", resp.StatusCode, errMsg)
+	}
+
+	return nil
+}
@@ -10,13 +10,27 @@ import (
 
 var numberingPattern = regexp.MustCompile(`^\s*(?:\d+[\.\)]\s*|[-*]\s+)`)
 
-// BuildFactsPrompt constructs the prompt for generating facts.
-func BuildFactsPrompt(topic, description, customInstructions, toneInstructions string, count, minWords, maxWords int) string {
+var citationPattern = regexp.MustCompile(`(?i)\s*[\[(]\s*source:\s*([^\])]+?)\s*[\])]\s*$`)
+
+var confidencePattern = regexp.MustCompile(`(?i)\s*[\[(]\s*confidence:\s*(high|medium|low)\s*[\])]`)
+
+// BuildFactsPrompt constructs the prompt for generating facts. With series set,
+// it asks for the next entries of an ordered narrative instead of standalone
+// trivia, continuing from seriesSoFar (the latest entries, oldest first).
+func BuildFactsPrompt(topic, description, customInstructions, toneInstructions string, count, minWords, maxWords int, series bool, seriesSoFar []string) string {
 	var sb strings.Builder
 
-	sb.WriteString(fmt.Sprintf(
-		"Generate exactly %d unique, interesting, and accurate facts about the topic: \"%s\".\n",
-		count, topic))
+	if series {
+		sb.WriteString(fmt.Sprintf(
+			"Generate the next %d accurate facts in an ongoing series about the topic: \"%s\".\n",
+			count, topic))
+		sb.WriteString("The facts must build on each other as a coherent narrative arc (for example, a chronological history), ")
+		sb.WriteString("with each fact following on from the one before it.\n")
+	} else {
+		sb.WriteString(fmt.Sprintf(
+			"Generate exactly %d unique, interesting, and accurate facts about the topic: \"%s\".\n",
+			count, topic))
+	}
 
 	if description != "" {
 		sb.WriteString(fmt.Sprintf("Topic description: %s\n", description))
@@ -38,7 +52,25 @@ func BuildFactsPrompt(topic, description, customInstructions, toneInstructions s
 		sb.WriteString(fmt.Sprintf("Each fact should be at most %d words long.\n", maxWords))
 	}
 
-	sb.WriteString("\nIMPORTANT: Return ONLY the facts as a numbered list (1., 2., 3., etc.), one per line. ")
+	if series {
+		if len(seriesSoFar) > 0 {
+			sb.WriteString("\nThe series so far, most recent last:\n")
+			for _, f := range seriesSoFar {
+				sb.WriteString("- ")
+				sb.WriteString(f)
+				sb.WriteString("\n")
+			}
+			sb.WriteString("Continue the series from where it leaves off. Do not repeat or restate earlier entries.\n")
+		} else {
+			sb.WriteString("This is the start of the series, so begin at the beginning.\n")
+		}
+	}
+
+	sb.WriteString("\nIMPORTANT: Return ONLY the facts as a numbered list (1., 2., 3., etc.), one per line")
+	if series {
+		sb.WriteString(", in series order")
+	}
+	sb.WriteString(". ")
 	sb.WriteString("Do not include any other text, headers, or explanations. ")
 	sb.WriteString("Each fact should be a single, self-contained sentence or short paragraph.")
 
@@ -46,7 +78,7 @@ func BuildFactsPrompt(topic, description, customInstructions, toneInstructions s
 }
 
 // BuildFactsPromptWithContext constructs a fact prompt augmented with research context (RAG).
-func BuildFactsPromptWithContext(topic, description, customInstructions, toneInstructions string, count, minWords, maxWords int, context string) string {
+func BuildFactsPromptWithContext(topic, description, customInstructions, toneInstructions string, count, minWords, maxWords int, series bool, seriesSoFar []string, context string) string {
 	var sb strings.Builder
 
 	sb.WriteString("=== REFERENCE MATERIAL ===\n")
@@ -55,7 +87,131 @@ func BuildFactsPromptWithContext(topic, description, customInstructions, toneIns
 	sb.WriteString(context)
 	sb.WriteString("\n\n=== END REFERENCE MATERIAL ===\n\n")
 
-	sb.WriteString(BuildFactsPrompt(topic, description, customInstructions, toneInstructions, count, minWords, maxWords))
+	sb.WriteString(BuildFactsPrompt(topic, description, customInstructions, toneInstructions, count, minWords, maxWords, series, seriesSoFar))
+	sb.WriteString("\nIf a fact comes from one of the reference articles above, end its line with ")
+	sb.WriteString("[Source: Article Title], using the article title exactly as it appears after \"## \". ")
+	sb.WriteString("Omit the source for facts drawn from general knowledge.")
+
+	return sb.String()
+}
+
+// BuildFactsExclusion returns a prompt section listing facts that have already
+// been generated, for a follow-up request that tops up a short batch.
+func BuildFactsExclusion(facts []string) string {
+	if len(facts) == 0 {
+		return ""
+	}
+	var sb strings.Builder
+	sb.WriteString("\n\nThe following facts have already been generated. Do NOT repeat or restate any of them:\n")
+	for _, f := range facts {
+		sb.WriteString("- ")
+		sb.WriteString(f)
+		sb.WriteString("\n")
+	}
+	return sb.String()
+}
+
+// BuildVerifiableInstructions returns a prompt section for topics that require
+// verifiable facts, asking for well-established statements only, each rated
+// with a "[Confidence: ...]" marker.
+func BuildVerifiableInstructions() string {
+	var sb strings.Builder
+	sb.WriteString("\n\nACCURACY IS CRITICAL: Only include statements you are confident are accurate and that could be checked ")
+	sb.WriteString("against a reliable reference. Prefer well-established facts over surprising claims, and never invent ")
+	sb.WriteString("figures, dates, names, or studies. It is better to return fewer facts than an uncertain one.\n")
+	sb.WriteString("End every fact's line with [Confidence: high], [Confidence: medium], or [Confidence: low], ")
+	sb.WriteString("rating how sure you are that the fact is accurate. Place it after any [Source: ...] attribution.")
+	return sb.String()
+}
+
+// ExtractFactConfidence removes a "[Confidence: level]" marker from a fact and
+// returns the fact without it and the lowercased level (ConfidenceHigh,
+// ConfidenceMedium, or ConfidenceLow), or "" if the fact carries no marker.
+func ExtractFactConfidence(fact string) (string, string) {
+	m := confidencePattern.FindStringSubmatchIndex(fact)
+	if m == nil {
+		return fact, ""
+	}
+	level := strings.ToLower(fact[m[2]:m[3]])
+	return strings.TrimSpace(fact[:m[0]] + fact[m[1]:]), level
+}
+
+// ExtractFactCitation splits a trailing "[Source: Title]" attribution from a fact.
+// It returns the fact text without the attribution and the cited title, or an
+// empty title if the fact carries no citation.
+func ExtractFactCitation(fact string) (string, string) {
+	m := citationPattern.FindStringSubmatchIndex(fact)
+	if m == nil {
+		return fact, ""
+	}
+	return strings.TrimSpace(fact[:m[0]]), strings.TrimSpace(fact[m[2]:m[3]])
+}
+
+// WrapPrompt puts prefix before prompt and suffix after it, each separated by a
+// blank line. Blank prefixes and suffixes are left out.
+func WrapPrompt(prompt, prefix, suffix string) string {
+	if prefix = strings.TrimSpace(prefix); prefix != "" {
+		prompt = prefix + "\n\n" + prompt
+	}
+	if suffix = strings.TrimSpace(suffix); suffix != "" {
+		prompt = prompt + "\n\n" + suffix
+	}
+	return prompt
+}
+
+// ResearchTitles returns the article titles in a research context built by
+// ResearchTopic, in the order they appear.
+func ResearchTitles(context string) []string {
+	var titles []string
+	for _, line := range strings.Split(context, "\n") {
+		if title, ok := strings.CutPrefix(line, "## "); ok {
+			titles = append(titles, strings.TrimSpace(title))
+		}
+	}
+	return titles
+}
+
+// BuildRelevancePrompt constructs the prompt for scoring how relevant each fact is to its topic.
+func BuildRelevancePrompt(topic, description string, facts []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Rate how relevant each of the following facts is to the topic: \"%s\".\n", topic))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf("Topic description: %s\n", description))
+	}
+	sb.WriteString("\nUse a scale from 0 (unrelated) to 10 (squarely about the topic). ")
+	sb.WriteString("A fact that only mentions something adjacent to the topic, such as a different era, place, or field, should score low.\n\n")
+
+	for i, f := range facts {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, f))
+	}
+
+	sb.WriteString(fmt.Sprintf("\nIMPORTANT: Return ONLY %d scores as a numbered list (1., 2., 3., etc.), one integer per line, ", len(facts)))
+	sb.WriteString("in the same order as the facts. Do not include any other text.")
+
+	return sb.String()
+}
+
+// BuildOverviewPrompt constructs the prompt for a one-paragraph overview that
+// synthesizes a topic's recent facts.
+func BuildOverviewPrompt(topic, description, toneInstructions string, facts []string) string {
+	var sb strings.Builder
+
+	sb.WriteString(fmt.Sprintf("Write a short overview of the topic \"%s\" that synthesizes the facts below.\n", topic))
+	if description != "" {
+		sb.WriteString(fmt.Sprintf("Topic description: %s\n", description))
+	}
+	if toneInstructions != "" {
+		sb.WriteString(fmt.Sprintf("Tone and style: %s\n", toneInstructions))
+	}
+	sb.WriteString("\nFacts:\n")
+	for i, f := range facts {
+		sb.WriteString(fmt.Sprintf("%d. %s\n", i+1, f))
+	}
+
+	sb.WriteString("\nIMPORTANT: Return ONE cohesive paragraph of 3 to 5 sentences that connects the facts into a readable whole, ")
+	sb.WriteString("rather than listing them one by one. Use only what the facts say and add no new claims. ")
+	sb.WriteString("Do not include a title, numbering, markdown, or any other text.")
 
 	return sb.String()
 }
@@ -161,7 +317,7 @@ func BuildDiscoverPromptWithContext(topicName, description, sourcingInstructions
 }
 
 // BuildSummarizePrompt constructs the prompt for summarizing scraped content.
-func BuildSummarizePrompt(topicName string, scrapedContent []ScrapedContent, summarizingInstructions, toneInstructions string, maxStories, minWords, maxWords int, existingTitles []string) string {
+func BuildSummarizePrompt(topicName string, scrapedContent []ScrapedContent, summarizingInstructions, toneInstructions string, maxStories, minWords, maxWords int, existingTitles []string, relaxed bool, contentFocus string, includeQuotes bool) string {
 	var sb strings.Builder
 
 	sb.WriteString(fmt.Sprintf(`You are a news summarization assistant. Analyze the following scraped content and create clear, informative news summaries.
@@ -216,14 +372,33 @@ Topic: %s
 		totalChars += len(c)
 	}
 
-	sb.WriteString(fmt.Sprintf(`
-From the content above, identify the %d most interesting and relevant news stories.
-
-IMPORTANT FILTERING RULES:
+	filteringRules := fmt.Sprintf(`IMPORTANT FILTERING RULES:
 - ONLY include content that DIRECTLY relates to the topic "%s"
 - Skip any content that is off-topic or only tangentially related
 - For Reddit posts, focus on substantive discussions and news, not casual comments or memes
-- Prioritize recent, newsworthy content over general discussion
+- Prioritize recent, newsworthy content over general discussion`, topicName)
+	if relaxed {
+		// A strict pass found nothing; accept looser matches rather than leave the topic empty
+		filteringRules = fmt.Sprintf(`FILTERING RULES:
+- A stricter pass over this content found no stories, so be more inclusive this time
+- Include content that relates to the topic "%s" directly OR through a closely related subject, field, or audience
+- Skip only content that has no reasonable connection to the topic
+- For Reddit posts, focus on substantive discussions and news, not casual comments or memes`, topicName)
+	}
+	if contentFocus == ContentFocusLinks {
+		filteringRules += `
+
+LINK AGGREGATOR SOURCES:
+- These sources are link aggregators; each LINK POST is a headline someone shared, not a full article
+- Judge importance from the title, the linked site, and the SCORE where given
+- Summarize what the headline and excerpt establish; do NOT invent details the post does not contain
+- Prefer posts with high scores, and skip bare links with no clear news value`
+	}
+
+	sb.WriteString(fmt.Sprintf(`
+From the content above, identify the %d most interesting and relevant news stories.
+
+%s
 
 SOURCE DIVERSITY:
 - Distribute stories across different sources. Avoid selecting more than 2 stories from the same source.
@@ -234,7 +409,7 @@ EDITORIAL QUALITY:
 - Prefer stories with genuine news value over routine announcements or press releases
 - Skip listicles, opinion pieces with no news hook, and repackaged wire stories
 
-`, maxStories, topicName))
+`, maxStories, filteringRules))
 
 	// Add dedup context if existing titles are provided
 	if len(existingTitles) > 0 {
@@ -251,13 +426,27 @@ For each story:
 2. Write a summary focusing on key facts and why this story matters
 3. Include the source URL where the story was found
 4. Include the source name/title
+5. Rate its importance from 1 (minor) to 10 (major news for anyone following this topic)
+6. Include the article's publication date as YYYY-MM-DD if the source content shows one, otherwise ""
+7. Include the author exactly as given on the article's AUTHOR line, otherwise ""
+`)
+	if includeQuotes {
+		sb.WriteString(`8. Include a quote: one short excerpt (at most 40 words) copied word for word from the source content above, such as a key statement or finding. Do not paraphrase, translate, or add quotation marks. Use "" if the source has nothing worth quoting
+`)
+	}
 
+	sb.WriteString(`
 IMPORTANT: Return ONLY a valid JSON array with no additional text, markdown, or explanation.
 
 Format:
 [
-  {"title": "Headline Here", "summary": "Summary text here...", "source_url": "https://source.com/article", "source_title": "Source Name"}
-]`)
+`)
+	if includeQuotes {
+		sb.WriteString(`  {"title": "Headline Here", "summary": "Summary text here...", "quote": "Exact words from the source", "source_url": "https://source.com/article", "source_title": "Source Name", "author": "Jane Doe", "importance": 7, "published_date": "2025-01-31"}`)
+	} else {
+		sb.WriteString(`  {"title": "Headline Here", "summary": "Summary text here...", "source_url": "https://source.com/article", "source_title": "Source Name", "author": "Jane Doe", "importance": 7, "published_date": "2025-01-31"}`)
+	}
+	sb.WriteString("\n]")
 
 	return sb.String()
 }
@@ -350,3 +539,25 @@ func IsCompleteSentence(text string, minWords int) bool {
 	last := stripped[len(stripped)-1]
 	return last == '.' || last == '!' || last == '?'
 }
+
+// TruncateToWords caps text at maxWords words, cutting back to the last complete
+// sentence within the cap. It returns false if no sentence boundary fits, in which
+// case the text cannot be shortened cleanly. Text already within the cap is returned as-is.
+func TruncateToWords(text string, maxWords int) (string, bool) {
+	words := strings.Fields(text)
+	if maxWords <= 0 || len(words) <= maxWords {
+		return text, true
+	}
+
+	for i := maxWords - 1; i >= 0; i-- {
+		w := strings.TrimRight(words[i], `"')]}»`)
+		if w == "" {
+			continue
+		}
+		last := w[len(w)-1]
+		if last == '.' || last == '!' || last == '?' {
+			return strings.Join(words[:i+1], " "), true
+		}
+	}
+	return text, false
+}
@@ -0,0 +1,160 @@
+package ai
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+)
+
+func TestTruncateToWords(t *testing.T) {
+	tests := []struct {
+		name     string
+		text     string
+		maxWords int
+		want     string
+		wantOK   bool
+	}{
+		{"Under cap", "One two three.", 5, "One two three.", true},
+		{"No cap", "One two three four five six.", 0, "One two three four five six.", true},
+		{"Cut at sentence", "One two. Three four five six.", 4, "One two.", true},
+		{"Closing quote", `He said "stop." Then he left the room.`, 5, `He said "stop."`, true},
+		{"Question mark", "Is it true? Yes it is indeed true.", 5, "Is it true?", true},
+		{"No boundary", "One two three four five six.", 3, "One two three four five six.", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := TruncateToWords(tt.text, tt.maxWords)
+			if got != tt.want || ok != tt.wantOK {
+				t.Errorf("TruncateToWords(%q, %d) = (%q, %v), want (%q, %v)",
+					tt.text, tt.maxWords, got, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
+func TestExtractFactCitation(t *testing.T) {
+	tests := []struct {
+		name        string
+		fact        string
+		wantContent string
+		wantTitle   string
+	}{
+		{"No citation", "Octopuses have three hearts.", "Octopuses have three hearts.", ""},
+		{"Bracketed", "Octopuses have three hearts. [Source: Octopus]", "Octopuses have three hearts.", "Octopus"},
+		{"Parenthesized", "Octopuses have three hearts. (source: Cephalopod)", "Octopuses have three hearts.", "Cephalopod"},
+		{"Not trailing", "[Source: Octopus] Octopuses have three hearts.", "[Source: Octopus] Octopuses have three hearts.", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, title := ExtractFactCitation(tt.fact)
+			if content != tt.wantContent || title != tt.wantTitle {
+				t.Errorf("ExtractFactCitation(%q) = (%q, %q), want (%q, %q)",
+					tt.fact, content, title, tt.wantContent, tt.wantTitle)
+			}
+		})
+	}
+}
+
+func TestExtractFactConfidence(t *testing.T) {
+	tests := []struct {
+		name        string
+		fact        string
+		wantContent string
+		wantLevel   string
+	}{
+		{"No marker", "Octopuses have three hearts.", "Octopuses have three hearts.", ""},
+		{"Trailing", "Octopuses have three hearts. [Confidence: High]", "Octopuses have three hearts.", ConfidenceHigh},
+		{"Before source", "Octopuses have three hearts. (confidence: low) [Source: Octopus]", "Octopuses have three hearts. [Source: Octopus]", ConfidenceLow},
+		{"After source", "Octopuses have three hearts. [Source: Octopus] [Confidence: medium]", "Octopuses have three hearts. [Source: Octopus]", ConfidenceMedium},
+		{"Unknown level", "Octopuses have three hearts. [Confidence: certain]", "Octopuses have three hearts. [Confidence: certain]", ""},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			content, level := ExtractFactConfidence(tt.fact)
+			if content != tt.wantContent || level != tt.wantLevel {
+				t.Errorf("ExtractFactConfidence(%q) = (%q, %q), want (%q, %q)",
+					tt.fact, content, level, tt.wantContent, tt.wantLevel)
+			}
+		})
+	}
+}
+
+func TestBuildFactsPromptSeries(t *testing.T) {
+	standalone := BuildFactsPrompt("Rome", "", "", "", 3, 0, 0, false, nil)
+	if strings.Contains(standalone, "series") {
+		t.Errorf("standalone prompt mentions a series:\n%s", standalone)
+	}
+
+	first := BuildFactsPrompt("Rome", "", "", "", 3, 0, 0, true, nil)
+	if !strings.Contains(first, "start of the series") {
+		t.Errorf("first series prompt does not start the series:\n%s", first)
+	}
+
+	next := BuildFactsPrompt("Rome", "", "", "", 3, 0, 0, true, []string{"Rome was founded in 753 BC."})
+	if !strings.Contains(next, "- Rome was founded in 753 BC.") || !strings.Contains(next, "Continue the series") {
+		t.Errorf("continuing series prompt is missing the series so far:\n%s", next)
+	}
+}
+
+func TestBuildSummarizePromptQuotes(t *testing.T) {
+	content := []ScrapedContent{{URL: "https://example.com", SourceName: "Example", Content: "Officials said the bridge will reopen in May."}}
+
+	plain := BuildSummarizePrompt("Local News", content, "", "", 3, 0, 0, nil, false, "", false)
+	if strings.Contains(plain, `"quote"`) {
+		t.Errorf("prompt without quotes asks for a quote:\n%s", plain)
+	}
+
+	quoted := BuildSummarizePrompt("Local News", content, "", "", 3, 0, 0, nil, false, "", true)
+	if !strings.Contains(quoted, `"quote"`) || !strings.Contains(quoted, "word for word") {
+		t.Errorf("prompt with quotes does not ask for a verbatim quote:\n%s", quoted)
+	}
+	if !strings.HasSuffix(quoted, "]") {
+		t.Errorf("prompt with quotes does not end with the format example:\n%s", quoted)
+	}
+}
+
+func TestScoreUnmarshal(t *testing.T) {
+	tests := []struct {
+		raw  string
+		want Score
+	}{
+		{`{"importance": 7}`, 7},
+		{`{"importance": "8"}`, 8},
+		{`{"importance": 6.6}`, 7},
+		{`{"importance": "high"}`, 0},
+		{`{"importance": null}`, 0},
+		{`{}`, 0},
+	}
+	for _, tt := range tests {
+		var story SummarizedStory
+		if err := json.Unmarshal([]byte(tt.raw), &story); err != nil {
+			t.Errorf("Unmarshal(%s) error: %v", tt.raw, err)
+			continue
+		}
+		if story.Importance != tt.want {
+			t.Errorf("Unmarshal(%s) importance = %d, want %d", tt.raw, story.Importance, tt.want)
+		}
+	}
+}
+
+func TestWrapPrompt(t *testing.T) {
+	tests := []struct {
+		name, prefix, suffix, want string
+	}{
+		{"Neither", "", "", "PROMPT"},
+		{"Blank", "  ", "\n", "PROMPT"},
+		{"Prefix", "Do not fabricate statistics.", "", "Do not fabricate statistics.\n\nPROMPT"},
+		{"Suffix", "", " Cite sources. ", "PROMPT\n\nCite sources."},
+		{"Both", "Before.", "After.", "Before.\n\nPROMPT\n\nAfter."},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := WrapPrompt("PROMPT", tt.prefix, tt.suffix); got != tt.want {
+				t.Errorf("WrapPrompt() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
@@ -10,7 +10,16 @@ type SettingsGetter interface {
 // Provider is the interface that all AI backends must implement.
 type Provider interface {
 	Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error)
-	Name() string // "gemini", "ollama", or "chutes"
+	Name() string // "gemini", "ollama", "chutes", or "openai"
+}
+
+// MissingKeyError is returned by a cloud provider whose API key has not been set.
+type MissingKeyError struct {
+	Provider string // "gemini", "chutes", or "openai"
+}
+
+func (e *MissingKeyError) Error() string {
+	return e.Provider + " API key not configured — set it in Settings"
 }
 
 // ChatRequest is a provider-agnostic request.
@@ -23,10 +32,21 @@ type ChatRequest struct {
 
 // ChatResponse is a provider-agnostic response.
 type ChatResponse struct {
-	Content    string
-	TokensUsed int
-	Model      string // e.g. "gemini-2.5-flash" or "mistral-nemo"
-	Provider   string // "gemini" or "ollama"
+	Content          string
+	TokensUsed       int
+	PromptTokens     int    // input tokens, when the provider reports them separately
+	CompletionTokens int    // output tokens, when the provider reports them separately
+	Model            string // e.g. "gemini-2.5-flash" or "mistral-nemo"
+	Provider         string // "gemini" or "ollama"
+}
+
+// StreamChunk is one piece of a streamed chat response. Every chunk but the
+// last carries a Delta of new content; the last carries either the aggregate
+// Response or the Err that ended the stream.
+type StreamChunk struct {
+	Delta    string
+	Response *ChatResponse
+	Err      error
 }
 
 // Message represents a single message in a chat conversation.
@@ -0,0 +1,67 @@
+package ai
+
+import (
+	"strings"
+	"unicode"
+)
+
+// relevanceStopwords are common words ignored when extracting topic keywords.
+var relevanceStopwords = map[string]bool{
+	"the": true, "and": true, "for": true, "with": true, "about": true, "from": true,
+	"that": true, "this": true, "into": true, "their": true, "its": true, "are": true,
+	"was": true, "were": true, "has": true, "have": true, "how": true, "what": true,
+	"why": true, "who": true, "all": true, "any": true, "facts": true, "fact": true,
+	"news": true, "interesting": true, "things": true, "topic": true,
+}
+
+// KeywordRelevance scores how relevant a fact is to a topic on a 0–1 scale using
+// keyword overlap. A fact mentioning any keyword from the topic name scores 1;
+// otherwise each description keyword it mentions adds 0.5. Words are matched by
+// their first five letters so simple plurals and suffixes still count.
+func KeywordRelevance(fact, topic, description string) float64 {
+	factWords := make(map[string]bool)
+	for _, w := range relevanceKeywords(fact) {
+		factWords[w] = true
+	}
+
+	for _, w := range relevanceKeywords(topic) {
+		if factWords[w] {
+			return 1
+		}
+	}
+
+	score := 0.0
+	for _, w := range relevanceKeywords(description) {
+		if factWords[w] {
+			score += 0.5
+		}
+	}
+	if score > 1 {
+		score = 1
+	}
+	return score
+}
+
+// relevanceKeywords lowercases text, drops stopwords and words shorter than three
+// letters, and returns the distinct remaining word stems.
+func relevanceKeywords(text string) []string {
+	fields := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+
+	seen := make(map[string]bool)
+	var words []string
+	for _, w := range fields {
+		if len(w) < 3 || relevanceStopwords[w] {
+			continue
+		}
+		if r := []rune(w); len(r) > 5 {
+			w = string(r[:5])
+		}
+		if !seen[w] {
+			seen[w] = true
+			words = append(words, w)
+		}
+	}
+	return words
+}
@@ -0,0 +1,25 @@
+package ai
+
+import "testing"
+
+func TestKeywordRelevance(t *testing.T) {
+	tests := []struct {
+		name        string
+		fact        string
+		description string
+		want        float64
+	}{
+		{"Name match", "Roman legions built roads across Europe.", "", 1},
+		{"Plural name match", "The empires of antiquity traded grain.", "", 1},
+		{"Description match", "Julius Caesar crossed the Rubicon in 49 BC.", "Caesar, Augustus and the legions", 0.5},
+		{"Off topic", "Modern Italy adopted the euro in 1999.", "Caesar, Augustus and the legions", 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := KeywordRelevance(tt.fact, "Roman Empire", tt.description); got != tt.want {
+				t.Errorf("KeywordRelevance(%q) = %v, want %v", tt.fact, got, tt.want)
+			}
+		})
+	}
+}
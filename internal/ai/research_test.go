@@ -0,0 +1,46 @@
+package ai
+
+import (
+	"slices"
+	"testing"
+
+	"github.com/thinkscotty/kibble/internal/wikipedia"
+)
+
+func TestRankResearchTitles(t *testing.T) {
+	hits := func(titles ...string) []wikipedia.SearchResult {
+		var r []wikipedia.SearchResult
+		for _, title := range titles {
+			r = append(r, wikipedia.SearchResult{Title: title})
+		}
+		return r
+	}
+
+	tests := []struct {
+		name    string
+		results [][]wikipedia.SearchResult
+		want    []string
+	}{
+		{"Empty", nil, nil},
+		{"Single query keeps order", [][]wikipedia.SearchResult{hits("A", "B", "C")}, []string{"A", "B", "C"}},
+		{"Failed query skipped", [][]wikipedia.SearchResult{nil, hits("A", "B")}, []string{"A", "B"}},
+		{
+			"Shared titles rank first",
+			[][]wikipedia.SearchResult{hits("Magnetar", "Tangent"), hits("Neutron star", "Magnetar"), hits("Magnetar")},
+			[]string{"Magnetar", "Neutron star", "Tangent"},
+		},
+		{
+			"Search rank breaks match ties",
+			[][]wikipedia.SearchResult{hits("A", "B", "C"), hits("C", "B", "A")},
+			[]string{"A", "C", "B"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := RankResearchTitles(tt.results); !slices.Equal(got, tt.want) {
+				t.Errorf("RankResearchTitles() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
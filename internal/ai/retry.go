@@ -0,0 +1,74 @@
+package ai
+
+import (
+	"cmp"
+	"context"
+	"errors"
+	"log/slog"
+	"strconv"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/retry"
+)
+
+// retryingProvider wraps a Provider so a Chat call that fails with a rate
+// limit, server error, or network error is retried up to ai_max_retries times
+// with exponential backoff and jitter. The last error is returned once the
+// retries run out, so callers classify it as before.
+type retryingProvider struct {
+	Provider
+	settings  SettingsGetter
+	baseDelay time.Duration
+}
+
+func (p retryingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	maxRetries := p.maxRetries()
+	for attempt := 0; ; attempt++ {
+		resp, err := p.Provider.Chat(ctx, req)
+		if err == nil || attempt >= maxRetries || ctx.Err() != nil || !isRetryable(err) {
+			return resp, err
+		}
+
+		delay := p.backoff(attempt)
+		if deadline, ok := ctx.Deadline(); ok && time.Until(deadline) < delay {
+			slog.Warn("AI request failed, no time left to retry", "provider", p.Name(), "error", err)
+			return resp, err
+		}
+		slog.Warn("AI request failed, retrying", "provider", p.Name(),
+			"attempt", attempt+1, "max_retries", maxRetries, "delay", delay, "error", err)
+
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return resp, err
+		case <-timer.C:
+		}
+	}
+}
+
+// maxRetries returns the ai_max_retries setting, or 0 when retrying is off.
+func (p retryingProvider) maxRetries() int {
+	v, _ := p.settings.GetSetting("ai_max_retries")
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 0
+	}
+	return n
+}
+
+// backoff returns the wait before retry number attempt+1, from the base delay
+// (retry.BaseDelay unless overridden in tests) with exponential backoff and
+// jitter.
+func (p retryingProvider) backoff(attempt int) time.Duration {
+	return retry.Backoff(cmp.Or(p.baseDelay, retry.BaseDelay), attempt)
+}
+
+// isRetryable reports whether a failed Chat call may succeed if repeated: the
+// provider returned 429 or a 5xx status, or the request never got a response.
+func isRetryable(err error) bool {
+	if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
+		return false
+	}
+	return retry.Transient(err)
+}
@@ -0,0 +1,97 @@
+package ai
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+type failingProvider struct {
+	calls *int
+	errs  []error // returned in order; nil once exhausted
+}
+
+func (p failingProvider) Chat(ctx context.Context, req ChatRequest) (*ChatResponse, error) {
+	n := *p.calls
+	*p.calls++
+	if n < len(p.errs) {
+		return nil, p.errs[n]
+	}
+	return &ChatResponse{Content: "ok", Provider: "gemini"}, nil
+}
+
+func (p failingProvider) Name() string { return "gemini" }
+
+func TestRetryingProvider(t *testing.T) {
+	rateLimited := fmt.Errorf("gemini returned status 429: quota exceeded")
+	unauthorized := fmt.Errorf("gemini returned status 401: bad key")
+
+	tests := []struct {
+		name      string
+		retries   string
+		errs      []error
+		wantCalls int
+		wantErr   error
+	}{
+		{"Succeeds first time", "2", nil, 1, nil},
+		{"Recovers after 429", "2", []error{rateLimited}, 2, nil},
+		{"Gives up with last error", "2", []error{rateLimited, rateLimited, rateLimited, rateLimited}, 3, rateLimited},
+		{"Does not retry 401", "2", []error{unauthorized}, 1, unauthorized},
+		{"Retries off", "0", []error{rateLimited}, 1, rateLimited},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var calls int
+			p := retryingProvider{
+				Provider:  failingProvider{calls: &calls, errs: tt.errs},
+				settings:  mapSettings{"ai_max_retries": tt.retries},
+				baseDelay: time.Millisecond,
+			}
+			_, err := p.Chat(context.Background(), ChatRequest{})
+			if calls != tt.wantCalls {
+				t.Errorf("made %d calls, want %d", calls, tt.wantCalls)
+			}
+			if err != tt.wantErr {
+				t.Errorf("error = %v, want %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestRetryingProviderStopsAtDeadline(t *testing.T) {
+	var calls int
+	p := retryingProvider{
+		Provider:  failingProvider{calls: &calls, errs: []error{fmt.Errorf("gemini returned status 503: overloaded")}},
+		settings:  mapSettings{"ai_max_retries": "3"},
+		baseDelay: time.Minute,
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+	defer cancel()
+	if _, err := p.Chat(ctx, ChatRequest{}); err == nil || calls != 1 {
+		t.Errorf("got %d calls and error %v, want 1 call and the 503 error", calls, err)
+	}
+}
+
+func TestIsRetryable(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("gemini returned status 429: slow down"), true},
+		{fmt.Errorf("openai returned status 503: overloaded"), true},
+		{fmt.Errorf("chutes returned status 400: bad request"), false},
+		{fmt.Errorf("gemini returned status 403: forbidden"), false},
+		{fmt.Errorf("openai request failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{fmt.Errorf("ollama request failed: %w", context.DeadlineExceeded), false},
+		{fmt.Errorf("parse gemini response: unexpected end of JSON input"), false},
+	}
+	for _, tt := range tests {
+		if got := isRetryable(tt.err); got != tt.want {
+			t.Errorf("isRetryable(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -0,0 +1,104 @@
+package ai
+
+import (
+	"regexp"
+	"slices"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// Cleanup rules for SanitizeContent, as stored in the comma-separated
+// content_cleanup setting.
+const (
+	CleanupQuotes       = "quotes"        // trim quotes wrapping the whole text
+	CleanupWhitespace   = "whitespace"    // collapse repeated spaces and blank lines
+	CleanupListMarkers  = "list_markers"  // strip leading "1." / "-" / "*" markers
+	CleanupMarkdown     = "markdown"      // strip bold, code, heading, and link syntax
+	CleanupSentenceCase = "sentence_case" // capitalize the first letter of each sentence
+)
+
+var (
+	listMarkerPattern   = regexp.MustCompile(`(?m)^[ \t]*(?:\d+[.)]|[-*•])[ \t]+`)
+	headingPattern      = regexp.MustCompile(`(?m)^[ \t]*#{1,6}[ \t]+`)
+	markdownLinkPattern = regexp.MustCompile(`\[([^\]]+)\]\([^)\s]+\)`)
+	spacePattern        = regexp.MustCompile(`[ \t]+`)
+	blankLinesPattern   = regexp.MustCompile(`\n\s*\n\s*`)
+)
+
+// wrappingQuotes pairs each opening quote with its closing quote.
+var wrappingQuotes = [][2]string{{`"`, `"`}, {"“", "”"}, {"'", "'"}, {"‘", "’"}}
+
+// SanitizeContent normalizes AI-generated text before it is stored, applying
+// each of the given Cleanup rules. Surrounding whitespace is always trimmed.
+func SanitizeContent(s string, rules []string) string {
+	if slices.Contains(rules, CleanupWhitespace) {
+		s = spacePattern.ReplaceAllString(s, " ")
+		s = blankLinesPattern.ReplaceAllString(s, "\n\n")
+	}
+	s = strings.TrimSpace(s)
+	if slices.Contains(rules, CleanupListMarkers) {
+		s = listMarkerPattern.ReplaceAllString(s, "")
+	}
+	if slices.Contains(rules, CleanupMarkdown) {
+		s = headingPattern.ReplaceAllString(s, "")
+		s = markdownLinkPattern.ReplaceAllString(s, "$1")
+		s = strings.NewReplacer("**", "", "__", "", "`", "").Replace(s)
+	}
+	if slices.Contains(rules, CleanupQuotes) {
+		s = trimWrappingQuotes(strings.TrimSpace(s))
+	}
+	if slices.Contains(rules, CleanupSentenceCase) {
+		s = sentenceCase(s)
+	}
+	return strings.TrimSpace(s)
+}
+
+// trimWrappingQuotes removes one pair of quotes around the whole of s. Quotes are
+// kept when the same quote also appears inside, since then they are not a wrapper
+// (e.g. `"Stop," she said, "now."`).
+func trimWrappingQuotes(s string) string {
+	for _, q := range wrappingQuotes {
+		if len(s) < len(q[0])+len(q[1]) || !strings.HasPrefix(s, q[0]) || !strings.HasSuffix(s, q[1]) {
+			continue
+		}
+		inner := s[len(q[0]) : len(s)-len(q[1])]
+		if strings.Contains(inner, q[0]) || strings.Contains(inner, q[1]) {
+			return s
+		}
+		return strings.TrimSpace(inner)
+	}
+	return s
+}
+
+// sentenceCase upper-cases the first letter of s and of each sentence after
+// ". ", "! ", or "? ". Other letters are left alone so proper nouns survive.
+func sentenceCase(s string) string {
+	var sb strings.Builder
+	capNext := true
+	for i, r := range s {
+		if capNext && unicode.IsLetter(r) {
+			r = unicode.ToUpper(r)
+			capNext = false
+		} else if capNext && !unicode.IsSpace(r) && !strings.ContainsRune(`"'“‘(`, r) {
+			capNext = false
+		}
+		sb.WriteRune(r)
+		if strings.ContainsRune(".!?", r) {
+			next, _ := utf8.DecodeRuneInString(s[i+utf8.RuneLen(r):])
+			capNext = unicode.IsSpace(next)
+		}
+	}
+	return sb.String()
+}
+
+// ParseCleanupRules splits a content_cleanup setting value into rules.
+func ParseCleanupRules(v string) []string {
+	var rules []string
+	for _, r := range strings.Split(v, ",") {
+		if r = strings.TrimSpace(r); r != "" {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
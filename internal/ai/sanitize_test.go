@@ -0,0 +1,34 @@
+package ai
+
+import "testing"
+
+func TestSanitizeContent(t *testing.T) {
+	all := []string{CleanupQuotes, CleanupWhitespace, CleanupListMarkers, CleanupMarkdown, CleanupSentenceCase}
+
+	tests := []struct {
+		name  string
+		text  string
+		rules []string
+		want  string
+	}{
+		{"No rules", `  "1. Hello   world"  `, nil, `"1. Hello   world"`},
+		{"Wrapping quotes", `"Octopuses have three hearts."`, []string{CleanupQuotes}, "Octopuses have three hearts."},
+		{"Curly quotes", "“Octopuses have three hearts.”", []string{CleanupQuotes}, "Octopuses have three hearts."},
+		{"Inner quotes kept", `"Stop," she said, "now."`, []string{CleanupQuotes}, `"Stop," she said, "now."`},
+		{"Whitespace", "One  two\t three.\n\n\n\nFour.", []string{CleanupWhitespace}, "One two three.\n\nFour."},
+		{"List marker", "3. Octopuses have three hearts.", []string{CleanupListMarkers}, "Octopuses have three hearts."},
+		{"Decimal kept", "2.5 million people live there.", []string{CleanupListMarkers}, "2.5 million people live there."},
+		{"Markdown", "## **Octopuses** have `three` [hearts](https://example.com).", []string{CleanupMarkdown}, "Octopuses have three hearts."},
+		{"Sentence case", "octopuses have three hearts. they also have NASA fans.", []string{CleanupSentenceCase}, "Octopuses have three hearts. They also have NASA fans."},
+		{"Decimal not a sentence", "it grew 2.5 times.", []string{CleanupSentenceCase}, "It grew 2.5 times."},
+		{"All rules", `  - "**octopuses**  have three hearts."  `, all, "Octopuses have three hearts."},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := SanitizeContent(tt.text, tt.rules); got != tt.want {
+				t.Errorf("SanitizeContent(%q, %v) = %q, want %q", tt.text, tt.rules, got, tt.want)
+			}
+		})
+	}
+}
@@ -1,5 +1,11 @@
 package ai
 
+import (
+	"math"
+	"strconv"
+	"strings"
+)
+
 // DiscoveredSource is a web source found by AI for a news topic.
 type DiscoveredSource struct {
 	URL         string `json:"url"`
@@ -9,17 +15,51 @@ type DiscoveredSource struct {
 
 // SummarizedStory is a news story summary produced by AI.
 type SummarizedStory struct {
-	Title       string `json:"title"`
-	Summary     string `json:"summary"`
-	SourceURL   string `json:"source_url"`
-	SourceTitle string `json:"source_title"`
+	Title         string `json:"title"`
+	Summary       string `json:"summary"`
+	Quote         string `json:"quote,omitempty"` // only requested when SummarizeOpts.IncludeQuotes is set
+	SourceURL     string `json:"source_url"`
+	SourceTitle   string `json:"source_title"`
+	Author        string `json:"author,omitempty"`         // byline from the source's AUTHOR line, if any
+	Importance    Score  `json:"importance"`               // 1 (minor) to 10 (major), 0 if not given
+	PublishedDate string `json:"published_date,omitempty"` // YYYY-MM-DD when the source shows a date
+}
+
+// Score is an integer rating from the model. Models sometimes quote numbers or
+// add decimals, so any number-like value is accepted and anything else reads as 0
+// rather than failing the whole response.
+type Score int
+
+func (s *Score) UnmarshalJSON(b []byte) error {
+	v := strings.Trim(strings.TrimSpace(string(b)), `"`)
+	f, err := strconv.ParseFloat(strings.TrimSpace(v), 64)
+	if err != nil {
+		*s = 0
+		return nil
+	}
+	*s = Score(math.Round(f))
+	return nil
+}
+
+// GeneratedFact is a fact produced by AI. SourceTitle and SourceURL are set when
+// the fact was grounded in a research article the model cited. Confidence is the
+// model's own rating, set only when verifiable facts were requested.
+type GeneratedFact struct {
+	Content     string
+	SourceTitle string
+	SourceURL   string
+	Confidence  string // ConfidenceHigh, ConfidenceMedium, ConfidenceLow, or ""
 }
 
-// ScrapedContent holds raw content scraped from a web source.
+// ScrapedContent holds raw content scraped from a web source. Bylines maps an
+// article link to its author, for feed items and Reddit posts that name one.
 type ScrapedContent struct {
-	URL        string
-	SourceName string
-	Content    string
+	URL          string
+	SourceName   string
+	Content      string
+	Bylines      map[string]string
+	ETag         string // feed validators, sent back on the next fetch of the source
+	LastModified string
 }
 
 // OllamaModel represents a model available on an Ollama server.
@@ -30,6 +70,14 @@ type OllamaModel struct {
 	Family        string `json:"family"`
 }
 
+// Sampling settings used when a topic does not override them.
+const (
+	DefaultFactsTemperature     = 0.9
+	DefaultFactsMaxTokens       = 2048
+	DefaultSummarizeTemperature = 0.7
+	DefaultSummarizeMaxTokens   = 4096
+)
+
 // FactsOpts holds parameters for fact generation.
 type FactsOpts struct {
 	Topic              string
@@ -41,6 +89,13 @@ type FactsOpts struct {
 	MaxWords           int
 	AIProvider         string // per-topic override: "", "gemini", "ollama"
 	IsNiche            bool
+	SkipResearch       bool     // niche topic with Wikipedia research turned off
+	RequireVerifiable  bool     // ask for confident, checkable statements with a confidence rating each
+	ExcludeFacts       []string // facts already kept this refresh, for a follow-up request
+	SeriesMode         bool     // facts continue an ordered sequence instead of standing alone
+	SeriesSoFar        []string // latest facts of the series in order, for continuing it
+	Temperature        float64  // per-topic sampling temperature; 0 uses the default
+	MaxTokens          int      // per-topic response token limit; 0 uses the default
 }
 
 // DiscoverOpts holds parameters for news source discovery.
@@ -49,6 +104,7 @@ type DiscoverOpts struct {
 	Description          string
 	SourcingInstructions string
 	AIProvider           string
+	DiscoveryProvider    string // Per-topic override for discovery only; "" uses the discovery_provider setting
 	IsNiche              bool
 	CommunityDomains     []string // Domains frequently shared in related subreddits
 }
@@ -64,4 +120,34 @@ type SummarizeOpts struct {
 	MaxWords                int
 	AIProvider              string
 	ExistingTitles          []string // Recent story titles for dedup
+	RelaxedFiltering        bool     // Loosen the on-topic filter, for a retry after nothing passed
+	ContentFocus            string   // "" for articles, ContentFocusLinks for link aggregators
+	IncludeQuotes           bool     // Ask for a short verbatim quote from the source with each story
+	Voice                   string   // preset editorial voice; VoiceCustom or "" uses ToneInstructions
+	Temperature             float64  // per-topic sampling temperature; 0 uses the default
+	MaxTokens               int      // per-topic response token limit; 0 uses the default
+}
+
+// ContentFocusLinks marks a news topic whose sources are link aggregators (Reddit,
+// Hacker News), where titles, links, and scores matter more than item bodies.
+const ContentFocusLinks = "links"
+
+// Confidence levels a model gives facts when a topic requires verifiable facts.
+const (
+	ConfidenceHigh   = "high"
+	ConfidenceMedium = "medium"
+	ConfidenceLow    = "low"
+)
+
+// IsConfident reports whether a fact's confidence level is high enough to keep
+// it for a topic that requires verifiable facts. Unrated facts are not.
+func IsConfident(level string) bool {
+	return level == ConfidenceHigh || level == ConfidenceMedium
 }
+
+// Content types with their own default provider setting (ai_provider_facts,
+// ai_provider_news), used with Client.ContentProvider.
+const (
+	ContentFacts = "facts"
+	ContentNews  = "news"
+)
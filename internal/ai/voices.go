@@ -0,0 +1,43 @@
+package ai
+
+// Editorial voices a news topic can use for its story summaries. VoiceCustom
+// uses the free-text news_tone_instructions setting instead of a preset.
+const (
+	VoiceCustom      = "custom"
+	VoiceNeutral     = "neutral"
+	VoiceExplanatory = "explanatory"
+	VoiceWitty       = "witty"
+	VoiceAcademic    = "academic"
+)
+
+// voices maps each preset voice to the tone instructions sent to the summarizer.
+var voices = map[string]string{
+	VoiceNeutral: `Write in a neutral wire-service style. Lead with the most important fact: who, what, when, and where.
+Use short, plain sentences and attribute claims to their sources ("officials said", "according to the report").
+Do not editorialize, speculate, or use loaded adjectives.`,
+	VoiceExplanatory: `Write for a curious reader who is new to the subject. State what happened, then explain why it matters
+and what background is needed to understand it. Define jargon and acronyms in plain words the first time they appear.
+Stay factual; explain rather than persuade.`,
+	VoiceWitty: `Write with a light, wry touch, like a sharp columnist's news roundup. Allow one dry aside or turn of phrase
+per story, but keep every fact accurate and the news itself clear. Never joke about tragedies, deaths, or victims;
+report those plainly.`,
+	VoiceAcademic: `Write in a measured, precise academic register. Distinguish established findings from claims and
+preliminary results, note methods, sample sizes, or limitations when the source gives them, and avoid hype words
+such as "breakthrough" or "revolutionary" unless quoting.`,
+}
+
+// IsVoice reports whether voice names a preset voice.
+func IsVoice(voice string) bool {
+	_, ok := voices[voice]
+	return ok
+}
+
+// VoiceInstructions returns the tone instructions for a news topic's voice: the
+// preset's instructions, or custom (the free-text tone setting) for VoiceCustom
+// and any voice that is not a preset.
+func VoiceInstructions(voice, custom string) string {
+	if instr, ok := voices[voice]; ok {
+		return instr
+	}
+	return custom
+}
@@ -0,0 +1,28 @@
+package ai
+
+import "testing"
+
+func TestVoiceInstructions(t *testing.T) {
+	const custom = "Keep it upbeat."
+	tests := []struct {
+		voice string
+		want  string
+	}{
+		{VoiceCustom, custom},
+		{"", custom},
+		{"pirate", custom},
+		{VoiceNeutral, voices[VoiceNeutral]},
+		{VoiceAcademic, voices[VoiceAcademic]},
+	}
+
+	for _, tt := range tests {
+		if got := VoiceInstructions(tt.voice, custom); got != tt.want {
+			t.Errorf("VoiceInstructions(%q) = %q, want %q", tt.voice, got, tt.want)
+		}
+	}
+	for _, v := range []string{VoiceNeutral, VoiceExplanatory, VoiceWitty, VoiceAcademic} {
+		if !IsVoice(v) || voices[v] == "" {
+			t.Errorf("preset voice %q has no instructions", v)
+		}
+	}
+}
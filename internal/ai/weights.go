@@ -0,0 +1,76 @@
+package ai
+
+import (
+	"fmt"
+	"math/rand"
+	"strconv"
+	"strings"
+)
+
+// ProviderWeight is one entry of the ai_provider_weights setting.
+type ProviderWeight struct {
+	Provider string
+	Weight   int
+}
+
+// ParseProviderWeights parses an ai_provider_weights value such as
+// "gemini:70,ollama:30". An empty value yields no weights. Unknown providers,
+// repeated providers, and weights that are not positive integers are errors.
+func ParseProviderWeights(s string) ([]ProviderWeight, error) {
+	var weights []ProviderWeight
+	seen := make(map[string]bool)
+	for _, entry := range strings.Split(s, ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		name, value, ok := strings.Cut(entry, ":")
+		if !ok {
+			return nil, fmt.Errorf("%q: expected provider:weight", entry)
+		}
+		name = strings.ToLower(strings.TrimSpace(name))
+		switch name {
+		case "gemini", "ollama", "chutes", "openai":
+		default:
+			return nil, fmt.Errorf("%q: unknown provider %q", entry, name)
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("%q: provider %q listed twice", entry, name)
+		}
+		weight, err := strconv.Atoi(strings.TrimSpace(value))
+		if err != nil || weight <= 0 {
+			return nil, fmt.Errorf("%q: weight must be a positive whole number", entry)
+		}
+		seen[name] = true
+		weights = append(weights, ProviderWeight{Provider: name, Weight: weight})
+	}
+	return weights, nil
+}
+
+// pickWeighted returns the provider that n falls on, where n is in
+// [0, total weight).
+func pickWeighted(weights []ProviderWeight, n int) string {
+	for _, w := range weights {
+		if n < w.Weight {
+			return w.Provider
+		}
+		n -= w.Weight
+	}
+	return weights[len(weights)-1].Provider
+}
+
+// weightedProvider picks a provider at random according to the
+// ai_provider_weights setting. It returns "" when no weights are configured
+// or the setting is invalid.
+func (c *Client) weightedProvider() string {
+	raw, _ := c.settings.GetSetting("ai_provider_weights")
+	weights, err := ParseProviderWeights(raw)
+	if err != nil || len(weights) == 0 {
+		return ""
+	}
+	total := 0
+	for _, w := range weights {
+		total += w.Weight
+	}
+	return pickWeighted(weights, rand.Intn(total))
+}
@@ -0,0 +1,45 @@
+package ai
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestParseProviderWeights(t *testing.T) {
+	tests := []struct {
+		name    string
+		value   string
+		want    []ProviderWeight
+		wantErr bool
+	}{
+		{"Empty", "", nil, false},
+		{"Two providers", "gemini:70,ollama:30", []ProviderWeight{{"gemini", 70}, {"ollama", 30}}, false},
+		{"Spaces and case", " Gemini : 1 , chutes:3 ,", []ProviderWeight{{"gemini", 1}, {"chutes", 3}}, false},
+		{"OpenAI", "openai:2,ollama:1", []ProviderWeight{{"openai", 2}, {"ollama", 1}}, false},
+		{"Missing weight", "gemini", nil, true},
+		{"Unknown provider", "anthropic:50", nil, true},
+		{"Zero weight", "gemini:0", nil, true},
+		{"Repeated provider", "gemini:1,gemini:2", nil, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := ParseProviderWeights(tt.value)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("ParseProviderWeights(%q) error = %v, wantErr %v", tt.value, err, tt.wantErr)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("ParseProviderWeights(%q) = %v, want %v", tt.value, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestPickWeighted(t *testing.T) {
+	weights := []ProviderWeight{{"gemini", 70}, {"ollama", 30}}
+	for n, want := range map[int]string{0: "gemini", 69: "gemini", 70: "ollama", 99: "ollama"} {
+		if got := pickWeighted(weights, n); got != want {
+			t.Errorf("pickWeighted(%d) = %q, want %q", n, got, want)
+		}
+	}
+}
@@ -23,7 +23,9 @@ type ServerConfig struct {
 }
 
 type DatabaseConfig struct {
-	Path string `yaml:"path"`
+	Driver string `yaml:"driver"` // "sqlite" or "postgres"
+	Path   string `yaml:"path"`   // SQLite database file
+	DSN    string `yaml:"dsn"`    // Postgres connection string
 }
 
 type LoggingConfig struct {
@@ -31,8 +33,9 @@ type LoggingConfig struct {
 }
 
 type SimilarityConfig struct {
-	Threshold float64 `yaml:"threshold"`
-	NGramSize int     `yaml:"ngram_size"`
+	Threshold          float64 `yaml:"threshold"`
+	NGramSize          int     `yaml:"ngram_size"`
+	EmbeddingThreshold float64 `yaml:"embedding_threshold"` // cosine cutoff when similarity_mode is "embedding"
 }
 
 func DefaultConfig() Config {
@@ -45,14 +48,16 @@ func DefaultConfig() Config {
 			IdleTimeoutSeconds:  120,
 		},
 		Database: DatabaseConfig{
-			Path: "./kibble.db",
+			Driver: "sqlite",
+			Path:   "./kibble.db",
 		},
 		Logging: LoggingConfig{
 			Level: "info",
 		},
 		Similarity: SimilarityConfig{
-			Threshold: 0.6,
-			NGramSize: 3,
+			Threshold:          0.6,
+			NGramSize:          3,
+			EmbeddingThreshold: 0.85,
 		},
 	}
 }
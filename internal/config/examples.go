@@ -0,0 +1,34 @@
+package config
+
+import (
+	"fmt"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ExampleTopic describes a starter topic bundled in examples.yaml.
+type ExampleTopic struct {
+	Name                   string `yaml:"name"`
+	Description            string `yaml:"description"`
+	FactsPerRefresh        int    `yaml:"facts_per_refresh"`
+	StoriesPerRefresh      int    `yaml:"stories_per_refresh"`
+	RefreshIntervalMinutes int    `yaml:"refresh_interval_minutes"`
+	SummaryMinWords        int    `yaml:"summary_min_words"`
+	SummaryMaxWords        int    `yaml:"summary_max_words"`
+	IsNiche                bool   `yaml:"is_niche"`
+}
+
+// Examples holds the fact and news topics used to seed a fresh install.
+type Examples struct {
+	FactTopics []ExampleTopic `yaml:"fact_topics"`
+	NewsTopics []ExampleTopic `yaml:"news_topics"`
+}
+
+// ParseExamples parses the bundled examples YAML.
+func ParseExamples(data []byte) (Examples, error) {
+	var ex Examples
+	if err := yaml.Unmarshal(data, &ex); err != nil {
+		return ex, fmt.Errorf("parse examples: %w", err)
+	}
+	return ex, nil
+}
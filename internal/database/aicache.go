@@ -0,0 +1,39 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+)
+
+// GetCachedAIResponse returns the cached AI response for key if it was stored
+// within maxAge. ok is false on a miss or an expired entry.
+func (db *DB) GetCachedAIResponse(key string, maxAge time.Duration) (content, model string, tokensUsed int, ok bool, err error) {
+	err = db.conn.QueryRow(`
+		SELECT content, model, tokens_used FROM ai_response_cache
+		WHERE key = ? AND created_at >= ?`,
+		key, formatTime(time.Now().Add(-maxAge))).Scan(&content, &model, &tokensUsed)
+	if err == sql.ErrNoRows {
+		return "", "", 0, false, nil
+	}
+	if err != nil {
+		return "", "", 0, false, err
+	}
+	return content, model, tokensUsed, true, nil
+}
+
+// PutCachedAIResponse stores an AI response under key, replacing any previous
+// entry, and prunes entries older than maxAge so the table stays small.
+func (db *DB) PutCachedAIResponse(key, provider, model, content string, tokensUsed int, maxAge time.Duration) error {
+	if _, err := db.conn.Exec(`DELETE FROM ai_response_cache WHERE created_at < ?`,
+		formatTime(time.Now().Add(-maxAge))); err != nil {
+		return err
+	}
+	_, err := db.conn.Exec(`
+		INSERT INTO ai_response_cache (key, provider, model, content, tokens_used)
+		VALUES (?, ?, ?, ?, ?)
+		ON CONFLICT(key) DO UPDATE SET
+			provider = excluded.provider, model = excluded.model, content = excluded.content,
+			tokens_used = excluded.tokens_used, created_at = excluded.created_at`,
+		key, provider, model, content, tokensUsed)
+	return err
+}
@@ -0,0 +1,146 @@
+package database
+
+import (
+	"database/sql"
+	"strconv"
+	"strings"
+	"sync"
+)
+
+// Database drivers selectable with the database.driver config key.
+const (
+	DriverSQLite   = "sqlite"
+	DriverPostgres = "postgres"
+)
+
+// dialect is the part of the SQL that differs between database backends.
+// Queries in this package are written to run on both; where they can't be,
+// the query is chosen by driver. A dialect only adapts the schema statements,
+// which use SQLite types, and the placeholder syntax.
+type dialect interface {
+	// translate rewrites a query's ? placeholders into this dialect.
+	translate(query string) string
+	// translateSchema rewrites a CREATE or ALTER statement, or another
+	// migration in alterStatements, written for SQLite into this dialect.
+	translateSchema(stmt string) string
+}
+
+type sqliteDialect struct{}
+
+func (sqliteDialect) translate(query string) string      { return query }
+func (sqliteDialect) translateSchema(stmt string) string { return stmt }
+
+// pgDatetimeFunction defines datetime('now') in Postgres, so queries and column
+// defaults use the same SQL on both backends: the current UTC time as text in
+// the format timestamps are stored in. Queries only call it with 'now'. It is
+// created before the tables, whose defaults use it.
+const pgDatetimeFunction = `CREATE OR REPLACE FUNCTION datetime(text) RETURNS text
+	LANGUAGE sql STABLE
+	AS $$ SELECT to_char(now() AT TIME ZONE 'UTC', 'YYYY-MM-DD HH24:MI:SS') $$`
+
+// pgSchemaReplacer rewrites the SQLite column types and migration SQL in this
+// package's schema statements. It is never applied to queries. Timestamps stay
+// TEXT columns in the same format on both backends, so stored values and
+// comparisons between them behave the same.
+var pgSchemaReplacer = strings.NewReplacer(
+	"INTEGER PRIMARY KEY AUTOINCREMENT", "BIGSERIAL PRIMARY KEY",
+	"INTEGER", "BIGINT",
+	" REAL ", " DOUBLE PRECISION ",
+	"ADD COLUMN", "ADD COLUMN IF NOT EXISTS",
+	"abs(random()) % 60", "floor(random() * 60)",
+)
+
+type postgresDialect struct {
+	cache sync.Map // SQLite query -> translated query
+}
+
+func (d *postgresDialect) translate(query string) string {
+	if q, ok := d.cache.Load(query); ok {
+		return q.(string)
+	}
+	q := rebind(query)
+	d.cache.Store(query, q)
+	return q
+}
+
+func (d *postgresDialect) translateSchema(stmt string) string {
+	return pgSchemaReplacer.Replace(stmt)
+}
+
+// rebind replaces each ? placeholder outside a string literal with Postgres'
+// numbered $1, $2, ... form.
+func rebind(query string) string {
+	var b strings.Builder
+	n := 0
+	inString := false
+	for _, r := range query {
+		switch {
+		case r == '\'':
+			inString = !inString
+		case r == '?' && !inString:
+			n++
+			b.WriteString("$" + strconv.Itoa(n))
+			continue
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// conn is a *sql.DB that translates every query into its dialect. Methods it
+// doesn't override pass straight through.
+type conn struct {
+	*sql.DB
+	dialect dialect
+}
+
+func (c *conn) Exec(query string, args ...any) (sql.Result, error) {
+	return c.DB.Exec(c.dialect.translate(query), args...)
+}
+
+func (c *conn) Query(query string, args ...any) (*sql.Rows, error) {
+	return c.DB.Query(c.dialect.translate(query), args...)
+}
+
+func (c *conn) QueryRow(query string, args ...any) *sql.Row {
+	return c.DB.QueryRow(c.dialect.translate(query), args...)
+}
+
+func (c *conn) Prepare(query string) (*sql.Stmt, error) {
+	return c.DB.Prepare(c.dialect.translate(query))
+}
+
+// execSchema runs a schema statement, translated with translateSchema.
+func (c *conn) execSchema(stmt string) (sql.Result, error) {
+	return c.DB.Exec(c.dialect.translateSchema(stmt))
+}
+
+// insertID runs an INSERT and returns the new row's id. It uses RETURNING,
+// which both backends support, because Postgres drivers have no LastInsertId.
+func (c *conn) insertID(query string, args ...any) (int64, error) {
+	var id int64
+	err := c.QueryRow(query+` RETURNING id`, args...).Scan(&id)
+	return id, err
+}
+
+func (c *conn) Begin() (*tx, error) {
+	t, err := c.DB.Begin()
+	if err != nil {
+		return nil, err
+	}
+	return &tx{Tx: t, dialect: c.dialect}, nil
+}
+
+// tx is a *sql.Tx that translates every query into its dialect.
+type tx struct {
+	*sql.Tx
+	dialect dialect
+}
+
+func (t *tx) Exec(query string, args ...any) (sql.Result, error) {
+	return t.Tx.Exec(t.dialect.translate(query), args...)
+}
+
+func (t *tx) Prepare(query string) (*sql.Stmt, error) {
+	return t.Tx.Prepare(t.dialect.translate(query))
+}
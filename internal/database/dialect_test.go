@@ -0,0 +1,116 @@
+package database
+
+import (
+	"regexp"
+	"strings"
+	"testing"
+)
+
+func TestPostgresTranslateSchema(t *testing.T) {
+	d := &postgresDialect{}
+	integerRe := regexp.MustCompile(`\bINTEGER\b`)
+	for _, stmt := range alterStatements {
+		got := d.translateSchema(stmt)
+		if strings.HasPrefix(stmt, "ALTER TABLE ") && strings.Count(got, " ADD COLUMN IF NOT EXISTS ") != 1 {
+			t.Errorf("translateSchema(%q) = %q, want ADD COLUMN IF NOT EXISTS once", stmt, got)
+		}
+		if integerRe.MatchString(got) {
+			t.Errorf("translateSchema(%q) = %q, want INTEGER as BIGINT", stmt, got)
+		}
+		if strings.Contains(stmt, " REAL ") && !strings.Contains(got, " DOUBLE PRECISION ") {
+			t.Errorf("translateSchema(%q) = %q, want REAL as DOUBLE PRECISION", stmt, got)
+		}
+		if strings.Contains(got, "abs(random())") {
+			t.Errorf("translateSchema(%q) = %q, want Postgres random()", stmt, got)
+		}
+	}
+
+	tests := []struct {
+		stmt string
+		want string
+	}{
+		{
+			"ALTER TABLE topics ADD COLUMN summary_min_words INTEGER NOT NULL DEFAULT 0",
+			"ALTER TABLE topics ADD COLUMN IF NOT EXISTS summary_min_words BIGINT NOT NULL DEFAULT 0",
+		},
+		{
+			"ALTER TABLE topics ADD COLUMN temperature REAL NOT NULL DEFAULT 0",
+			"ALTER TABLE topics ADD COLUMN IF NOT EXISTS temperature DOUBLE PRECISION NOT NULL DEFAULT 0",
+		},
+		{
+			"UPDATE topics SET schedule_offset_minutes = abs(random()) % 60",
+			"UPDATE topics SET schedule_offset_minutes = floor(random() * 60)",
+		},
+		{
+			"CREATE TABLE IF NOT EXISTS sessions (id INTEGER PRIMARY KEY AUTOINCREMENT, created_at TEXT NOT NULL DEFAULT (datetime('now')))",
+			"CREATE TABLE IF NOT EXISTS sessions (id BIGSERIAL PRIMARY KEY, created_at TEXT NOT NULL DEFAULT (datetime('now')))",
+		},
+	}
+	for _, tt := range tests {
+		if got := d.translateSchema(tt.stmt); got != tt.want {
+			t.Errorf("translateSchema(%q)\n got %q\nwant %q", tt.stmt, got, tt.want)
+		}
+	}
+}
+
+func TestPostgresTranslate(t *testing.T) {
+	tests := []struct {
+		name  string
+		query string
+		want  string
+	}{
+		{
+			name:  "Placeholders",
+			query: `UPDATE facts SET is_archived = ?, updated_at = datetime('now') WHERE id = ?`,
+			want:  `UPDATE facts SET is_archived = $1, updated_at = datetime('now') WHERE id = $2`,
+		},
+		{
+			name: "Repeated parameters",
+			query: `UPDATE facts SET is_archived = 1
+				WHERE topic_id = ? AND (is_custom = 0 OR ? = 1) AND id NOT IN (
+					SELECT id FROM facts WHERE topic_id = ? AND (is_custom = 0 OR ? = 1) LIMIT ?)`,
+			want: `UPDATE facts SET is_archived = 1
+				WHERE topic_id = $1 AND (is_custom = 0 OR $2 = 1) AND id NOT IN (
+					SELECT id FROM facts WHERE topic_id = $3 AND (is_custom = 0 OR $4 = 1) LIMIT $5)`,
+		},
+		{
+			name:  "Question mark in a string literal",
+			query: `SELECT COUNT(*) FROM facts WHERE content = 'why?' AND topic_id = ?`,
+			want:  `SELECT COUNT(*) FROM facts WHERE content = 'why?' AND topic_id = $1`,
+		},
+		{
+			name:  "Escaped quote in a string literal",
+			query: `SELECT id FROM facts WHERE content = 'it''s ?' AND id = ?`,
+			want:  `SELECT id FROM facts WHERE content = 'it''s ?' AND id = $1`,
+		},
+		{
+			name:  "Schema words left alone",
+			query: `SELECT id FROM facts WHERE content LIKE ? AND source != 'INTEGER ADD COLUMN'`,
+			want:  `SELECT id FROM facts WHERE content LIKE $1 AND source != 'INTEGER ADD COLUMN'`,
+		},
+	}
+
+	d := &postgresDialect{}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := d.translate(tt.query)
+			if got != tt.want {
+				t.Errorf("translate()\n got %q\nwant %q", got, tt.want)
+			}
+			if again := d.translate(tt.query); again != got {
+				t.Errorf("cached translate() = %q, want %q", again, got)
+			}
+		})
+	}
+}
+
+func TestSQLiteTranslateUnchanged(t *testing.T) {
+	query := `ALTER TABLE facts ADD COLUMN confidence INTEGER NOT NULL DEFAULT 0`
+	d := sqliteDialect{}
+	if got := d.translate(query); got != query {
+		t.Errorf("translate() = %q, want the query unchanged", got)
+	}
+	if got := d.translateSchema(query); got != query {
+		t.Errorf("translateSchema() = %q, want the statement unchanged", got)
+	}
+}
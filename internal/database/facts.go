@@ -3,6 +3,7 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
 	"time"
 
 	"github.com/thinkscotty/kibble/internal/models"
@@ -10,17 +11,26 @@ import (
 
 // StoredTrigrams holds minimal data for similarity comparison.
 type StoredTrigrams struct {
-	ID       int64
-	Trigrams string
+	ID        int64
+	Trigrams  string
+	Embedding string // JSON embedding vector, or "" if the fact has none
 }
 
 func (db *DB) ListFactsByTopic(topicID int64, limit int) ([]models.Fact, error) {
+	return db.ListFactsByTopicPage(topicID, limit, 0, false)
+}
+
+// ListFactsByTopicPage returns up to limit of a topic's facts, newest first,
+// skipping the first offset of them. Archived facts are left out unless
+// includeArchived is set.
+func (db *DB) ListFactsByTopicPage(topicID int64, limit, offset int, includeArchived bool) ([]models.Fact, error) {
 	rows, err := db.conn.Query(`
 		SELECT f.id, f.topic_id, f.content, f.trigrams, f.is_custom, f.is_archived,
-		       f.source, f.ai_provider, f.ai_model, f.created_at, f.updated_at
+		       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+		       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
 		FROM facts f
-		WHERE f.topic_id = ? AND f.is_archived = 0
-		ORDER BY f.created_at DESC LIMIT ?`, topicID, limit)
+		WHERE f.topic_id = ? AND (f.is_archived = 0 OR ?)
+		ORDER BY f.created_at DESC, f.sequence_index DESC, f.id DESC LIMIT ? OFFSET ?`, topicID, includeArchived, limit, offset)
 	if err != nil {
 		return nil, err
 	}
@@ -28,15 +38,76 @@ func (db *DB) ListFactsByTopic(topicID int64, limit int) ([]models.Fact, error)
 	return scanFacts(rows)
 }
 
+// AllFactsGroupedByTopic returns the non-archived facts of active topics in one
+// query, newest first within each topic, keyed by topic ID. topicIDs restricts the
+// result to those topics (nil for all), and limitPerTopic caps the facts returned
+// per topic (0 for no cap). Trigrams are not loaded.
+func (db *DB) AllFactsGroupedByTopic(topicIDs []int64, limitPerTopic int) (map[int64][]models.Fact, error) {
+	where := `f.is_archived = 0 AND t.is_active = 1`
+	var args []any
+	if len(topicIDs) > 0 {
+		where += ` AND f.topic_id IN (?` + strings.Repeat(`, ?`, len(topicIDs)-1) + `)`
+		for _, id := range topicIDs {
+			args = append(args, id)
+		}
+	}
+
+	query := `
+		SELECT f.id, f.topic_id, f.content, '' AS trigrams, f.is_custom, f.is_archived,
+		       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+		       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
+		FROM facts f
+		JOIN topics t ON t.id = f.topic_id
+		WHERE ` + where + `
+		ORDER BY f.topic_id, f.created_at DESC`
+	if limitPerTopic > 0 {
+		// Rank facts within each topic so the cap is applied by the database, not after loading
+		query = `
+		SELECT id, topic_id, content, trigrams, is_custom, is_archived,
+		       source, ai_provider, ai_model, source_title, source_url,
+		       word_count, sequence_index, confidence, created_at, updated_at
+		FROM (
+			SELECT f.id, f.topic_id, f.content, '' AS trigrams, f.is_custom, f.is_archived,
+			       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+			       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at,
+			       ROW_NUMBER() OVER (PARTITION BY f.topic_id ORDER BY f.created_at DESC) AS rank
+			FROM facts f
+			JOIN topics t ON t.id = f.topic_id
+			WHERE ` + where + `
+		) AS ranked
+		WHERE rank <= ?
+		ORDER BY topic_id, created_at DESC`
+		args = append(args, limitPerTopic)
+	}
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	facts, err := scanFacts(rows)
+	if err != nil {
+		return nil, err
+	}
+	grouped := make(map[int64][]models.Fact)
+	for _, f := range facts {
+		grouped[f.TopicID] = append(grouped[f.TopicID], f)
+	}
+	return grouped, nil
+}
+
 func (db *DB) GetFact(id int64) (models.Fact, error) {
 	var f models.Fact
 	var createdAt, updatedAt string
 	err := db.conn.QueryRow(`
 		SELECT f.id, f.topic_id, f.content, f.trigrams, f.is_custom, f.is_archived,
-		       f.source, f.ai_provider, f.ai_model, f.created_at, f.updated_at
+		       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+		       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
 		FROM facts f WHERE f.id = ?`, id).Scan(
 		&f.ID, &f.TopicID, &f.Content, &f.Trigrams, &f.IsCustom, &f.IsArchived,
-		&f.Source, &f.AIProvider, &f.AIModel, &createdAt, &updatedAt)
+		&f.Source, &f.AIProvider, &f.AIModel, &f.SourceTitle, &f.SourceURL,
+		&f.WordCount, &f.SequenceIndex, &f.Confidence, &createdAt, &updatedAt)
 	if err != nil {
 		return f, err
 	}
@@ -46,15 +117,13 @@ func (db *DB) GetFact(id int64) (models.Fact, error) {
 }
 
 func (db *DB) CreateFact(f *models.Fact) error {
-	result, err := db.conn.Exec(`
-		INSERT INTO facts (topic_id, content, trigrams, is_custom, source, ai_provider, ai_model)
-		VALUES (?, ?, ?, ?, ?, ?, ?)`,
-		f.TopicID, f.Content, f.Trigrams, boolToInt(f.IsCustom), f.Source,
-		f.AIProvider, f.AIModel)
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+	f.WordCount = countWords(f.Content)
+	id, err := db.conn.insertID(`
+		INSERT INTO facts (topic_id, content, trigrams, embedding, is_custom, source, ai_provider, ai_model,
+		                   source_title, source_url, word_count, sequence_index, confidence)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		f.TopicID, f.Content, f.Trigrams, f.Embedding, boolToInt(f.IsCustom), f.Source,
+		f.AIProvider, f.AIModel, f.SourceTitle, f.SourceURL, f.WordCount, f.SequenceIndex, f.Confidence)
 	if err != nil {
 		return err
 	}
@@ -62,15 +131,27 @@ func (db *DB) CreateFact(f *models.Fact) error {
 	return nil
 }
 
+// UpdateFact saves a fact's edited content, trigrams, and embedding. Callers
+// leave Embedding empty when they have none for the new content, which clears
+// the stale one.
 func (db *DB) UpdateFact(f *models.Fact) error {
+	f.WordCount = countWords(f.Content)
 	_, err := db.conn.Exec(`
-		UPDATE facts SET content = ?, trigrams = ?, updated_at = datetime('now')
-		WHERE id = ?`, f.Content, f.Trigrams, f.ID)
+		UPDATE facts SET content = ?, trigrams = ?, embedding = ?, word_count = ?, updated_at = datetime('now')
+		WHERE id = ?`, f.Content, f.Trigrams, f.Embedding, f.WordCount, f.ID)
 	return err
 }
 
+// DeleteFact archives a fact. Use HardDeleteFact to remove it.
 func (db *DB) DeleteFact(id int64) error {
-	_, err := db.conn.Exec(`UPDATE facts SET is_archived = 1, updated_at = datetime('now') WHERE id = ?`, id)
+	return db.SetFactArchived(id, true)
+}
+
+// SetFactArchived archives or unarchives a fact. Archived facts are kept but
+// left out of the dashboard, the API, and duplicate checks.
+func (db *DB) SetFactArchived(id int64, archived bool) error {
+	_, err := db.conn.Exec(`UPDATE facts SET is_archived = ?, updated_at = datetime('now') WHERE id = ?`,
+		boolToInt(archived), id)
 	return err
 }
 
@@ -79,7 +160,9 @@ func (db *DB) HardDeleteFact(id int64) error {
 	return err
 }
 
-func (db *DB) SearchFacts(query string, topicID *int64) ([]models.Fact, error) {
+// SearchFacts returns up to 200 facts containing query, ignoring case, newest
+// first, from one topic or all of them. Archived facts are left out unless includeArchived is set.
+func (db *DB) SearchFacts(query string, topicID *int64, includeArchived bool) ([]models.Fact, error) {
 	var rows *sql.Rows
 	var err error
 
@@ -88,17 +171,19 @@ func (db *DB) SearchFacts(query string, topicID *int64) ([]models.Fact, error) {
 	if topicID != nil {
 		rows, err = db.conn.Query(`
 			SELECT f.id, f.topic_id, f.content, f.trigrams, f.is_custom, f.is_archived,
-			       f.source, f.created_at, f.updated_at
+			       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+			       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
 			FROM facts f
-			WHERE f.is_archived = 0 AND f.topic_id = ? AND f.content LIKE ?
-			ORDER BY f.created_at DESC LIMIT 200`, *topicID, likeQuery)
+			WHERE (f.is_archived = 0 OR ?) AND f.topic_id = ? AND LOWER(f.content) LIKE LOWER(?)
+			ORDER BY f.created_at DESC LIMIT 200`, includeArchived, *topicID, likeQuery)
 	} else {
 		rows, err = db.conn.Query(`
 			SELECT f.id, f.topic_id, f.content, f.trigrams, f.is_custom, f.is_archived,
-			       f.source, f.created_at, f.updated_at
+			       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+			       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
 			FROM facts f
-			WHERE f.is_archived = 0 AND f.content LIKE ?
-			ORDER BY f.created_at DESC LIMIT 200`, likeQuery)
+			WHERE (f.is_archived = 0 OR ?) AND LOWER(f.content) LIKE LOWER(?)
+			ORDER BY f.created_at DESC LIMIT 200`, includeArchived, likeQuery)
 	}
 	if err != nil {
 		return nil, err
@@ -107,9 +192,36 @@ func (db *DB) SearchFacts(query string, topicID *int64) ([]models.Fact, error) {
 	return scanFacts(rows)
 }
 
+// LatestSeriesFacts returns up to limit of a series-mode topic's most recent
+// non-archived series facts, in sequence order (oldest first).
+func (db *DB) LatestSeriesFacts(topicID int64, limit int) ([]models.Fact, error) {
+	rows, err := db.conn.Query(`
+		SELECT * FROM (
+			SELECT f.id, f.topic_id, f.content, f.trigrams, f.is_custom, f.is_archived,
+			       f.source, f.ai_provider, f.ai_model, f.source_title, f.source_url,
+			       f.word_count, f.sequence_index, f.confidence, f.created_at, f.updated_at
+			FROM facts f
+			WHERE f.topic_id = ? AND f.is_archived = 0 AND f.sequence_index > 0
+			ORDER BY f.sequence_index DESC LIMIT ?
+		) AS latest ORDER BY sequence_index ASC`, topicID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanFacts(rows)
+}
+
+// NextSequenceIndex returns the sequence index for a topic's next series fact.
+// Archived facts are counted so their positions are never reused.
+func (db *DB) NextSequenceIndex(topicID int64) (int, error) {
+	var maxIndex int
+	err := db.conn.QueryRow(`SELECT COALESCE(MAX(sequence_index), 0) FROM facts WHERE topic_id = ?`, topicID).Scan(&maxIndex)
+	return maxIndex + 1, err
+}
+
 func (db *DB) GetFactTrigramsForTopic(topicID int64) ([]StoredTrigrams, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, trigrams FROM facts
+		SELECT id, trigrams, embedding FROM facts
 		WHERE topic_id = ? AND is_archived = 0`, topicID)
 	if err != nil {
 		return nil, err
@@ -119,7 +231,7 @@ func (db *DB) GetFactTrigramsForTopic(topicID int64) ([]StoredTrigrams, error) {
 	var result []StoredTrigrams
 	for rows.Next() {
 		var st StoredTrigrams
-		if err := rows.Scan(&st.ID, &st.Trigrams); err != nil {
+		if err := rows.Scan(&st.ID, &st.Trigrams, &st.Embedding); err != nil {
 			return nil, err
 		}
 		result = append(result, st)
@@ -127,12 +239,171 @@ func (db *DB) GetFactTrigramsForTopic(topicID int64) ([]StoredTrigrams, error) {
 	return result, rows.Err()
 }
 
+// GetAllFactTrigrams returns the trigrams and embeddings of the unarchived
+// facts of every active topic, for checking new facts against all topics at
+// once. Only the columns the check needs are read, since this covers the whole
+// facts table.
+func (db *DB) GetAllFactTrigrams() ([]StoredTrigrams, error) {
+	rows, err := db.conn.Query(`
+		SELECT f.id, f.trigrams, f.embedding FROM facts f
+		JOIN topics t ON t.id = f.topic_id
+		WHERE t.is_active = 1 AND f.is_archived = 0`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var result []StoredTrigrams
+	for rows.Next() {
+		var st StoredTrigrams
+		if err := rows.Scan(&st.ID, &st.Trigrams, &st.Embedding); err != nil {
+			return nil, err
+		}
+		result = append(result, st)
+	}
+	return result, rows.Err()
+}
+
+// factContent is the id and text of a fact whose trigrams or embedding are being rebuilt.
+type factContent struct {
+	id      int64
+	content string
+}
+
+// RebuildFactTrigrams recomputes the stored trigrams of every fact, archived ones
+// included, using compute. Rows are updated in transactions of batchSize, and
+// progress is called after each batch with the facts done so far and the total.
+// Returns the number of facts updated.
+func (db *DB) RebuildFactTrigrams(compute func(content string) string, batchSize int, progress func(done, total int)) (int, error) {
+	rows, err := db.conn.Query(`SELECT id, content FROM facts ORDER BY id`)
+	if err != nil {
+		return 0, err
+	}
+	var facts []factContent
+	for rows.Next() {
+		var f factContent
+		if err := rows.Scan(&f.id, &f.content); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		facts = append(facts, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if batchSize <= 0 {
+		batchSize = len(facts)
+	}
+	done := 0
+	for done < len(facts) {
+		batch := facts[done:min(done+batchSize, len(facts))]
+		if err := db.updateTrigramBatch(batch, compute); err != nil {
+			return done, err
+		}
+		done += len(batch)
+		if progress != nil {
+			progress(done, len(facts))
+		}
+	}
+	return done, nil
+}
+
+// updateTrigramBatch writes the recomputed trigrams for one batch of facts in a
+// single transaction.
+func (db *DB) updateTrigramBatch(batch []factContent, compute func(string) string) error {
+	tx, err := db.conn.Begin()
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	stmt, err := tx.Prepare(`UPDATE facts SET trigrams = ? WHERE id = ?`)
+	if err != nil {
+		return err
+	}
+	defer stmt.Close()
+
+	for _, f := range batch {
+		if _, err := stmt.Exec(compute(f.content), f.id); err != nil {
+			return err
+		}
+	}
+	return tx.Commit()
+}
+
+// BackfillFactEmbeddings stores an embedding for every unarchived fact that has
+// none, such as facts saved before similarity_mode was "embedding" and custom
+// facts whose embedding request failed. embed returns a fact's JSON embedding;
+// the first error stops the backfill. progress is called after each fact with
+// the facts done so far and the total. Returns the number of facts updated.
+func (db *DB) BackfillFactEmbeddings(embed func(content string) (string, error), progress func(done, total int)) (int, error) {
+	rows, err := db.conn.Query(`SELECT id, content FROM facts WHERE embedding = '' AND is_archived = 0 ORDER BY id`)
+	if err != nil {
+		return 0, err
+	}
+	var facts []factContent
+	for rows.Next() {
+		var f factContent
+		if err := rows.Scan(&f.id, &f.content); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		facts = append(facts, f)
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	for i, f := range facts {
+		embedding, err := embed(f.content)
+		if err != nil {
+			return i, err
+		}
+		// Matching the content skips a fact edited while its embedding was requested
+		if _, err := db.conn.Exec(`UPDATE facts SET embedding = ? WHERE id = ? AND content = ?`, embedding, f.id, f.content); err != nil {
+			return i, err
+		}
+		if progress != nil {
+			progress(i+1, len(facts))
+		}
+	}
+	return len(facts), nil
+}
+
 func (db *DB) CountFactsByTopic(topicID int64) (int, error) {
 	var count int
 	err := db.conn.QueryRow(`SELECT COUNT(*) FROM facts WHERE topic_id = ? AND is_archived = 0`, topicID).Scan(&count)
 	return count, err
 }
 
+// CountAllFactsByTopic counts a topic's facts including archived ones.
+func (db *DB) CountAllFactsByTopic(topicID int64) (int, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM facts WHERE topic_id = ?`, topicID).Scan(&count)
+	return count, err
+}
+
+// ArchiveFactsOverLimit archives the oldest AI-generated facts of a topic so
+// that at most limit remain, and returns how many were archived. Unless
+// includeCustom is set, custom facts are never archived and don't count toward
+// the limit; with it, they are archived by age like any other fact.
+func (db *DB) ArchiveFactsOverLimit(topicID int64, limit int, includeCustom bool) (int64, error) {
+	custom := boolToInt(includeCustom)
+	result, err := db.conn.Exec(`
+		UPDATE facts SET is_archived = 1, updated_at = datetime('now')
+		WHERE topic_id = ? AND (is_custom = 0 OR ? = 1) AND is_archived = 0 AND id NOT IN (
+			SELECT id FROM facts WHERE topic_id = ? AND (is_custom = 0 OR ? = 1) AND is_archived = 0
+			ORDER BY created_at DESC, id DESC LIMIT ?
+		)`, topicID, custom, topicID, custom, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func (db *DB) FactCounts() (total, custom, ai int, err error) {
 	err = db.conn.QueryRow(`SELECT COUNT(*) FROM facts WHERE is_archived = 0`).Scan(&total)
 	if err != nil {
@@ -153,7 +424,8 @@ func scanFacts(rows *sql.Rows) ([]models.Fact, error) {
 		var createdAt, updatedAt string
 		if err := rows.Scan(
 			&f.ID, &f.TopicID, &f.Content, &f.Trigrams, &f.IsCustom, &f.IsArchived,
-			&f.Source, &f.AIProvider, &f.AIModel, &createdAt, &updatedAt,
+			&f.Source, &f.AIProvider, &f.AIModel, &f.SourceTitle, &f.SourceURL,
+			&f.WordCount, &f.SequenceIndex, &f.Confidence, &createdAt, &updatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan fact: %w", err)
 		}
@@ -0,0 +1,106 @@
+package database
+
+import (
+	"database/sql"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// overdueGrace is how far past its interval an active topic may go before it is
+// flagged as overdue. It allows for scheduler tick latency and slow local models.
+const overdueGrace = 30 * time.Minute
+
+// TopicHealthReport returns the status of every fact and news topic: last refresh,
+// the latest refresh_log outcome, item and source counts, and whether it is overdue.
+func (db *DB) TopicHealthReport() ([]models.TopicHealth, error) {
+	factRows, err := db.conn.Query(`
+		SELECT t.id, t.name, t.is_active, t.refresh_interval_minutes, t.last_refreshed_at, t.created_at,
+		       t.snoozed_until, t.is_paused,
+		       (SELECT COUNT(*) FROM facts f WHERE f.topic_id = t.id AND f.is_archived = 0),
+		       0,
+		       COALESCE(l.status, ''), COALESCE(l.error_type, ''), COALESCE(l.error_message, ''), l.created_at
+		FROM topics t
+		LEFT JOIN refresh_log l ON l.id = (
+			SELECT id FROM refresh_log
+			WHERE topic_type = 'facts' AND topic_id = t.id
+			ORDER BY created_at DESC, id DESC LIMIT 1)
+		ORDER BY t.display_order ASC, t.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	report, err := scanTopicHealth(factRows, "facts")
+	factRows.Close()
+	if err != nil {
+		return nil, err
+	}
+
+	newsRows, err := db.conn.Query(`
+		SELECT t.id, t.name, t.is_active, t.refresh_interval_minutes, t.last_refreshed_at, t.created_at,
+		       t.snoozed_until, 0,
+		       (SELECT COUNT(*) FROM stories s WHERE s.news_topic_id = t.id),
+		       (SELECT COUNT(*) FROM news_sources ns WHERE ns.news_topic_id = t.id AND ns.is_active = 1),
+		       COALESCE(l.status, ''), COALESCE(l.error_type, ''), COALESCE(l.error_message, ''), l.created_at
+		FROM news_topics t
+		LEFT JOIN refresh_log l ON l.id = (
+			SELECT id FROM refresh_log
+			WHERE topic_type = 'news' AND topic_id = t.id
+			ORDER BY created_at DESC, id DESC LIMIT 1)
+		ORDER BY t.display_order ASC, t.id ASC`)
+	if err != nil {
+		return nil, err
+	}
+	defer newsRows.Close()
+	news, err := scanTopicHealth(newsRows, "news")
+	if err != nil {
+		return nil, err
+	}
+
+	return append(report, news...), nil
+}
+
+func scanTopicHealth(rows *sql.Rows, topicType string) ([]models.TopicHealth, error) {
+	now := time.Now().UTC()
+
+	var report []models.TopicHealth
+	for rows.Next() {
+		h := models.TopicHealth{TopicType: topicType}
+		var isActive int
+		var lastRefreshed, lastAttempt, snoozedUntil sql.NullString
+		var createdAt string
+		if err := rows.Scan(&h.TopicID, &h.Name, &isActive, &h.RefreshIntervalMinutes,
+			&lastRefreshed, &createdAt, &snoozedUntil, &h.IsPaused, &h.ItemCount, &h.ActiveSources,
+			&h.LastStatus, &h.LastErrorType, &h.LastErrorMessage, &lastAttempt); err != nil {
+			return nil, err
+		}
+		h.IsActive = isActive == 1
+
+		// Never-refreshed topics are due immediately, so measure them from creation
+		// time with no interval.
+		since, _ := parseTime(createdAt)
+		interval := time.Duration(0)
+		if lastRefreshed.Valid {
+			parsed, _ := parseTime(lastRefreshed.String)
+			h.LastRefreshedAt = &parsed
+			since = parsed
+			interval = time.Duration(h.RefreshIntervalMinutes) * time.Minute
+		}
+		if lastAttempt.Valid {
+			parsed, _ := parseTime(lastAttempt.String)
+			h.LastAttemptAt = &parsed
+		}
+
+		// Snoozed and paused topics are skipped by the scheduler on purpose, so they are never overdue
+		snoozed := false
+		if snoozedUntil.Valid {
+			parsed, _ := parseTime(snoozedUntil.String)
+			h.SnoozedUntil = &parsed
+			snoozed = parsed.After(now)
+		}
+
+		h.Overdue = h.IsActive && !snoozed && !h.IsPaused && now.Sub(since) > interval+overdueGrace
+
+		report = append(report, h)
+	}
+	return report, rows.Err()
+}
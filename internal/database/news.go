@@ -3,6 +3,8 @@ package database
 import (
 	"database/sql"
 	"fmt"
+	"strings"
+	"time"
 
 	"github.com/thinkscotty/kibble/internal/models"
 )
@@ -12,8 +14,9 @@ import (
 func (db *DB) ListNewsTopics() ([]models.NewsTopic, error) {
 	rows, err := db.conn.Query(`
 		SELECT id, name, description, display_order, is_active, stories_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, max_sources_per_refresh, temperature, max_tokens,
+		       ai_provider, discovery_provider, content_focus, voice, include_quotes, show_source_link, is_niche, last_refreshed_at, snoozed_until,
+		       needs_attention, discovery_paused_until, created_at, updated_at
 		FROM news_topics ORDER BY display_order ASC, id ASC`)
 	if err != nil {
 		return nil, err
@@ -25,8 +28,9 @@ func (db *DB) ListNewsTopics() ([]models.NewsTopic, error) {
 func (db *DB) ListActiveNewsTopics() ([]models.NewsTopic, error) {
 	rows, err := db.conn.Query(`
 		SELECT id, name, description, display_order, is_active, stories_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, max_sources_per_refresh, temperature, max_tokens,
+		       ai_provider, discovery_provider, content_focus, voice, include_quotes, show_source_link, is_niche, last_refreshed_at, snoozed_until,
+		       needs_attention, discovery_paused_until, created_at, updated_at
 		FROM news_topics WHERE is_active = 1 ORDER BY display_order ASC, id ASC`)
 	if err != nil {
 		return nil, err
@@ -37,18 +41,20 @@ func (db *DB) ListActiveNewsTopics() ([]models.NewsTopic, error) {
 
 func (db *DB) GetNewsTopic(id int64) (models.NewsTopic, error) {
 	var t models.NewsTopic
-	var lastRefreshed sql.NullString
+	var lastRefreshed, snoozedUntil, pausedUntil sql.NullString
 	var createdAt, updatedAt string
 
 	err := db.conn.QueryRow(`
 		SELECT id, name, description, display_order, is_active, stories_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, max_sources_per_refresh, temperature, max_tokens,
+		       ai_provider, discovery_provider, content_focus, voice, include_quotes, show_source_link, is_niche, last_refreshed_at, snoozed_until,
+		       needs_attention, discovery_paused_until, created_at, updated_at
 		FROM news_topics WHERE id = ?`, id).Scan(
 		&t.ID, &t.Name, &t.Description, &t.DisplayOrder, &t.IsActive,
-		&t.StoriesPerRefresh, &t.RefreshIntervalMinutes,
-		&t.SummaryMinWords, &t.SummaryMaxWords,
-		&t.AIProvider, &t.IsNiche, &lastRefreshed,
+		&t.StoriesPerRefresh, &t.RefreshIntervalMinutes, &t.ScheduleOffsetMinutes,
+		&t.SummaryMinWords, &t.SummaryMaxWords, &t.MaxSourcesPerRefresh, &t.Temperature, &t.MaxTokens,
+		&t.AIProvider, &t.DiscoveryProvider, &t.ContentFocus, &t.Voice, &t.IncludeQuotes, &t.ShowSourceLink, &t.IsNiche, &lastRefreshed, &snoozedUntil,
+		&t.NeedsAttention, &pausedUntil,
 		&createdAt, &updatedAt)
 	if err != nil {
 		return t, err
@@ -60,6 +66,14 @@ func (db *DB) GetNewsTopic(id int64) (models.NewsTopic, error) {
 		parsed, _ := parseTime(lastRefreshed.String)
 		t.LastRefreshedAt = &parsed
 	}
+	if snoozedUntil.Valid {
+		parsed, _ := parseTime(snoozedUntil.String)
+		t.SnoozedUntil = &parsed
+	}
+	if pausedUntil.Valid {
+		parsed, _ := parseTime(pausedUntil.String)
+		t.DiscoveryPausedUntil = &parsed
+	}
 	return t, nil
 }
 
@@ -71,17 +85,14 @@ func (db *DB) CreateNewsTopic(t *models.NewsTopic) error {
 		nextOrder = int(maxOrder.Int64) + 1
 	}
 
-	result, err := db.conn.Exec(`
-		INSERT INTO news_topics (name, description, display_order, is_active, stories_per_refresh, refresh_interval_minutes, summary_min_words, summary_max_words, ai_provider, is_niche)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	t.ScheduleOffsetMinutes = newScheduleOffset()
+	id, err := db.conn.insertID(`
+		INSERT INTO news_topics (name, description, display_order, is_active, stories_per_refresh, refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, max_sources_per_refresh, temperature, max_tokens, ai_provider, discovery_provider, content_focus, voice, include_quotes, show_source_link, is_niche)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		t.Name, t.Description, nextOrder, boolToInt(t.IsActive),
-		t.StoriesPerRefresh, t.RefreshIntervalMinutes,
-		t.SummaryMinWords, t.SummaryMaxWords,
-		t.AIProvider, boolToInt(t.IsNiche))
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+		t.StoriesPerRefresh, t.RefreshIntervalMinutes, t.ScheduleOffsetMinutes,
+		t.SummaryMinWords, t.SummaryMaxWords, t.MaxSourcesPerRefresh, t.Temperature, t.MaxTokens,
+		t.AIProvider, t.DiscoveryProvider, t.ContentFocus, t.Voice, boolToInt(t.IncludeQuotes), boolToInt(t.ShowSourceLink), boolToInt(t.IsNiche))
 	if err != nil {
 		return err
 	}
@@ -90,18 +101,27 @@ func (db *DB) CreateNewsTopic(t *models.NewsTopic) error {
 	return nil
 }
 
+// NewsTopicNameExists reports whether another news topic already uses name,
+// compared case-insensitively. excludeID skips the topic being edited (0 for none).
+func (db *DB) NewsTopicNameExists(name string, excludeID int64) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM news_topics WHERE LOWER(name) = LOWER(?) AND id != ?`,
+		name, excludeID).Scan(&count)
+	return count > 0, err
+}
+
 func (db *DB) UpdateNewsTopic(t *models.NewsTopic) error {
 	_, err := db.conn.Exec(`
 		UPDATE news_topics SET name = ?, description = ?, is_active = ?,
-		       stories_per_refresh = ?, refresh_interval_minutes = ?,
-		       summary_min_words = ?, summary_max_words = ?,
-		       ai_provider = ?, is_niche = ?,
+		       stories_per_refresh = ?, refresh_interval_minutes = ?, schedule_offset_minutes = ?,
+		       summary_min_words = ?, summary_max_words = ?, max_sources_per_refresh = ?, temperature = ?, max_tokens = ?,
+		       ai_provider = ?, discovery_provider = ?, content_focus = ?, voice = ?, include_quotes = ?, show_source_link = ?, is_niche = ?,
 		       updated_at = datetime('now')
 		WHERE id = ?`,
 		t.Name, t.Description, boolToInt(t.IsActive),
-		t.StoriesPerRefresh, t.RefreshIntervalMinutes,
-		t.SummaryMinWords, t.SummaryMaxWords,
-		t.AIProvider, boolToInt(t.IsNiche), t.ID)
+		t.StoriesPerRefresh, t.RefreshIntervalMinutes, t.ScheduleOffsetMinutes,
+		t.SummaryMinWords, t.SummaryMaxWords, t.MaxSourcesPerRefresh, t.Temperature, t.MaxTokens,
+		t.AIProvider, t.DiscoveryProvider, t.ContentFocus, t.Voice, boolToInt(t.IncludeQuotes), boolToInt(t.ShowSourceLink), boolToInt(t.IsNiche), t.ID)
 	return err
 }
 
@@ -116,6 +136,29 @@ func (db *DB) ToggleNewsTopicActive(id int64, active bool) error {
 	return err
 }
 
+// SnoozeNewsTopic pauses scheduled refreshes for a news topic until the given time.
+// A nil until clears the snooze.
+func (db *DB) SnoozeNewsTopic(id int64, until *time.Time) error {
+	_, err := db.conn.Exec(`UPDATE news_topics SET snoozed_until = ?, updated_at = datetime('now') WHERE id = ?`,
+		formatSnooze(until), id)
+	return err
+}
+
+// SetNewsTopicNeedsAttention flags a news topic left without working sources and
+// pauses automatic source discovery for it until pausedUntil (nil for no pause).
+func (db *DB) SetNewsTopicNeedsAttention(id int64, pausedUntil *time.Time) error {
+	_, err := db.conn.Exec(`UPDATE news_topics SET needs_attention = 1, discovery_paused_until = ?, updated_at = datetime('now') WHERE id = ?`,
+		formatSnooze(pausedUntil), id)
+	return err
+}
+
+// ClearNewsTopicAttention removes a news topic's needs-attention flag and any
+// pause on automatic source discovery.
+func (db *DB) ClearNewsTopicAttention(id int64) error {
+	_, err := db.conn.Exec(`UPDATE news_topics SET needs_attention = 0, discovery_paused_until = NULL, updated_at = datetime('now') WHERE id = ?`, id)
+	return err
+}
+
 func (db *DB) ReorderNewsTopics(ids []int64) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
@@ -145,12 +188,13 @@ func (db *DB) UpdateNewsTopicRefreshTime(id int64) error {
 func (db *DB) NewsTopicsDueForRefresh() ([]models.NewsTopic, error) {
 	rows, err := db.conn.Query(`
 		SELECT id, name, description, display_order, is_active, stories_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, max_sources_per_refresh, temperature, max_tokens,
+		       ai_provider, discovery_provider, content_focus, voice, include_quotes, show_source_link, is_niche, last_refreshed_at, snoozed_until,
+		       needs_attention, discovery_paused_until, created_at, updated_at
 		FROM news_topics
 		WHERE is_active = 1
-		  AND (last_refreshed_at IS NULL
-		       OR datetime('now') > datetime(last_refreshed_at, '+' || refresh_interval_minutes || ' minutes'))
+		  AND (snoozed_until IS NULL OR datetime('now') >= snoozed_until)
+		  AND ` + db.refreshDue() + `
 		ORDER BY last_refreshed_at ASC NULLS FIRST`)
 	if err != nil {
 		return nil, err
@@ -163,14 +207,15 @@ func scanNewsTopics(rows *sql.Rows) ([]models.NewsTopic, error) {
 	var topics []models.NewsTopic
 	for rows.Next() {
 		var t models.NewsTopic
-		var lastRefreshed sql.NullString
+		var lastRefreshed, snoozedUntil, pausedUntil sql.NullString
 		var createdAt, updatedAt string
 
 		if err := rows.Scan(
 			&t.ID, &t.Name, &t.Description, &t.DisplayOrder, &t.IsActive,
-			&t.StoriesPerRefresh, &t.RefreshIntervalMinutes,
-			&t.SummaryMinWords, &t.SummaryMaxWords,
-			&t.AIProvider, &t.IsNiche, &lastRefreshed,
+			&t.StoriesPerRefresh, &t.RefreshIntervalMinutes, &t.ScheduleOffsetMinutes,
+			&t.SummaryMinWords, &t.SummaryMaxWords, &t.MaxSourcesPerRefresh, &t.Temperature, &t.MaxTokens,
+			&t.AIProvider, &t.DiscoveryProvider, &t.ContentFocus, &t.Voice, &t.IncludeQuotes, &t.ShowSourceLink, &t.IsNiche, &lastRefreshed, &snoozedUntil,
+			&t.NeedsAttention, &pausedUntil,
 			&createdAt, &updatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan news topic: %w", err)
@@ -182,6 +227,14 @@ func scanNewsTopics(rows *sql.Rows) ([]models.NewsTopic, error) {
 			parsed, _ := parseTime(lastRefreshed.String)
 			t.LastRefreshedAt = &parsed
 		}
+		if snoozedUntil.Valid {
+			parsed, _ := parseTime(snoozedUntil.String)
+			t.SnoozedUntil = &parsed
+		}
+		if pausedUntil.Valid {
+			parsed, _ := parseTime(pausedUntil.String)
+			t.DiscoveryPausedUntil = &parsed
+		}
 		topics = append(topics, t)
 	}
 	return topics, rows.Err()
@@ -191,7 +244,7 @@ func scanNewsTopics(rows *sql.Rows) ([]models.NewsTopic, error) {
 
 func (db *DB) GetSourcesForNewsTopic(newsTopicID int64) ([]models.NewsSource, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, created_at
+		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, force_feed, timeout_seconds, max_retries, content_hash, etag, last_modified, created_at
 		FROM news_sources WHERE news_topic_id = ? ORDER BY is_manual DESC, id ASC`, newsTopicID)
 	if err != nil {
 		return nil, err
@@ -202,7 +255,7 @@ func (db *DB) GetSourcesForNewsTopic(newsTopicID int64) ([]models.NewsSource, er
 
 func (db *DB) GetActiveSourcesForNewsTopic(newsTopicID int64) ([]models.NewsSource, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, created_at
+		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, force_feed, timeout_seconds, max_retries, content_hash, etag, last_modified, created_at
 		FROM news_sources WHERE news_topic_id = ? AND is_active = 1 ORDER BY id ASC`, newsTopicID)
 	if err != nil {
 		return nil, err
@@ -211,14 +264,70 @@ func (db *DB) GetActiveSourcesForNewsTopic(newsTopicID int64) ([]models.NewsSour
 	return scanNewsSources(rows)
 }
 
+// NextSourcesForRefresh returns up to limit active sources for a news topic,
+// least recently scraped first (never-scraped sources lead), breaking ties by
+// fewest failures. Stamping the picked sources with MarkNewsSourcesScraped makes
+// successive refreshes rotate through every source.
+func (db *DB) NextSourcesForRefresh(newsTopicID int64, limit int) ([]models.NewsSource, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, force_feed, timeout_seconds, max_retries, content_hash, etag, last_modified, created_at
+		FROM news_sources WHERE news_topic_id = ? AND is_active = 1
+		ORDER BY last_scraped_at ASC NULLS FIRST, failure_count ASC, id ASC
+		LIMIT ?`, newsTopicID, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanNewsSources(rows)
+}
+
+// MarkNewsSourcesScraped records that the given sources were scraped now.
+func (db *DB) MarkNewsSourcesScraped(ids []int64) error {
+	for _, id := range ids {
+		if _, err := db.conn.Exec(`UPDATE news_sources SET last_scraped_at = datetime('now') WHERE id = ?`, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetNewsSourceContentHashes records the hash of each source's latest scraped
+// content, keyed by source ID.
+func (db *DB) SetNewsSourceContentHashes(hashes map[int64]string) error {
+	for id, hash := range hashes {
+		if _, err := db.conn.Exec(`UPDATE news_sources SET content_hash = ? WHERE id = ?`, hash, id); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// SetNewsSourceValidators records the ETag and Last-Modified headers a source's
+// feed returned, to send on its next fetch. Empty values clear them.
+func (db *DB) SetNewsSourceValidators(id int64, etag, lastModified string) error {
+	_, err := db.conn.Exec(`UPDATE news_sources SET etag = ?, last_modified = ? WHERE id = ?`, etag, lastModified, id)
+	return err
+}
+
+func (db *DB) GetNewsSource(id int64) (models.NewsSource, error) {
+	var s models.NewsSource
+	var createdAt string
+	err := db.conn.QueryRow(`
+		SELECT id, news_topic_id, url, name, is_manual, is_active, failure_count, last_error, force_feed, timeout_seconds, max_retries, content_hash, etag, last_modified, created_at
+		FROM news_sources WHERE id = ?`, id).Scan(
+		&s.ID, &s.NewsTopicID, &s.URL, &s.Name, &s.IsManual,
+		&s.IsActive, &s.FailureCount, &s.LastError, &s.ForceFeed, &s.TimeoutSeconds, &s.MaxRetries, &s.ContentHash, &s.ETag, &s.LastModified, &createdAt)
+	if err != nil {
+		return s, err
+	}
+	s.CreatedAt, _ = parseTime(createdAt)
+	return s, nil
+}
+
 func (db *DB) AddNewsSource(newsTopicID int64, url, name string, isManual bool) (int64, error) {
-	result, err := db.conn.Exec(`
+	return db.conn.insertID(`
 		INSERT INTO news_sources (news_topic_id, url, name, is_manual) VALUES (?, ?, ?, ?)`,
 		newsTopicID, url, name, boolToInt(isManual))
-	if err != nil {
-		return 0, err
-	}
-	return result.LastInsertId()
 }
 
 func (db *DB) DeleteNewsSource(id int64) error {
@@ -228,8 +337,102 @@ func (db *DB) DeleteNewsSource(id int64) error {
 
 func (db *DB) UpdateNewsSourceStatus(id int64, isActive bool, failureCount int, lastError string) error {
 	_, err := db.conn.Exec(`
-		UPDATE news_sources SET is_active = ?, failure_count = ?, last_error = ? WHERE id = ?`,
-		boolToInt(isActive), failureCount, lastError, id)
+		UPDATE news_sources SET is_active = ?, failure_count = ?, last_error = ?,
+		       last_failed_at = CASE WHEN ? != '' THEN datetime('now') ELSE last_failed_at END
+		WHERE id = ?`,
+		boolToInt(isActive), failureCount, lastError, lastError, id)
+	return err
+}
+
+// RecentlyFailedSources returns active sources whose most recent scrape failed,
+// across all news topics, most recently failed first.
+func (db *DB) RecentlyFailedSources(limit int) ([]models.FailedSource, error) {
+	rows, err := db.conn.Query(`
+		SELECT s.id, s.news_topic_id, s.url, s.name, s.is_manual, s.is_active,
+		       s.failure_count, s.last_error, s.force_feed, s.created_at,
+		       t.name, s.last_failed_at
+		FROM news_sources s
+		JOIN news_topics t ON t.id = s.news_topic_id
+		WHERE s.is_active = 1 AND s.last_error != ''
+		ORDER BY s.last_failed_at DESC NULLS LAST, s.failure_count DESC
+		LIMIT ?`, limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var failed []models.FailedSource
+	for rows.Next() {
+		var f models.FailedSource
+		var createdAt string
+		var lastFailed sql.NullString
+		if err := rows.Scan(
+			&f.Source.ID, &f.Source.NewsTopicID, &f.Source.URL, &f.Source.Name, &f.Source.IsManual,
+			&f.Source.IsActive, &f.Source.FailureCount, &f.Source.LastError, &f.Source.ForceFeed, &createdAt,
+			&f.TopicName, &lastFailed,
+		); err != nil {
+			return nil, fmt.Errorf("scan failed source: %w", err)
+		}
+		f.Source.CreatedAt, _ = parseTime(createdAt)
+		if lastFailed.Valid {
+			parsed, _ := parseTime(lastFailed.String)
+			f.LastFailedAt = &parsed
+		}
+		failed = append(failed, f)
+	}
+	return failed, rows.Err()
+}
+
+// SharedSources returns the URLs that active sources of more than one news topic
+// point at, compared ignoring case and trailing slashes, most shared first.
+func (db *DB) SharedSources() ([]models.SharedSource, error) {
+	names := `GROUP_CONCAT(t.name, char(31))`
+	if db.driver == DriverPostgres {
+		names = `string_agg(t.name, chr(31))`
+	}
+	rows, err := db.conn.Query(`
+		SELECT MIN(s.url), ` + names + `
+		FROM news_sources s
+		JOIN news_topics t ON t.id = s.news_topic_id
+		WHERE s.is_active = 1
+		GROUP BY lower(rtrim(trim(s.url), '/'))
+		HAVING COUNT(DISTINCT s.news_topic_id) > 1
+		ORDER BY COUNT(DISTINCT s.news_topic_id) DESC, MIN(s.url)`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var shared []models.SharedSource
+	for rows.Next() {
+		var src models.SharedSource
+		var names string
+		if err := rows.Scan(&src.URL, &names); err != nil {
+			return nil, fmt.Errorf("scan shared source: %w", err)
+		}
+		seen := make(map[string]bool)
+		for _, name := range strings.Split(names, "\x1f") {
+			if !seen[name] {
+				seen[name] = true
+				src.Topics = append(src.Topics, name)
+			}
+		}
+		shared = append(shared, src)
+	}
+	return shared, rows.Err()
+}
+
+// SetNewsSourceForceFeed sets whether a source is always parsed as an RSS/Atom feed.
+func (db *DB) SetNewsSourceForceFeed(id int64, force bool) error {
+	_, err := db.conn.Exec(`UPDATE news_sources SET force_feed = ? WHERE id = ?`, boolToInt(force), id)
+	return err
+}
+
+// SetNewsSourceFetchOptions sets a source's request timeout in seconds and how
+// many times a transient fetch failure is retried. 0 uses the defaults.
+func (db *DB) SetNewsSourceFetchOptions(id int64, timeoutSeconds, maxRetries int) error {
+	_, err := db.conn.Exec(`UPDATE news_sources SET timeout_seconds = ?, max_retries = ? WHERE id = ?`,
+		timeoutSeconds, maxRetries, id)
 	return err
 }
 
@@ -246,7 +449,7 @@ func scanNewsSources(rows *sql.Rows) ([]models.NewsSource, error) {
 
 		if err := rows.Scan(
 			&s.ID, &s.NewsTopicID, &s.URL, &s.Name, &s.IsManual,
-			&s.IsActive, &s.FailureCount, &s.LastError, &createdAt,
+			&s.IsActive, &s.FailureCount, &s.LastError, &s.ForceFeed, &s.TimeoutSeconds, &s.MaxRetries, &s.ContentHash, &s.ETag, &s.LastModified, &createdAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan news source: %w", err)
 		}
@@ -281,11 +484,41 @@ func (db *DB) GetRecentStoryTitles(newsTopicID int64, limit int) ([]string, erro
 	return titles, rows.Err()
 }
 
-func (db *DB) ListStoriesByNewsTopic(newsTopicID int64, limit int) ([]models.Story, error) {
-	rows, err := db.conn.Query(`
-		SELECT id, news_topic_id, title, summary, source_url, source_title, ai_provider, ai_model, published_at, created_at
+// Story orders accepted by ListStoriesByNewsTopic, as stored in the story_order setting.
+const (
+	StoryOrderCreated    = "created"    // newest stored first
+	StoryOrderPublished  = "published"  // newest article date first
+	StoryOrderImportance = "importance" // highest rated first
+)
+
+// storyOrderBy maps a story order to its ORDER BY clause, defaulting to
+// StoryOrderCreated. Ties fall back to the newest stored story.
+func storyOrderBy(order string) string {
+	switch order {
+	case StoryOrderPublished:
+		return "published_at DESC, created_at DESC, id DESC"
+	case StoryOrderImportance:
+		return "importance DESC, created_at DESC, id DESC"
+	default:
+		return "created_at DESC, id DESC"
+	}
+}
+
+// ListStoriesByNewsTopic returns up to limit stories for a news topic in the
+// given story order (one of the StoryOrder constants), or all of them if limit
+// is 0.
+func (db *DB) ListStoriesByNewsTopic(newsTopicID int64, limit int, order string) ([]models.Story, error) {
+	query := `
+		SELECT id, news_topic_id, title, summary, quote, source_url, source_title, author, ai_provider, ai_model,
+		       word_count, importance, published_at, created_at
 		FROM stories WHERE news_topic_id = ?
-		ORDER BY created_at DESC LIMIT ?`, newsTopicID, limit)
+		ORDER BY ` + storyOrderBy(order)
+	args := []any{newsTopicID}
+	if limit > 0 {
+		query += ` LIMIT ?`
+		args = append(args, limit)
+	}
+	rows, err := db.conn.Query(query, args...)
 	if err != nil {
 		return nil, err
 	}
@@ -293,15 +526,18 @@ func (db *DB) ListStoriesByNewsTopic(newsTopicID int64, limit int) ([]models.Sto
 	return scanStories(rows)
 }
 
+// CreateStory stores a story. A zero PublishedAt means the article date is
+// unknown, and the time it was stored is used instead.
 func (db *DB) CreateStory(s *models.Story) error {
-	result, err := db.conn.Exec(`
-		INSERT INTO stories (news_topic_id, title, summary, source_url, source_title, ai_provider, ai_model, published_at)
-		VALUES (?, ?, ?, ?, ?, ?, ?, datetime('now'))`,
-		s.NewsTopicID, s.Title, s.Summary, s.SourceURL, s.SourceTitle, s.AIProvider, s.AIModel)
-	if err != nil {
-		return err
+	s.WordCount = countWords(s.Summary)
+	var publishedAt any
+	if !s.PublishedAt.IsZero() {
+		publishedAt = s.PublishedAt.UTC().Format("2006-01-02 15:04:05")
 	}
-	id, err := result.LastInsertId()
+	id, err := db.conn.insertID(`
+		INSERT INTO stories (news_topic_id, title, summary, quote, source_url, source_title, author, ai_provider, ai_model, word_count, importance, published_at)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, COALESCE(?, datetime('now')))`,
+		s.NewsTopicID, s.Title, s.Summary, s.Quote, s.SourceURL, s.SourceTitle, s.Author, s.AIProvider, s.AIModel, s.WordCount, s.Importance, publishedAt)
 	if err != nil {
 		return err
 	}
@@ -317,6 +553,19 @@ func (db *DB) DeleteOldStories(newsTopicID int64, keepCount int) error {
 	return err
 }
 
+// PruneStoriesOverLimit deletes the oldest stories across all news topics so that
+// at most limit remain, and returns how many were deleted.
+func (db *DB) PruneStoriesOverLimit(limit int) (int64, error) {
+	result, err := db.conn.Exec(`
+		DELETE FROM stories WHERE id NOT IN (
+			SELECT id FROM stories ORDER BY created_at DESC, id DESC LIMIT ?
+		)`, limit)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
+
 func scanStories(rows *sql.Rows) ([]models.Story, error) {
 	var stories []models.Story
 	for rows.Next() {
@@ -324,9 +573,9 @@ func scanStories(rows *sql.Rows) ([]models.Story, error) {
 		var publishedAt, createdAt string
 
 		if err := rows.Scan(
-			&s.ID, &s.NewsTopicID, &s.Title, &s.Summary,
-			&s.SourceURL, &s.SourceTitle, &s.AIProvider, &s.AIModel,
-			&publishedAt, &createdAt,
+			&s.ID, &s.NewsTopicID, &s.Title, &s.Summary, &s.Quote,
+			&s.SourceURL, &s.SourceTitle, &s.Author, &s.AIProvider, &s.AIModel,
+			&s.WordCount, &s.Importance, &publishedAt, &createdAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan story: %w", err)
 		}
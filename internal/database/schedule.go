@@ -0,0 +1,55 @@
+package database
+
+import (
+	"math/rand"
+	"time"
+)
+
+// scheduleOffsetSpread is the range, in minutes, new topics' schedule offsets
+// are drawn from, spreading topics with the same interval across the hour.
+const scheduleOffsetSpread = 60
+
+// refreshDueCondition selects topics due for a scheduled refresh. A topic's
+// refreshes fall on a grid of slots every refresh_interval_minutes, shifted by
+// schedule_offset_minutes, so topics created together stay out of phase instead
+// of firing in the same tick. A topic is due once the current slot has started
+// since its last refresh and at least half an interval has passed, so a manual
+// refresh just before a slot doesn't trigger another straight away.
+const refreshDueCondition = `(last_refreshed_at IS NULL
+		       OR (datetime('now') > datetime(last_refreshed_at, '+' || (refresh_interval_minutes / 2) || ' minutes')
+		           AND last_refreshed_at < datetime((CAST(strftime('%s', 'now') AS INTEGER) / 60
+		               - (CAST(strftime('%s', 'now') AS INTEGER) / 60 - schedule_offset_minutes) % MAX(refresh_interval_minutes, 1)) * 60,
+		               'unixepoch')))`
+
+// pgRefreshDueCondition is refreshDueCondition in Postgres date arithmetic.
+const pgRefreshDueCondition = `(last_refreshed_at IS NULL
+		       OR (now() AT TIME ZONE 'UTC' > last_refreshed_at::timestamp + make_interval(mins => (refresh_interval_minutes / 2)::int)
+		           AND last_refreshed_at::timestamp < to_timestamp((floor(extract(epoch FROM now()))::bigint / 60
+		               - (floor(extract(epoch FROM now()))::bigint / 60 - schedule_offset_minutes) % GREATEST(refresh_interval_minutes, 1)) * 60)
+		               AT TIME ZONE 'UTC'))`
+
+// refreshDue returns refreshDueCondition for the database's backend.
+func (db *DB) refreshDue() string {
+	if db.driver == DriverPostgres {
+		return pgRefreshDueCondition
+	}
+	return refreshDueCondition
+}
+
+// newScheduleOffset returns a schedule offset for a new topic.
+func newScheduleOffset() int {
+	return rand.Intn(scheduleOffsetSpread)
+}
+
+// NextRefreshSlot returns when a topic refreshed at last is next due, matching
+// refreshDueCondition: the first slot of its interval/offset grid after last, or
+// half an interval after last if that is later.
+func NextRefreshSlot(last time.Time, intervalMinutes, offsetMinutes int) time.Time {
+	interval := int64(max(intervalMinutes, 1))
+	minute := last.Unix() / 60
+	next := time.Unix((minute-(minute-int64(offsetMinutes))%interval+interval)*60, 0)
+	if earliest := last.Add(time.Duration(interval/2) * time.Minute); earliest.After(next) {
+		return earliest
+	}
+	return next
+}
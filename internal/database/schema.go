@@ -0,0 +1,131 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+	"log/slog"
+	"strings"
+
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// applyMigrations runs each alterStatements entry that schema_migrations has no
+// record of and records it. A "duplicate column" error means the column was added
+// before migrations were tracked, so the statement is recorded as applied. Any
+// other error is logged and the migration left pending, to be retried next start.
+func (db *DB) applyMigrations() error {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return err
+	}
+
+	for i, stmt := range alterStatements {
+		version := i + 1
+		if _, ok := applied[version]; ok {
+			continue
+		}
+		if _, err := db.conn.execSchema(stmt); err != nil && !strings.Contains(err.Error(), "duplicate column") {
+			slog.Warn("Migration failed, will retry on next start", "version", version, "error", err)
+			continue
+		}
+		if _, err := db.conn.Exec(`INSERT INTO schema_migrations (version, statement) VALUES (?, ?)`, version, stmt); err != nil {
+			return fmt.Errorf("record migration %d: %w", version, err)
+		}
+	}
+	return nil
+}
+
+// appliedMigrations returns the applied_at time of each recorded migration, by version.
+func (db *DB) appliedMigrations() (map[int]string, error) {
+	rows, err := db.conn.Query(`SELECT version, applied_at FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := make(map[int]string)
+	for rows.Next() {
+		var version int
+		var appliedAt string
+		if err := rows.Scan(&version, &appliedAt); err != nil {
+			return nil, err
+		}
+		applied[version] = appliedAt
+	}
+	return applied, rows.Err()
+}
+
+// SchemaVersion returns the number of known migrations and the highest version
+// up to which every migration has been applied.
+func (db *DB) SchemaVersion() (current, latest int, err error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return 0, 0, err
+	}
+	for current < len(alterStatements) {
+		if _, ok := applied[current+1]; !ok {
+			break
+		}
+		current++
+	}
+	return current, len(alterStatements), nil
+}
+
+// ListMigrations returns every known migration in version order, with the time
+// it was applied, or a nil AppliedAt if it is still pending.
+func (db *DB) ListMigrations() ([]models.SchemaMigration, error) {
+	applied, err := db.appliedMigrations()
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := make([]models.SchemaMigration, len(alterStatements))
+	for i, stmt := range alterStatements {
+		m := models.SchemaMigration{Version: i + 1, Statement: stmt}
+		if at, ok := applied[m.Version]; ok {
+			if t, err := parseTime(at); err == nil {
+				m.AppliedAt = &t
+			}
+		}
+		migrations[i] = m
+	}
+	return migrations, nil
+}
+
+// ListSchemaObjects returns the live schema from sqlite_master: every table,
+// index, trigger, and view with the SQL that created it. On Postgres it lists
+// tables and indexes from the catalog, and only indexes carry their SQL.
+func (db *DB) ListSchemaObjects() ([]models.SchemaObject, error) {
+	query := `
+		SELECT type, name, tbl_name, sql FROM sqlite_master
+		WHERE name NOT LIKE 'sqlite_%'
+		ORDER BY CASE type WHEN 'table' THEN 0 WHEN 'index' THEN 1 ELSE 2 END, tbl_name, name`
+	if db.driver == DriverPostgres {
+		query = `
+		SELECT type, name, tbl_name, sql FROM (
+			SELECT 'table' AS type, tablename AS name, tablename AS tbl_name, NULL AS sql
+			FROM pg_tables WHERE schemaname = current_schema()
+			UNION ALL
+			SELECT 'index', indexname, tablename, indexdef
+			FROM pg_indexes WHERE schemaname = current_schema()
+		) o
+		ORDER BY CASE type WHEN 'table' THEN 0 ELSE 1 END, tbl_name, name`
+	}
+	rows, err := db.conn.Query(query)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var objects []models.SchemaObject
+	for rows.Next() {
+		var o models.SchemaObject
+		var sqlText sql.NullString
+		if err := rows.Scan(&o.Type, &o.Name, &o.Table, &sqlText); err != nil {
+			return nil, err
+		}
+		o.SQL = sqlText.String
+		objects = append(objects, o)
+	}
+	return objects, rows.Err()
+}
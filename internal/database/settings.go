@@ -1,7 +1,8 @@
 package database
 
 import (
-	"fmt"
+	"database/sql"
+	"time"
 
 	"github.com/thinkscotty/kibble/internal/models"
 )
@@ -46,7 +47,9 @@ func (db *DB) GetSetting(key string) (string, error) {
 }
 
 func (db *DB) SetSetting(key, value string) error {
-	_, err := db.conn.Exec(`INSERT OR REPLACE INTO settings (key, value, updated_at) VALUES (?, ?, datetime('now'))`,
+	_, err := db.conn.Exec(`
+		INSERT INTO settings (key, value, updated_at) VALUES (?, ?, datetime('now'))
+		ON CONFLICT(key) DO UPDATE SET value = excluded.value, updated_at = excluded.updated_at`,
 		key, value)
 	if err != nil {
 		return err
@@ -69,10 +72,10 @@ func (db *DB) GetAllSettings() (map[string]string, error) {
 
 func (db *DB) LogAPIUsage(log models.APIUsageLog) error {
 	_, err := db.conn.Exec(`
-		INSERT INTO api_usage_log (topic_id, facts_requested, facts_generated, facts_discarded, tokens_used, ai_provider, ai_model, error_message)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?)`,
-		log.TopicID, log.FactsRequested, log.FactsGenerated, log.FactsDiscarded,
-		log.TokensUsed, log.AIProvider, log.AIModel, log.ErrorMessage)
+		INSERT INTO api_usage_log (topic_id, news_topic_id, facts_requested, facts_generated, facts_discarded, tokens_used, estimated_cost_usd, ai_provider, ai_model, error_message)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+		log.TopicID, log.NewsTopicID, log.FactsRequested, log.FactsGenerated, log.FactsDiscarded,
+		log.TokensUsed, log.EstimatedCost, log.AIProvider, log.AIModel, log.ErrorMessage)
 	return err
 }
 
@@ -113,12 +116,13 @@ func (db *DB) GetStats() (models.Stats, error) {
 
 func (db *DB) RecentAPIUsage(limit int) ([]models.APIUsageLog, error) {
 	rows, err := db.conn.Query(`
-		SELECT l.id, l.topic_id, COALESCE(t.name, 'Deleted Topic'), l.facts_requested,
-		       l.facts_generated, l.facts_discarded, l.tokens_used,
+		SELECT l.id, l.topic_id, l.news_topic_id, COALESCE(t.name, n.name, 'Deleted Topic'), l.facts_requested,
+		       l.facts_generated, l.facts_discarded, l.tokens_used, l.estimated_cost_usd,
 		       l.ai_provider, l.ai_model,
 		       COALESCE(l.error_message, ''), l.created_at
 		FROM api_usage_log l
 		LEFT JOIN topics t ON l.topic_id = t.id
+		LEFT JOIN news_topics n ON l.news_topic_id = n.id
 		ORDER BY l.created_at DESC LIMIT ?`, limit)
 	if err != nil {
 		return nil, err
@@ -129,8 +133,8 @@ func (db *DB) RecentAPIUsage(limit int) ([]models.APIUsageLog, error) {
 	for rows.Next() {
 		var log models.APIUsageLog
 		var createdAt string
-		if err := rows.Scan(&log.ID, &log.TopicID, &log.TopicName, &log.FactsRequested,
-			&log.FactsGenerated, &log.FactsDiscarded, &log.TokensUsed,
+		if err := rows.Scan(&log.ID, &log.TopicID, &log.NewsTopicID, &log.TopicName, &log.FactsRequested,
+			&log.FactsGenerated, &log.FactsDiscarded, &log.TokensUsed, &log.EstimatedCost,
 			&log.AIProvider, &log.AIModel,
 			&log.ErrorMessage, &createdAt); err != nil {
 			return nil, err
@@ -141,6 +145,16 @@ func (db *DB) RecentAPIUsage(limit int) ([]models.APIUsageLog, error) {
 	return logs, rows.Err()
 }
 
+// MonthlySpend returns the estimated AI cost in US dollars of the requests
+// logged since the start of the current month (UTC).
+func (db *DB) MonthlySpend() (float64, error) {
+	var total float64
+	err := db.conn.QueryRow(`
+		SELECT COALESCE(SUM(estimated_cost_usd), 0) FROM api_usage_log
+		WHERE created_at >= ?`, time.Now().UTC().Format("2006-01")+"-01 00:00:00").Scan(&total)
+	return total, err
+}
+
 // LogRefresh records a refresh attempt (facts or news) in the refresh_log table.
 func (db *DB) LogRefresh(entry models.RefreshLog) error {
 	_, err := db.conn.Exec(`
@@ -163,7 +177,41 @@ func (db *DB) RecentRefreshLogs(limit int) ([]models.RefreshLog, error) {
 		return nil, err
 	}
 	defer rows.Close()
+	return scanRefreshLogs(rows)
+}
+
+// ListRefreshLogs returns up to limit refresh log entries, newest first. A zero
+// since, or an empty status or topicType, leaves that filter off.
+func (db *DB) ListRefreshLogs(since time.Time, status, topicType string, limit int) ([]models.RefreshLog, error) {
+	query := `
+		SELECT id, topic_type, topic_id, topic_name, status, error_type, error_message,
+		       duration_ms, ai_provider, ai_model, item_count, created_at
+		FROM refresh_log WHERE 1 = 1`
+	var args []any
+	if !since.IsZero() {
+		query += ` AND created_at >= ?`
+		args = append(args, since.UTC().Format("2006-01-02 15:04:05"))
+	}
+	if status != "" {
+		query += ` AND status = ?`
+		args = append(args, status)
+	}
+	if topicType != "" {
+		query += ` AND topic_type = ?`
+		args = append(args, topicType)
+	}
+	query += ` ORDER BY created_at DESC LIMIT ?`
+	args = append(args, limit)
+
+	rows, err := db.conn.Query(query, args...)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+	return scanRefreshLogs(rows)
+}
 
+func scanRefreshLogs(rows *sql.Rows) ([]models.RefreshLog, error) {
 	var logs []models.RefreshLog
 	for rows.Next() {
 		var entry models.RefreshLog
@@ -180,9 +228,13 @@ func (db *DB) RecentRefreshLogs(limit int) ([]models.RefreshLog, error) {
 	return logs, rows.Err()
 }
 
-// CleanOldRefreshLogs removes refresh log entries older than the given number of days.
-func (db *DB) CleanOldRefreshLogs(days int) error {
-	_, err := db.conn.Exec(`DELETE FROM refresh_log WHERE created_at < datetime('now', ?)`,
-		fmt.Sprintf("-%d days", days))
-	return err
+// CleanOldRefreshLogs removes refresh log entries older than the given number of
+// days and returns how many were removed.
+func (db *DB) CleanOldRefreshLogs(days int) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM refresh_log WHERE created_at < ?`,
+		formatTime(time.Now().AddDate(0, 0, -days)))
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
 }
@@ -2,6 +2,7 @@ package database
 
 import (
 	"database/sql"
+	"errors"
 	"fmt"
 	"time"
 
@@ -10,9 +11,9 @@ import (
 
 func (db *DB) ListTopics() ([]models.Topic, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, name, description, display_order, is_active, facts_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		SELECT id, name, description, display_order, is_active, facts_per_refresh, target_fact_count, max_facts, temperature, max_tokens,
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words,
+		       ai_provider, is_niche, use_research, require_verifiable, series_mode, overview, overview_updated_at, last_refreshed_at, snoozed_until, is_paused, created_at, updated_at
 		FROM topics ORDER BY display_order ASC, id ASC`)
 	if err != nil {
 		return nil, err
@@ -23,9 +24,9 @@ func (db *DB) ListTopics() ([]models.Topic, error) {
 
 func (db *DB) ListActiveTopics() ([]models.Topic, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, name, description, display_order, is_active, facts_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		SELECT id, name, description, display_order, is_active, facts_per_refresh, target_fact_count, max_facts, temperature, max_tokens,
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words,
+		       ai_provider, is_niche, use_research, require_verifiable, series_mode, overview, overview_updated_at, last_refreshed_at, snoozed_until, is_paused, created_at, updated_at
 		FROM topics WHERE is_active = 1 ORDER BY display_order ASC, id ASC`)
 	if err != nil {
 		return nil, err
@@ -36,18 +37,18 @@ func (db *DB) ListActiveTopics() ([]models.Topic, error) {
 
 func (db *DB) GetTopic(id int64) (models.Topic, error) {
 	var t models.Topic
-	var lastRefreshed sql.NullString
+	var lastRefreshed, snoozedUntil, overviewUpdated sql.NullString
 	var createdAt, updatedAt string
 
 	err := db.conn.QueryRow(`
-		SELECT id, name, description, display_order, is_active, facts_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		SELECT id, name, description, display_order, is_active, facts_per_refresh, target_fact_count, max_facts, temperature, max_tokens,
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words,
+		       ai_provider, is_niche, use_research, require_verifiable, series_mode, overview, overview_updated_at, last_refreshed_at, snoozed_until, is_paused, created_at, updated_at
 		FROM topics WHERE id = ?`, id).Scan(
 		&t.ID, &t.Name, &t.Description, &t.DisplayOrder, &t.IsActive,
-		&t.FactsPerRefresh, &t.RefreshIntervalMinutes,
+		&t.FactsPerRefresh, &t.TargetFactCount, &t.MaxFacts, &t.Temperature, &t.MaxTokens, &t.RefreshIntervalMinutes, &t.ScheduleOffsetMinutes,
 		&t.SummaryMinWords, &t.SummaryMaxWords,
-		&t.AIProvider, &t.IsNiche, &lastRefreshed,
+		&t.AIProvider, &t.IsNiche, &t.UseResearch, &t.RequireVerifiable, &t.SeriesMode, &t.Overview, &overviewUpdated, &lastRefreshed, &snoozedUntil, &t.IsPaused,
 		&createdAt, &updatedAt)
 	if err != nil {
 		return t, err
@@ -59,6 +60,14 @@ func (db *DB) GetTopic(id int64) (models.Topic, error) {
 		parsed, _ := time.Parse("2006-01-02 15:04:05", lastRefreshed.String)
 		t.LastRefreshedAt = &parsed
 	}
+	if snoozedUntil.Valid {
+		parsed, _ := parseTime(snoozedUntil.String)
+		t.SnoozedUntil = &parsed
+	}
+	if overviewUpdated.Valid {
+		parsed, _ := time.Parse("2006-01-02 15:04:05", overviewUpdated.String)
+		t.OverviewUpdatedAt = &parsed
+	}
 	return t, nil
 }
 
@@ -71,17 +80,14 @@ func (db *DB) CreateTopic(t *models.Topic) error {
 		nextOrder = int(maxOrder.Int64) + 1
 	}
 
-	result, err := db.conn.Exec(`
-		INSERT INTO topics (name, description, display_order, is_active, facts_per_refresh, refresh_interval_minutes, summary_min_words, summary_max_words, ai_provider, is_niche)
-		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
+	t.ScheduleOffsetMinutes = newScheduleOffset()
+	id, err := db.conn.insertID(`
+		INSERT INTO topics (name, description, display_order, is_active, facts_per_refresh, target_fact_count, max_facts, temperature, max_tokens, refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words, ai_provider, is_niche, use_research, require_verifiable, series_mode)
+		VALUES (?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?, ?)`,
 		t.Name, t.Description, nextOrder, boolToInt(t.IsActive),
-		t.FactsPerRefresh, t.RefreshIntervalMinutes,
+		t.FactsPerRefresh, t.TargetFactCount, t.MaxFacts, t.Temperature, t.MaxTokens, t.RefreshIntervalMinutes, t.ScheduleOffsetMinutes,
 		t.SummaryMinWords, t.SummaryMaxWords,
-		t.AIProvider, boolToInt(t.IsNiche))
-	if err != nil {
-		return err
-	}
-	id, err := result.LastInsertId()
+		t.AIProvider, boolToInt(t.IsNiche), boolToInt(t.UseResearch), boolToInt(t.RequireVerifiable), boolToInt(t.SeriesMode))
 	if err != nil {
 		return err
 	}
@@ -90,24 +96,57 @@ func (db *DB) CreateTopic(t *models.Topic) error {
 	return nil
 }
 
+// TopicNameExists reports whether another fact topic already uses name,
+// compared case-insensitively. excludeID skips the topic being edited (0 for none).
+func (db *DB) TopicNameExists(name string, excludeID int64) (bool, error) {
+	var count int
+	err := db.conn.QueryRow(`SELECT COUNT(*) FROM topics WHERE LOWER(name) = LOWER(?) AND id != ?`,
+		name, excludeID).Scan(&count)
+	return count > 0, err
+}
+
 func (db *DB) UpdateTopic(t *models.Topic) error {
 	_, err := db.conn.Exec(`
 		UPDATE topics SET name = ?, description = ?, is_active = ?,
-		       facts_per_refresh = ?, refresh_interval_minutes = ?,
+		       facts_per_refresh = ?, target_fact_count = ?, max_facts = ?, temperature = ?, max_tokens = ?, refresh_interval_minutes = ?, schedule_offset_minutes = ?,
 		       summary_min_words = ?, summary_max_words = ?,
-		       ai_provider = ?, is_niche = ?,
+		       ai_provider = ?, is_niche = ?, use_research = ?, require_verifiable = ?, series_mode = ?,
 		       updated_at = datetime('now')
 		WHERE id = ?`,
 		t.Name, t.Description, boolToInt(t.IsActive),
-		t.FactsPerRefresh, t.RefreshIntervalMinutes,
+		t.FactsPerRefresh, t.TargetFactCount, t.MaxFacts, t.Temperature, t.MaxTokens, t.RefreshIntervalMinutes, t.ScheduleOffsetMinutes,
 		t.SummaryMinWords, t.SummaryMaxWords,
-		t.AIProvider, boolToInt(t.IsNiche), t.ID)
+		t.AIProvider, boolToInt(t.IsNiche), boolToInt(t.UseResearch), boolToInt(t.RequireVerifiable), boolToInt(t.SeriesMode), t.ID)
 	return err
 }
 
-func (db *DB) DeleteTopic(id int64) error {
-	_, err := db.conn.Exec(`DELETE FROM topics WHERE id = ?`, id)
-	return err
+// ErrTopicHasCustomFacts is returned by DeleteTopic when the topic has custom
+// facts and the caller did not opt in to deleting them.
+var ErrTopicHasCustomFacts = errors.New("topic has custom facts")
+
+// DeleteTopic deletes a topic and its facts. Custom facts are protected by
+// default: unless includeCustom is set, a topic that has any is left in place
+// and ErrTopicHasCustomFacts is returned.
+func (db *DB) DeleteTopic(id int64, includeCustom bool) error {
+	result, err := db.conn.Exec(`
+		DELETE FROM topics WHERE id = ? AND (? = 1 OR NOT EXISTS (
+			SELECT 1 FROM facts WHERE facts.topic_id = topics.id AND facts.is_custom = 1
+		))`, id, boolToInt(includeCustom))
+	if err != nil {
+		return err
+	}
+	if n, err := result.RowsAffected(); err != nil || n > 0 || includeCustom {
+		return err
+	}
+	var exists int
+	err = db.conn.QueryRow(`SELECT COUNT(*) FROM topics WHERE id = ?`, id).Scan(&exists)
+	if err != nil {
+		return err
+	}
+	if exists > 0 {
+		return ErrTopicHasCustomFacts
+	}
+	return nil
 }
 
 func (db *DB) ToggleTopicActive(id int64, active bool) error {
@@ -116,6 +155,23 @@ func (db *DB) ToggleTopicActive(id int64, active bool) error {
 	return err
 }
 
+// SnoozeTopic pauses scheduled refreshes for a topic until the given time.
+// A nil until clears the snooze.
+func (db *DB) SnoozeTopic(id int64, until *time.Time) error {
+	_, err := db.conn.Exec(`UPDATE topics SET snoozed_until = ?, updated_at = datetime('now') WHERE id = ?`,
+		formatSnooze(until), id)
+	return err
+}
+
+// PauseTopic stops or resumes scheduled refreshes for a topic until changed
+// again. Unlike disabling it, the topic and its facts stay on the dashboard and
+// in the API.
+func (db *DB) PauseTopic(id int64, paused bool) error {
+	_, err := db.conn.Exec(`UPDATE topics SET is_paused = ?, updated_at = datetime('now') WHERE id = ?`,
+		boolToInt(paused), id)
+	return err
+}
+
 func (db *DB) ReorderTopics(ids []int64) error {
 	tx, err := db.conn.Begin()
 	if err != nil {
@@ -142,15 +198,21 @@ func (db *DB) UpdateTopicRefreshTime(id int64) error {
 	return err
 }
 
+// SetTopicOverview stores a topic's synthesized overview.
+func (db *DB) SetTopicOverview(id int64, overview string) error {
+	_, err := db.conn.Exec(`UPDATE topics SET overview = ?, overview_updated_at = datetime('now') WHERE id = ?`, overview, id)
+	return err
+}
+
 func (db *DB) TopicsDueForRefresh() ([]models.Topic, error) {
 	rows, err := db.conn.Query(`
-		SELECT id, name, description, display_order, is_active, facts_per_refresh,
-		       refresh_interval_minutes, summary_min_words, summary_max_words,
-		       ai_provider, is_niche, last_refreshed_at, created_at, updated_at
+		SELECT id, name, description, display_order, is_active, facts_per_refresh, target_fact_count, max_facts, temperature, max_tokens,
+		       refresh_interval_minutes, schedule_offset_minutes, summary_min_words, summary_max_words,
+		       ai_provider, is_niche, use_research, require_verifiable, series_mode, overview, overview_updated_at, last_refreshed_at, snoozed_until, is_paused, created_at, updated_at
 		FROM topics
-		WHERE is_active = 1
-		  AND (last_refreshed_at IS NULL
-		       OR datetime('now') > datetime(last_refreshed_at, '+' || refresh_interval_minutes || ' minutes'))
+		WHERE is_active = 1 AND is_paused = 0
+		  AND (snoozed_until IS NULL OR datetime('now') >= snoozed_until)
+		  AND ` + db.refreshDue() + `
 		ORDER BY last_refreshed_at ASC NULLS FIRST`)
 	if err != nil {
 		return nil, err
@@ -172,14 +234,14 @@ func scanTopics(rows *sql.Rows) ([]models.Topic, error) {
 	var topics []models.Topic
 	for rows.Next() {
 		var t models.Topic
-		var lastRefreshed sql.NullString
+		var lastRefreshed, snoozedUntil, overviewUpdated sql.NullString
 		var createdAt, updatedAt string
 
 		if err := rows.Scan(
 			&t.ID, &t.Name, &t.Description, &t.DisplayOrder, &t.IsActive,
-			&t.FactsPerRefresh, &t.RefreshIntervalMinutes,
+			&t.FactsPerRefresh, &t.TargetFactCount, &t.MaxFacts, &t.Temperature, &t.MaxTokens, &t.RefreshIntervalMinutes, &t.ScheduleOffsetMinutes,
 			&t.SummaryMinWords, &t.SummaryMaxWords,
-			&t.AIProvider, &t.IsNiche, &lastRefreshed,
+			&t.AIProvider, &t.IsNiche, &t.UseResearch, &t.RequireVerifiable, &t.SeriesMode, &t.Overview, &overviewUpdated, &lastRefreshed, &snoozedUntil, &t.IsPaused,
 			&createdAt, &updatedAt,
 		); err != nil {
 			return nil, fmt.Errorf("scan topic: %w", err)
@@ -191,11 +253,27 @@ func scanTopics(rows *sql.Rows) ([]models.Topic, error) {
 			parsed, _ := time.Parse("2006-01-02 15:04:05", lastRefreshed.String)
 			t.LastRefreshedAt = &parsed
 		}
+		if snoozedUntil.Valid {
+			parsed, _ := parseTime(snoozedUntil.String)
+			t.SnoozedUntil = &parsed
+		}
+		if overviewUpdated.Valid {
+			parsed, _ := time.Parse("2006-01-02 15:04:05", overviewUpdated.String)
+			t.OverviewUpdatedAt = &parsed
+		}
 		topics = append(topics, t)
 	}
 	return topics, rows.Err()
 }
 
+// formatSnooze converts a snooze time to its stored form, or NULL when cleared.
+func formatSnooze(until *time.Time) any {
+	if until == nil {
+		return nil
+	}
+	return formatTime(*until)
+}
+
 func boolToInt(b bool) int {
 	if b {
 		return 1
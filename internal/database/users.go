@@ -30,17 +30,13 @@ func (db *DB) GetUserByUsername(username string) (models.User, error) {
 
 // CreateUser inserts a new user record.
 func (db *DB) CreateUser(u *models.User) error {
-	result, err := db.conn.Exec(
+	id, err := db.conn.insertID(
 		`INSERT INTO users (username, password_hash) VALUES (?, ?)`,
 		u.Username, u.PasswordHash,
 	)
 	if err != nil {
 		return fmt.Errorf("create user: %w", err)
 	}
-	id, err := result.LastInsertId()
-	if err != nil {
-		return err
-	}
 	u.ID = id
 	return nil
 }
@@ -48,8 +44,8 @@ func (db *DB) CreateUser(u *models.User) error {
 // CreateSession inserts a new session record.
 func (db *DB) CreateSession(sess *models.Session) error {
 	_, err := db.conn.Exec(
-		`INSERT INTO sessions (token, user_id, expires_at) VALUES (?, ?, datetime(?))`,
-		sess.Token, sess.UserID, sess.ExpiresAt.UTC().Format("2006-01-02 15:04:05"),
+		`INSERT INTO sessions (token, user_id, expires_at) VALUES (?, ?, ?)`,
+		sess.Token, sess.UserID, formatTime(sess.ExpiresAt),
 	)
 	return err
 }
@@ -86,3 +82,46 @@ func (db *DB) DeleteExpiredSessions() (int64, error) {
 	}
 	return result.RowsAffected()
 }
+
+// ListSessions returns a user's non-expired sessions, newest first.
+func (db *DB) ListSessions(userID int64) ([]models.Session, error) {
+	rows, err := db.conn.Query(
+		`SELECT id, token, user_id, expires_at, created_at
+		 FROM sessions
+		 WHERE user_id = ? AND expires_at > datetime('now')
+		 ORDER BY created_at DESC, id DESC`,
+		userID,
+	)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var sessions []models.Session
+	for rows.Next() {
+		var sess models.Session
+		var expiresAt, createdAt string
+		if err := rows.Scan(&sess.ID, &sess.Token, &sess.UserID, &expiresAt, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan session: %w", err)
+		}
+		sess.ExpiresAt, _ = parseTime(expiresAt)
+		sess.CreatedAt, _ = parseTime(createdAt)
+		sessions = append(sessions, sess)
+	}
+	return sessions, rows.Err()
+}
+
+// DeleteUserSession removes one of a user's sessions by ID (for remote revocation).
+func (db *DB) DeleteUserSession(userID, id int64) error {
+	_, err := db.conn.Exec(`DELETE FROM sessions WHERE id = ? AND user_id = ?`, id, userID)
+	return err
+}
+
+// DeleteUserSessions removes every session belonging to a user (log out everywhere).
+func (db *DB) DeleteUserSessions(userID int64) (int64, error) {
+	result, err := db.conn.Exec(`DELETE FROM sessions WHERE user_id = ?`, userID)
+	if err != nil {
+		return 0, err
+	}
+	return result.RowsAffected()
+}
@@ -0,0 +1,59 @@
+package database
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// ListWebhooksForNewsTopic returns a news topic's webhooks, oldest first.
+func (db *DB) ListWebhooksForNewsTopic(newsTopicID int64) ([]models.Webhook, error) {
+	rows, err := db.conn.Query(`
+		SELECT id, news_topic_id, url, last_error, last_sent_at, created_at
+		FROM webhooks WHERE news_topic_id = ? ORDER BY id ASC`, newsTopicID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var hooks []models.Webhook
+	for rows.Next() {
+		var h models.Webhook
+		var lastSent sql.NullString
+		var createdAt string
+		if err := rows.Scan(&h.ID, &h.NewsTopicID, &h.URL, &h.LastError, &lastSent, &createdAt); err != nil {
+			return nil, fmt.Errorf("scan webhook: %w", err)
+		}
+		if lastSent.Valid {
+			parsed, _ := parseTime(lastSent.String)
+			h.LastSentAt = &parsed
+		}
+		h.CreatedAt, _ = parseTime(createdAt)
+		hooks = append(hooks, h)
+	}
+	return hooks, rows.Err()
+}
+
+// GetWebhookTopicID returns the ID of the news topic a webhook belongs to.
+func (db *DB) GetWebhookTopicID(id int64) (int64, error) {
+	var newsTopicID int64
+	err := db.conn.QueryRow(`SELECT news_topic_id FROM webhooks WHERE id = ?`, id).Scan(&newsTopicID)
+	return newsTopicID, err
+}
+
+func (db *DB) AddWebhook(newsTopicID int64, url string) (int64, error) {
+	return db.conn.insertID(`INSERT INTO webhooks (news_topic_id, url) VALUES (?, ?)`, newsTopicID, url)
+}
+
+func (db *DB) DeleteWebhook(id int64) error {
+	_, err := db.conn.Exec(`DELETE FROM webhooks WHERE id = ?`, id)
+	return err
+}
+
+// SetWebhookResult records a delivery attempt: lastError is "" on success.
+func (db *DB) SetWebhookResult(id int64, lastError string) error {
+	_, err := db.conn.Exec(`UPDATE webhooks SET last_error = ?, last_sent_at = datetime('now') WHERE id = ?`,
+		lastError, id)
+	return err
+}
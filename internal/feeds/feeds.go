@@ -7,6 +7,7 @@ type Feed struct {
 	Name        string
 	URL         string
 	Description string
+	Category    string // set on results from FindRelevant
 }
 
 // Category groups feeds by topic area.
@@ -713,7 +714,8 @@ var Categories = []Category{
 }
 
 // FindRelevant searches the curated feed database for feeds matching
-// the given topic name and description. Returns up to 20 matching feeds.
+// the given topic name and description. Returns up to 20 matching feeds,
+// each tagged with the name of its category.
 func FindRelevant(topicName, description string) []Feed {
 	query := strings.ToLower(topicName + " " + description)
 	words := strings.Fields(query)
@@ -750,6 +752,7 @@ func FindRelevant(topicName, description string) []Feed {
 			for _, f := range cat.Feeds {
 				if !seen[f.URL] {
 					seen[f.URL] = true
+					f.Category = cat.Name
 					results = append(results, f)
 				}
 			}
@@ -765,6 +768,7 @@ func FindRelevant(topicName, description string) []Feed {
 			for _, kw := range keywords {
 				if strings.Contains(feedText, kw) {
 					seen[f.URL] = true
+					f.Category = cat.Name
 					results = append(results, f)
 					break
 				}
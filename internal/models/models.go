@@ -9,29 +9,47 @@ type Topic struct {
 	DisplayOrder           int        `json:"display_order"`
 	IsActive               bool       `json:"is_active"`
 	FactsPerRefresh        int        `json:"facts_per_refresh"`
+	TargetFactCount        int        `json:"target_fact_count"` // when set, refreshes only top the topic up to this many facts
+	MaxFacts               int        `json:"max_facts"`         // when set, the oldest AI facts beyond this many are archived after a refresh
+	Temperature            float64    `json:"temperature"`       // sampling temperature for fact generation; 0 uses the default
+	MaxTokens              int        `json:"max_tokens"`        // response token limit for fact generation; 0 uses the default
 	RefreshIntervalMinutes int        `json:"refresh_interval_minutes"`
+	ScheduleOffsetMinutes  int        `json:"schedule_offset_minutes"` // shifts the refresh schedule so topics don't refresh together
 	SummaryMinWords        int        `json:"summary_min_words"`
 	SummaryMaxWords        int        `json:"summary_max_words"`
 	AIProvider             string     `json:"ai_provider"`
 	IsNiche                bool       `json:"is_niche"`
+	UseResearch            bool       `json:"use_research"`       // niche topics only: ground facts in Wikipedia research
+	RequireVerifiable      bool       `json:"require_verifiable"` // keep only facts the AI rates medium or high confidence
+	SeriesMode             bool       `json:"series_mode"`        // facts form an ordered, continuing sequence
+	Overview               string     `json:"overview,omitempty"` // AI synthesis of recent facts, refreshed on demand
+	OverviewUpdatedAt      *time.Time `json:"overview_updated_at,omitempty"`
 	LastRefreshedAt        *time.Time `json:"last_refreshed_at,omitempty"`
+	SnoozedUntil           *time.Time `json:"snoozed_until,omitempty"`
+	IsPaused               bool       `json:"is_paused"` // scheduled refreshes stop until resumed; the topic stays visible
 	CreatedAt              time.Time  `json:"created_at"`
 	UpdatedAt              time.Time  `json:"updated_at"`
 }
 
 type Fact struct {
-	ID         int64     `json:"id"`
-	TopicID    int64     `json:"topic_id"`
-	TopicName  string    `json:"topic_name,omitempty"`
-	Content    string    `json:"content"`
-	Trigrams   string    `json:"-"`
-	IsCustom   bool      `json:"is_custom"`
-	IsArchived bool      `json:"is_archived"`
-	Source     string    `json:"source"`
-	AIProvider string    `json:"ai_provider"`
-	AIModel    string    `json:"ai_model"`
-	CreatedAt  time.Time `json:"created_at"`
-	UpdatedAt  time.Time `json:"updated_at"`
+	ID            int64     `json:"id"`
+	TopicID       int64     `json:"topic_id"`
+	TopicName     string    `json:"topic_name,omitempty"`
+	Content       string    `json:"content"`
+	Trigrams      string    `json:"-"`
+	Embedding     string    `json:"-"` // JSON embedding vector when similarity_mode is "embedding"
+	IsCustom      bool      `json:"is_custom"`
+	IsArchived    bool      `json:"is_archived"`
+	Source        string    `json:"source"`
+	AIProvider    string    `json:"ai_provider"`
+	AIModel       string    `json:"ai_model"`
+	SourceTitle   string    `json:"source_title,omitempty"`
+	SourceURL     string    `json:"source_url,omitempty"`
+	WordCount     int       `json:"word_count"`
+	SequenceIndex int       `json:"sequence_index,omitempty"` // position in the topic's series; 0 outside series mode
+	Confidence    string    `json:"confidence,omitempty"`     // "high" or "medium" from topics requiring verifiable facts
+	CreatedAt     time.Time `json:"created_at"`
+	UpdatedAt     time.Time `json:"updated_at"`
 }
 
 type Setting struct {
@@ -43,11 +61,13 @@ type Setting struct {
 type APIUsageLog struct {
 	ID             int64     `json:"id"`
 	TopicID        *int64    `json:"topic_id,omitempty"`
+	NewsTopicID    *int64    `json:"news_topic_id,omitempty"`
 	TopicName      string    `json:"topic_name,omitempty"`
 	FactsRequested int       `json:"facts_requested"`
 	FactsGenerated int       `json:"facts_generated"`
 	FactsDiscarded int       `json:"facts_discarded"`
 	TokensUsed     int       `json:"tokens_used"`
+	EstimatedCost  float64   `json:"estimated_cost_usd"` // from the ai_cost_per_1k_* settings
 	AIProvider     string    `json:"ai_provider"`
 	AIModel        string    `json:"ai_model"`
 	ErrorMessage   string    `json:"error_message,omitempty"`
@@ -82,25 +102,43 @@ type NewsTopic struct {
 	IsActive               bool       `json:"is_active"`
 	StoriesPerRefresh      int        `json:"stories_per_refresh"`
 	RefreshIntervalMinutes int        `json:"refresh_interval_minutes"`
+	ScheduleOffsetMinutes  int        `json:"schedule_offset_minutes"` // shifts the refresh schedule so topics don't refresh together
 	SummaryMinWords        int        `json:"summary_min_words"`
 	SummaryMaxWords        int        `json:"summary_max_words"`
+	MaxSourcesPerRefresh   int        `json:"max_sources_per_refresh"` // 0 scrapes every active source
+	Temperature            float64    `json:"temperature"`             // sampling temperature for summaries; 0 uses the default
+	MaxTokens              int        `json:"max_tokens"`              // response token limit for summaries; 0 uses the default
 	AIProvider             string     `json:"ai_provider"`
+	DiscoveryProvider      string     `json:"discovery_provider"` // "" falls back to the discovery_provider setting
+	ContentFocus           string     `json:"content_focus"`      // "" for articles, "links" for link aggregators
+	Voice                  string     `json:"voice"`              // preset editorial voice, or "custom" for the tone setting
+	IncludeQuotes          bool       `json:"include_quotes"`     // ask for a verbatim source quote per story
+	ShowSourceLink         bool       `json:"show_source_link"`   // link stories to their source articles
 	IsNiche                bool       `json:"is_niche"`
 	LastRefreshedAt        *time.Time `json:"last_refreshed_at,omitempty"`
+	SnoozedUntil           *time.Time `json:"snoozed_until,omitempty"`
+	NeedsAttention         bool       `json:"needs_attention"`                  // discovery found no working sources
+	DiscoveryPausedUntil   *time.Time `json:"discovery_paused_until,omitempty"` // no automatic discovery before this
 	CreatedAt              time.Time  `json:"created_at"`
 	UpdatedAt              time.Time  `json:"updated_at"`
 }
 
 type NewsSource struct {
-	ID           int64     `json:"id"`
-	NewsTopicID  int64     `json:"news_topic_id"`
-	URL          string    `json:"url"`
-	Name         string    `json:"name"`
-	IsManual     bool      `json:"is_manual"`
-	IsActive     bool      `json:"is_active"`
-	FailureCount int       `json:"failure_count"`
-	LastError    string    `json:"last_error"`
-	CreatedAt    time.Time `json:"created_at"`
+	ID             int64     `json:"id"`
+	NewsTopicID    int64     `json:"news_topic_id"`
+	URL            string    `json:"url"`
+	Name           string    `json:"name"`
+	IsManual       bool      `json:"is_manual"`
+	IsActive       bool      `json:"is_active"`
+	FailureCount   int       `json:"failure_count"`
+	LastError      string    `json:"last_error"`
+	ForceFeed      bool      `json:"force_feed"`             // always parse as RSS/Atom, whatever the URL or content-type
+	TimeoutSeconds int       `json:"timeout_seconds"`        // request timeout; 0 uses the scraper default
+	MaxRetries     int       `json:"max_retries"`            // retries of a transient fetch failure before it counts
+	ContentHash    string    `json:"content_hash,omitempty"` // SHA-256 of the content last summarized
+	ETag           string    `json:"etag,omitempty"`         // feed validators from the last summarized fetch
+	LastModified   string    `json:"last_modified,omitempty"`
+	CreatedAt      time.Time `json:"created_at"`
 }
 
 type Story struct {
@@ -108,10 +146,14 @@ type Story struct {
 	NewsTopicID int64     `json:"news_topic_id"`
 	Title       string    `json:"title"`
 	Summary     string    `json:"summary"`
+	Quote       string    `json:"quote,omitempty"` // short verbatim excerpt from the source
 	SourceURL   string    `json:"source_url"`
 	SourceTitle string    `json:"source_title"`
+	Author      string    `json:"author,omitempty"` // byline from the feed item or Reddit post
 	AIProvider  string    `json:"ai_provider"`
 	AIModel     string    `json:"ai_model"`
+	WordCount   int       `json:"word_count"`
+	Importance  int       `json:"importance"` // 1-10 as rated by the summarizer, 0 if unrated
 	PublishedAt time.Time `json:"published_at"`
 	CreatedAt   time.Time `json:"created_at"`
 }
@@ -121,9 +163,35 @@ type NewsTopicWithStories struct {
 	Stories   []Story
 }
 
+// FailedSource is a news source whose most recent scrape failed, with its topic
+// name for display.
+type FailedSource struct {
+	Source       NewsSource `json:"source"`
+	TopicName    string     `json:"topic_name"`
+	LastFailedAt *time.Time `json:"last_failed_at,omitempty"`
+}
+
+// SharedSource is a source URL that more than one news topic scrapes.
+type SharedSource struct {
+	URL    string   `json:"url"`
+	Topics []string `json:"topics"`
+}
+
 type NewsTopicWithSources struct {
 	NewsTopic NewsTopic
 	Sources   []NewsSource
+	Webhooks  []Webhook
+}
+
+// Webhook is a URL that is sent a news topic's new stories after each refresh
+// that stores some.
+type Webhook struct {
+	ID          int64      `json:"id"`
+	NewsTopicID int64      `json:"news_topic_id"`
+	URL         string     `json:"url"`
+	LastError   string     `json:"last_error"` // from the latest delivery; "" if it succeeded
+	LastSentAt  *time.Time `json:"last_sent_at"`
+	CreatedAt   time.Time  `json:"created_at"`
 }
 
 type NewsRefreshStatus struct {
@@ -137,11 +205,11 @@ type NewsRefreshStatus struct {
 // RefreshLog records the outcome of a single topic refresh (facts or news).
 type RefreshLog struct {
 	ID           int64     `json:"id"`
-	TopicType    string    `json:"topic_type"`  // "facts" or "news"
+	TopicType    string    `json:"topic_type"` // "facts" or "news"
 	TopicID      int64     `json:"topic_id"`
 	TopicName    string    `json:"topic_name"`
-	Status       string    `json:"status"`      // "success" or "error"
-	ErrorType    string    `json:"error_type"`   // classified error category
+	Status       string    `json:"status"`     // "success" or "error"
+	ErrorType    string    `json:"error_type"` // classified error category, or why a successful refresh skipped work
 	ErrorMessage string    `json:"error_message"`
 	DurationMs   int64     `json:"duration_ms"`
 	AIProvider   string    `json:"ai_provider"`
@@ -150,6 +218,40 @@ type RefreshLog struct {
 	CreatedAt    time.Time `json:"created_at"`
 }
 
+// SchemaMigration is one additive database migration and when it was applied.
+type SchemaMigration struct {
+	Version   int        `json:"version"`
+	Statement string     `json:"statement"`
+	AppliedAt *time.Time `json:"applied_at"` // nil while pending
+}
+
+// SchemaObject is a table, index, trigger, or view from the database schema.
+type SchemaObject struct {
+	Type  string `json:"type"`
+	Name  string `json:"name"`
+	Table string `json:"table"`
+	SQL   string `json:"sql"`
+}
+
+// TopicHealth summarizes the current state of a fact or news topic for the health report.
+type TopicHealth struct {
+	TopicType              string     `json:"topic_type"` // "facts" or "news"
+	TopicID                int64      `json:"topic_id"`
+	Name                   string     `json:"name"`
+	IsActive               bool       `json:"is_active"`
+	RefreshIntervalMinutes int        `json:"refresh_interval_minutes"`
+	LastRefreshedAt        *time.Time `json:"last_refreshed_at,omitempty"`
+	LastStatus             string     `json:"last_status"` // latest refresh_log status, "" if never logged
+	LastErrorType          string     `json:"last_error_type,omitempty"`
+	LastErrorMessage       string     `json:"last_error_message,omitempty"`
+	LastAttemptAt          *time.Time `json:"last_attempt_at,omitempty"`
+	ItemCount              int        `json:"item_count"`     // facts or stories
+	ActiveSources          int        `json:"active_sources"` // news topics only
+	SnoozedUntil           *time.Time `json:"snoozed_until,omitempty"`
+	IsPaused               bool       `json:"is_paused"` // fact topics only
+	Overdue                bool       `json:"overdue"`
+}
+
 type Stats struct {
 	TotalTopics       int   `json:"total_topics"`
 	ActiveTopics      int   `json:"active_topics"`
@@ -0,0 +1,47 @@
+// Package retry holds the backoff and error classification shared by the AI
+// providers and the scraper when they retry failed requests.
+package retry
+
+import (
+	"errors"
+	"math/rand/v2"
+	"net"
+	"regexp"
+	"strconv"
+	"time"
+)
+
+const (
+	// BaseDelay is the wait before the first retry; each later retry waits twice as long.
+	BaseDelay = 2 * time.Second
+	// MaxDelay caps the wait between retries.
+	MaxDelay = 30 * time.Second
+)
+
+// statusCodeRe finds the HTTP status in an error such as "gemini returned
+// status 503: ...", "feed returned status 503 for ...", or "... (status: 502)".
+var statusCodeRe = regexp.MustCompile(`status:? (\d{3})`)
+
+// Backoff returns the wait before retry number attempt+1: base doubled per
+// attempt, capped at MaxDelay, then jittered to between half and all of that
+// so requests that failed together do not retry in lockstep.
+func Backoff(base time.Duration, attempt int) time.Duration {
+	delay := base << attempt
+	if delay <= 0 || delay > MaxDelay {
+		delay = MaxDelay
+	}
+	return delay/2 + rand.N(delay/2+1)
+}
+
+// Transient reports whether a failed request may succeed if repeated: its
+// error mentions a 429 or 5xx status, or it never got a response. Callers
+// decide separately how to treat canceled and timed-out contexts, since
+// context.DeadlineExceeded counts as a network error here.
+func Transient(err error) bool {
+	if m := statusCodeRe.FindStringSubmatch(err.Error()); m != nil {
+		code, _ := strconv.Atoi(m[1])
+		return code == 429 || code >= 500
+	}
+	var netErr net.Error
+	return errors.As(err, &netErr)
+}
@@ -0,0 +1,51 @@
+package retry
+
+import (
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+	"time"
+)
+
+func TestBackoff(t *testing.T) {
+	tests := []struct {
+		base    time.Duration
+		attempt int
+		want    time.Duration // before jitter
+	}{
+		{BaseDelay, 0, 2 * time.Second},
+		{BaseDelay, 1, 4 * time.Second},
+		{BaseDelay, 3, 16 * time.Second},
+		{BaseDelay, 4, MaxDelay},
+		{BaseDelay, 100, MaxDelay},
+		{time.Millisecond, 2, 4 * time.Millisecond},
+	}
+	for _, tt := range tests {
+		for range 20 {
+			if got := Backoff(tt.base, tt.attempt); got < tt.want/2 || got > tt.want {
+				t.Errorf("Backoff(%v, %d) = %v, want between %v and %v", tt.base, tt.attempt, got, tt.want/2, tt.want)
+			}
+		}
+	}
+}
+
+func TestTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("gemini returned status 429: slow down"), true},
+		{fmt.Errorf("feed returned status 503 for https://example.com/feed"), true},
+		{fmt.Errorf("scrape error for https://example.com: Bad Gateway (status: 502)"), true},
+		{fmt.Errorf("chutes returned status 400: bad request"), false},
+		{fmt.Errorf("scrape error for https://example.com: Not Found (status: 404)"), false},
+		{fmt.Errorf("openai request failed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{fmt.Errorf("parse gemini response: unexpected end of JSON input"), false},
+	}
+	for _, tt := range tests {
+		if got := Transient(tt.err); got != tt.want {
+			t.Errorf("Transient(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
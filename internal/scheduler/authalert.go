@@ -0,0 +1,144 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+)
+
+// authAlertSetting holds the raised authentication alert's message, or "" when
+// none is raised. It is kept in settings so the alert and the pause it causes
+// survive a restart.
+const authAlertSetting = "ai_auth_alert"
+
+// isAuthError reports whether err looks like a rejected or expired API key.
+func isAuthError(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "status 401") || strings.Contains(msg, "status 403") || strings.Contains(msg, "API key")
+}
+
+// noteAuthFailure records a refresh that failed with an authentication error.
+// Once auth_failure_threshold of them happen within auth_failure_window_minutes,
+// across any topics, it raises the authentication alert, which pauses scheduled
+// refreshes and sends alert_webhook_url a notification. A missing key is not a
+// failure here: those topics are already paused until the key is configured.
+func (s *Scheduler) noteAuthFailure(err error) {
+	var mk *ai.MissingKeyError
+	if err == nil || errors.As(err, &mk) || !isAuthError(err) {
+		return
+	}
+	v, _ := s.db.GetSetting("auth_failure_threshold")
+	threshold, _ := strconv.Atoi(v)
+	if threshold <= 0 {
+		return
+	}
+	v, _ = s.db.GetSetting("auth_failure_window_minutes")
+	window, _ := strconv.Atoi(v)
+	if window <= 0 {
+		window = 60
+	}
+
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+
+	now := time.Now()
+	cutoff := now.Add(-time.Duration(window) * time.Minute)
+	recent := s.authFailures[:0]
+	for _, t := range s.authFailures {
+		if t.After(cutoff) {
+			recent = append(recent, t)
+		}
+	}
+	s.authFailures = append(recent, now)
+
+	if len(s.authFailures) < threshold || s.AuthAlert() != "" {
+		return
+	}
+	msg := fmt.Sprintf("%d AI refreshes failed with authentication errors within %d minutes. Last error: %s",
+		len(s.authFailures), window, err.Error())
+	if err := s.db.SetSetting(authAlertSetting, msg); err != nil {
+		slog.Error("Failed to save authentication alert", "error", err)
+		return
+	}
+	slog.Error("Pausing scheduled AI refreshes after repeated authentication errors",
+		"failures", len(s.authFailures), "window_minutes", window, "error", err)
+
+	if url, _ := s.db.GetSetting("alert_webhook_url"); url != "" {
+		payload := alertWebhookPayload{
+			Event:    "ai_auth_failure",
+			Message:  msg,
+			Failures: len(s.authFailures),
+			Time:     now.UTC(),
+		}
+		s.notify(func(ctx context.Context) { sendAlertWebhook(ctx, url, payload) })
+	}
+}
+
+// AuthAlert returns the raised authentication alert's message, or "" if none
+// is raised. Scheduled refreshes are paused while it is raised.
+func (s *Scheduler) AuthAlert() string {
+	msg, _ := s.db.GetSetting(authAlertSetting)
+	return msg
+}
+
+// AcknowledgeAuthAlert clears the authentication alert and resumes scheduled
+// refreshes. Failures counted before it are forgotten, so the alert is only
+// raised again by new ones.
+func (s *Scheduler) AcknowledgeAuthAlert() error {
+	s.authMu.Lock()
+	defer s.authMu.Unlock()
+	s.authFailures = nil
+	if s.AuthAlert() == "" {
+		return nil
+	}
+	if err := s.db.SetSetting(authAlertSetting, ""); err != nil {
+		return err
+	}
+	slog.Info("Authentication alert cleared, resuming scheduled AI refreshes")
+	return nil
+}
+
+// alertWebhookPayload is the JSON body POSTed to alert_webhook_url.
+type alertWebhookPayload struct {
+	Event    string    `json:"event"`
+	Message  string    `json:"message"`
+	Failures int       `json:"failures"`
+	Time     time.Time `json:"time"`
+}
+
+// sendAlertWebhook POSTs an alert to the configured webhook. Failures are only
+// logged, since the dashboard alert is raised regardless.
+func sendAlertWebhook(ctx context.Context, url string, payload alertWebhookPayload) {
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode alert webhook", "error", err)
+		return
+	}
+	req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+	if err != nil {
+		slog.Warn("Failed to send alert webhook", "url", url, "error", err)
+		return
+	}
+	req.Header.Set("Content-Type", "application/json")
+	client := &http.Client{Timeout: 15 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		slog.Warn("Failed to send alert webhook", "url", url, "error", err)
+		return
+	}
+	resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		slog.Warn("Alert webhook returned an error", "url", url, "status", resp.StatusCode)
+		return
+	}
+	slog.Info("Sent alert webhook", "event", payload.Event, "url", url)
+}
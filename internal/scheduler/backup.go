@@ -0,0 +1,95 @@
+package scheduler
+
+import (
+	"log/slog"
+	"os"
+	"path/filepath"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// backupInterval is how often scheduled backups are written to backup_dir.
+const backupInterval = 24 * time.Hour
+
+// BackupFileName names a database backup taken at t, e.g.
+// kibble-20260131-020000.db. Names sort in the order the backups were taken.
+func BackupFileName(t time.Time) string {
+	return "kibble-" + t.Format("20060102-150405") + ".db"
+}
+
+// backupIfDue writes a database backup to the backup_dir setting once a day,
+// then deletes all but the newest backup_keep backups there. The newest backup
+// already in the directory counts, so restarts don't take extra backups. A
+// failed backup waits for the next interval rather than retrying each tick.
+func (s *Scheduler) backupIfDue(now time.Time) {
+	dir, _ := s.db.GetSetting("backup_dir")
+	if dir == "" {
+		return
+	}
+	if !s.lastBackup.IsZero() && now.Sub(s.lastBackup) < backupInterval {
+		return
+	}
+
+	backups, err := listBackups(dir)
+	if err != nil && !os.IsNotExist(err) {
+		slog.Error("Failed to list database backups", "dir", dir, "error", err)
+	}
+	if len(backups) > 0 {
+		if info, err := os.Stat(filepath.Join(dir, backups[len(backups)-1])); err == nil && now.Sub(info.ModTime()) < backupInterval {
+			s.lastBackup = info.ModTime()
+			return
+		}
+	}
+	s.lastBackup = now
+
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		slog.Error("Failed to create backup directory", "dir", dir, "error", err)
+		return
+	}
+	path := filepath.Join(dir, BackupFileName(now))
+	if err := s.db.Backup(path); err != nil {
+		slog.Error("Failed to back up database", "path", path, "error", err)
+		return
+	}
+	slog.Info("Backed up database", "path", path)
+
+	v, _ := s.db.GetSetting("backup_keep")
+	keep, err := strconv.Atoi(v)
+	if err != nil || keep < 1 {
+		keep = 7
+	}
+	pruneBackups(dir, keep)
+}
+
+// listBackups returns the names of the backups in dir, oldest first.
+func listBackups(dir string) ([]string, error) {
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+	var names []string
+	for _, e := range entries {
+		if name := e.Name(); !e.IsDir() && strings.HasPrefix(name, "kibble-") && strings.HasSuffix(name, ".db") {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// pruneBackups deletes all but the newest keep backups in dir.
+func pruneBackups(dir string, keep int) {
+	backups, err := listBackups(dir)
+	if err != nil || len(backups) <= keep {
+		return
+	}
+	for _, name := range backups[:len(backups)-keep] {
+		if err := os.Remove(filepath.Join(dir, name)); err != nil {
+			slog.Warn("Failed to delete old database backup", "file", name, "error", err)
+			continue
+		}
+		slog.Info("Deleted old database backup", "file", name)
+	}
+}
@@ -0,0 +1,160 @@
+package scheduler
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+)
+
+// NewsDryRun is the outcome of running a news topic's pipeline without saving anything.
+type NewsDryRun struct {
+	TopicID      int64          `json:"topic_id"`
+	TopicName    string         `json:"topic_name"`
+	Sources      []DryRunSource `json:"sources"`
+	Stories      []DryRunStory  `json:"stories"`
+	AIProvider   string         `json:"ai_provider,omitempty"`
+	AIModel      string         `json:"ai_model,omitempty"`
+	TokensUsed   int            `json:"tokens_used"`
+	RelaxedRetry bool           `json:"relaxed_retry"`
+	Error        string         `json:"error,omitempty"`
+	DurationMs   int64          `json:"duration_ms"`
+}
+
+// DryRunSource reports how scraping one source went.
+type DryRunSource struct {
+	ID           int64  `json:"id"`
+	URL          string `json:"url"`
+	Name         string `json:"name"`
+	OK           bool   `json:"ok"`
+	Error        string `json:"error,omitempty"`
+	ContentChars int    `json:"content_chars"`
+}
+
+// DryRunStory is a candidate story and whether a real refresh would store it.
+type DryRunStory struct {
+	ai.SummarizedStory
+	WordCount     int    `json:"word_count"`
+	Kept          bool   `json:"kept"`
+	DiscardReason string `json:"discard_reason,omitempty"`
+	Warning       string `json:"warning,omitempty"`
+}
+
+// DryRunNews scrapes a news topic's active sources and summarizes them exactly as
+// a refresh would, but stores no stories, leaves source failure counts alone, and
+// does not advance the topic's refresh time. Only the AI usage is logged, so the
+// spend counts toward the month. Pipeline failures are reported in
+// the result's Error field; the returned error is only for an unknown topic.
+func (s *Scheduler) DryRunNews(ctx context.Context, newsTopicID int64) (*NewsDryRun, error) {
+	topic, err := s.db.GetNewsTopic(newsTopicID)
+	if err != nil {
+		return nil, fmt.Errorf("topic not found: %w", err)
+	}
+
+	start := time.Now()
+	result := &NewsDryRun{TopicID: topic.ID, TopicName: topic.Name}
+	defer func() { result.DurationMs = time.Since(start).Milliseconds() }()
+
+	sources, err := s.db.GetActiveSourcesForNewsTopic(newsTopicID)
+	if err != nil {
+		result.Error = fmt.Sprintf("get sources: %v", err)
+		return result, nil
+	}
+	if len(sources) == 0 {
+		result.Error = "no active sources"
+		return result, nil
+	}
+
+	sources = s.capSources(topic, sources)
+	// A dry run shows what the sources hold now, so feeds are fetched in full
+	// even if unchanged since the last refresh
+	for i := range sources {
+		sources[i].ETag, sources[i].LastModified = "", ""
+	}
+
+	scrapeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
+	defer cancel()
+
+	var scrapedContent []ai.ScrapedContent
+	for _, r := range s.scraper.ScrapeSources(scrapeCtx, sources, topic.ContentFocus) {
+		src := DryRunSource{ID: r.Source.ID, URL: r.Source.URL, Name: r.Source.Name, OK: r.Error == nil}
+		if r.Error != nil {
+			src.Error = r.Error.Error()
+		} else {
+			src.ContentChars = len(r.Content.Content)
+			scrapedContent = append(scrapedContent, *r.Content)
+		}
+		result.Sources = append(result.Sources, src)
+	}
+	if len(scrapedContent) == 0 {
+		result.Error = fmt.Sprintf("failed to scrape any content from %d active sources", len(sources))
+		return result, nil
+	}
+
+	summarizeInstr, _ := s.db.GetSetting("news_summarizing_instructions")
+	toneInstr, _ := s.db.GetSetting("news_tone_instructions")
+	existingTitles, _ := s.db.GetRecentStoryTitles(newsTopicID, 30)
+
+	sumCtx, sumCancel := context.WithTimeout(ctx, s.aiTimeout(s.ai.ContentProvider(ai.ContentNews, topic.AIProvider), 8*time.Minute, 20*time.Minute))
+	defer sumCancel()
+	sumCtx, spend := ai.TrackSpend(sumCtx)
+
+	sumOpts := ai.SummarizeOpts{
+		TopicName:               topic.Name,
+		ScrapedContent:          scrapedContent,
+		SummarizingInstructions: summarizeInstr,
+		ToneInstructions:        toneInstr,
+		MaxStories:              topic.StoriesPerRefresh,
+		MinWords:                topic.SummaryMinWords,
+		MaxWords:                topic.SummaryMaxWords,
+		AIProvider:              topic.AIProvider,
+		ExistingTitles:          existingTitles,
+		ContentFocus:            topic.ContentFocus,
+		IncludeQuotes:           topic.IncludeQuotes,
+		Voice:                   topic.Voice,
+		Temperature:             topic.Temperature,
+		MaxTokens:               topic.MaxTokens,
+	}
+	stories, tokens, provider, model, err := s.ai.SummarizeContent(sumCtx, sumOpts)
+	result.TokensUsed += tokens
+	if err == nil && len(stories) == 0 && s.relaxedRetryEnabled() {
+		result.RelaxedRetry = true
+		sumOpts.RelaxedFiltering = true
+		stories, tokens, provider, model, err = s.ai.SummarizeContent(sumCtx, sumOpts)
+		result.TokensUsed += tokens
+	}
+	result.AIProvider, result.AIModel = provider, model
+	s.logNewsUsage(topic.ID, spend, result.TokensUsed, provider, model, err)
+	if err != nil {
+		result.Error = fmt.Sprintf("summarize content: %v", err)
+		return result, nil
+	}
+	if len(stories) == 0 {
+		result.Error = fmt.Sprintf("no relevant content: summarizer returned no stories from %d scraped sources", len(scrapedContent))
+		return result, nil
+	}
+
+	enforceMax := s.enforceMaxWords()
+	cleanup := s.cleanupRules()
+	domains := newDomainLimiter(s.maxStoriesPerDomain())
+	for _, story := range stories {
+		story, reason := screenStory(story, topic, enforceMax, cleanup)
+		if reason == "" && !domains.allow(story.SourceURL) {
+			reason = "over per-domain limit"
+		}
+		var warning string
+		if topic.ShowSourceLink && story.SourceURL == "" {
+			warning = "missing source URL"
+		}
+		result.Stories = append(result.Stories, DryRunStory{
+			SummarizedStory: story,
+			WordCount:       len(strings.Fields(story.Summary)),
+			Kept:            reason == "",
+			DiscardReason:   reason,
+			Warning:         warning,
+		})
+	}
+	return result, nil
+}
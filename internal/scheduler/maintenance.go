@@ -0,0 +1,50 @@
+package scheduler
+
+import (
+	"fmt"
+	"log/slog"
+	"time"
+)
+
+// refreshLogRetentionDays is how long maintenance keeps refresh log entries.
+const refreshLogRetentionDays = 30
+
+// MaintenanceReport summarizes what a maintenance run cleaned up.
+type MaintenanceReport struct {
+	SessionsDeleted    int64 `json:"sessions_deleted"`
+	RefreshLogsDeleted int64 `json:"refresh_logs_deleted"`
+	StoriesPruned      int64 `json:"stories_pruned"`
+	SizeBeforeBytes    int64 `json:"size_before_bytes"`
+	SizeAfterBytes     int64 `json:"size_after_bytes"`
+	DurationMs         int64 `json:"duration_ms"`
+}
+
+// RunMaintenance runs every cleanup pass now: expired sessions, refresh log
+// entries older than refreshLogRetentionDays, stories over max_total_stories,
+// and finally a VACUUM to return the freed space to the disk. It stops at the
+// first pass that fails, returning the report so far.
+func (s *Scheduler) RunMaintenance() (report MaintenanceReport, err error) {
+	start := time.Now()
+	defer func() { report.DurationMs = time.Since(start).Milliseconds() }()
+
+	report.SizeBeforeBytes, _ = s.db.DatabaseSizeBytes()
+
+	if report.SessionsDeleted, err = s.db.DeleteExpiredSessions(); err != nil {
+		return report, fmt.Errorf("delete expired sessions: %w", err)
+	}
+	if report.RefreshLogsDeleted, err = s.db.CleanOldRefreshLogs(refreshLogRetentionDays); err != nil {
+		return report, fmt.Errorf("clean refresh log: %w", err)
+	}
+	if report.StoriesPruned, err = s.pruneStories(); err != nil {
+		return report, fmt.Errorf("prune stories: %w", err)
+	}
+	if err = s.db.Vacuum(); err != nil {
+		return report, fmt.Errorf("vacuum: %w", err)
+	}
+
+	report.SizeAfterBytes, _ = s.db.DatabaseSizeBytes()
+	slog.Info("Maintenance complete", "sessions", report.SessionsDeleted,
+		"refresh_logs", report.RefreshLogsDeleted, "stories", report.StoriesPruned,
+		"size_before", report.SizeBeforeBytes, "size_after", report.SizeAfterBytes)
+	return report, nil
+}
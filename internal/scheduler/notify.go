@@ -0,0 +1,75 @@
+package scheduler
+
+import (
+	"context"
+	"log/slog"
+	"time"
+)
+
+// DrainTimeout is how long Drain waits at shutdown for notifications still
+// being sent.
+const DrainTimeout = 30 * time.Second
+
+// notify sends an outbound notification, such as a webhook, in the background
+// so a slow endpoint never holds up the caller. Deliveries are tracked so
+// Drain can wait for them before the process exits. send must give up when its
+// context is canceled. Once Drain has started, send runs in the caller instead,
+// since the process is about to exit.
+func (s *Scheduler) notify(send func(ctx context.Context)) {
+	s.deliveryMu.Lock()
+	ctx, draining := s.deliveryCtx, s.draining
+	if !draining {
+		s.deliveries.Add(1)
+	}
+	s.deliveryMu.Unlock()
+
+	if draining {
+		send(ctx)
+		return
+	}
+	go func() {
+		defer s.deliveries.Done()
+		send(ctx)
+	}()
+}
+
+// Resume undoes Drain when the process turns out not to be exiting, such as
+// after a failed restart: notifications go back to being sent in the
+// background, with a fresh context if Drain canceled the old one.
+func (s *Scheduler) Resume() {
+	s.deliveryMu.Lock()
+	defer s.deliveryMu.Unlock()
+	s.draining = false
+	if s.deliveryCtx.Err() != nil {
+		s.deliveryCtx, s.stopDeliveries = context.WithCancel(context.Background())
+	}
+}
+
+// Drain waits up to timeout for notifications still being sent, so webhooks
+// for stories and alerts that are already stored are delivered before exit.
+// Deliveries still running after that are canceled. It reports whether every
+// delivery finished. At shutdown it runs once Run has returned.
+func (s *Scheduler) Drain(timeout time.Duration) bool {
+	s.deliveryMu.Lock()
+	s.draining = true
+	s.deliveryMu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		s.deliveries.Wait()
+		close(done)
+	}()
+
+	timer := time.NewTimer(timeout)
+	defer timer.Stop()
+	select {
+	case <-done:
+		return true
+	case <-timer.C:
+		slog.Warn("Canceling notifications still being sent at shutdown", "timeout", timeout)
+		s.deliveryMu.Lock()
+		s.stopDeliveries()
+		s.deliveryMu.Unlock()
+		return false
+	}
+}
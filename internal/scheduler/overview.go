@@ -0,0 +1,74 @@
+package scheduler
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// overviewFactCount is how many of a topic's latest facts an overview synthesizes.
+const overviewFactCount = 20
+
+// ErrNoFacts is returned by SynthesizeOverview for a topic with no facts to summarize.
+var ErrNoFacts = errors.New("topic has no facts to summarize")
+
+// SynthesizeOverview asks the AI for a one-paragraph overview of a topic's most
+// recent facts and stores it on the topic. It returns the new overview.
+func (s *Scheduler) SynthesizeOverview(ctx context.Context, topicID int64) (string, error) {
+	topic, err := s.db.GetTopic(topicID)
+	if err != nil {
+		return "", fmt.Errorf("topic not found: %w", err)
+	}
+
+	facts, err := s.db.ListFactsByTopic(topicID, overviewFactCount)
+	if err != nil {
+		return "", fmt.Errorf("list facts: %w", err)
+	}
+	if len(facts) == 0 {
+		return "", ErrNoFacts
+	}
+	// Oldest first, so a series reads in order
+	contents := make([]string, len(facts))
+	for i, f := range facts {
+		contents[len(facts)-1-i] = f.Content
+	}
+
+	toneInstr, _ := s.db.GetSetting("ai_tone_instructions")
+	aiCtx, cancel := context.WithTimeout(ctx, s.aiTimeout(s.ai.ContentProvider(ai.ContentFacts, topic.AIProvider), 2*time.Minute, 10*time.Minute))
+	defer cancel()
+	aiCtx, spend := ai.TrackSpend(aiCtx)
+
+	opts := ai.FactsOpts{
+		Topic:            topic.Name,
+		Description:      topic.Description,
+		ToneInstructions: toneInstr,
+		AIProvider:       topic.AIProvider,
+	}
+	overview, tokensUsed, providerName, modelName, err := s.ai.SynthesizeOverview(aiCtx, opts, contents)
+
+	logEntry := models.APIUsageLog{
+		TopicID:       &topic.ID,
+		TokensUsed:    tokensUsed,
+		EstimatedCost: spend.USD(),
+		AIProvider:    providerName,
+		AIModel:       modelName,
+	}
+	if err != nil {
+		logEntry.ErrorMessage = err.Error()
+		s.db.LogAPIUsage(logEntry)
+		return "", err
+	}
+	s.db.LogAPIUsage(logEntry)
+
+	overview = ai.SanitizeContent(overview, s.cleanupRules())
+	if err := s.db.SetTopicOverview(topicID, overview); err != nil {
+		return "", fmt.Errorf("save overview: %w", err)
+	}
+	slog.Info("Synthesized topic overview", "topic", topic.Name, "facts", len(facts), "provider", providerName)
+	return overview, nil
+}
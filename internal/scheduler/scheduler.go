@@ -2,12 +2,20 @@ package scheduler
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
 	"fmt"
 	"log/slog"
+	"math/rand/v2"
+	"net/url"
 	"runtime/debug"
+	"strconv"
 	"strings"
 	"sync"
 	"time"
+	"unicode"
+	"unicode/utf8"
 
 	"github.com/thinkscotty/kibble/internal/ai"
 	"github.com/thinkscotty/kibble/internal/database"
@@ -24,13 +32,39 @@ type Scheduler struct {
 	sim     *similarity.Checker
 	scraper *scraper.Scraper
 	locks   sync.Map // per-topic locks: topicKey -> *sync.Mutex
+	running sync.Map // topicKeys whose lock is held, for status checks
+	keyWait sync.Map // topics paused for a missing API key: topicKey -> provider name
+
+	authMu       sync.Mutex  // guards authFailures and raising the authentication alert
+	authFailures []time.Time // recent refreshes that failed with an authentication error
+
+	snapshots    SnapshotWriter // writes story snapshots to snapshot_dir; nil disables them
+	lastSnapshot time.Time      // only used from the Run loop
+	lastBackup   time.Time      // only used from the Run loop
+
+	deliveryMu     sync.Mutex         // guards draining, deliveryCtx, and adding to deliveries
+	deliveries     sync.WaitGroup     // notifications still being sent
+	draining       bool               // set by Drain; later notifications are sent inline
+	deliveryCtx    context.Context    // canceled when Drain gives up on deliveries
+	stopDeliveries context.CancelFunc // cancels deliveryCtx
 }
 
-// aiTimeout returns an appropriate context timeout based on the effective AI provider.
+// aiTimeout returns an appropriate context timeout based on the effective AI provider,
+// where "" means the global ai_provider setting, or ai_provider_weights when set.
 // Ollama (local inference) gets longer timeouts since it's significantly slower than cloud APIs.
 func (s *Scheduler) aiTimeout(topicAIProvider string, cloudTimeout, ollamaTimeout time.Duration) time.Duration {
 	provider := topicAIProvider
 	if provider == "" {
+		// A weighted pick happens per request, so allow for Ollama if it may be chosen
+		raw, _ := s.db.GetSetting("ai_provider_weights")
+		if weights, err := ai.ParseProviderWeights(raw); err == nil && len(weights) > 0 {
+			for _, w := range weights {
+				if w.Provider == "ollama" {
+					return ollamaTimeout
+				}
+			}
+			return cloudTimeout
+		}
 		provider, _ = s.db.GetSetting("ai_provider")
 	}
 	if provider == "ollama" {
@@ -39,25 +73,239 @@ func (s *Scheduler) aiTimeout(topicAIProvider string, cloudTimeout, ollamaTimeou
 	return cloudTimeout
 }
 
+// discoveryTimeout returns how long source discovery may take, allowing for a
+// retry with the discovery_fallback_provider if one is set.
+func (s *Scheduler) discoveryTimeout(opts ai.DiscoverOpts) time.Duration {
+	timeout := s.aiTimeout(s.ai.DiscoveryProvider(opts), 5*time.Minute, 15*time.Minute)
+	if fallback, _ := s.db.GetSetting("discovery_fallback_provider"); fallback != "" {
+		timeout += s.aiTimeout(fallback, 5*time.Minute, 15*time.Minute)
+	}
+	return timeout
+}
+
+// relaxedRetryEnabled reports whether a news refresh whose summarizer returned
+// no stories should be retried once with relaxed topic filtering.
+func (s *Scheduler) relaxedRetryEnabled() bool {
+	v, _ := s.db.GetSetting("news_relaxed_retry")
+	return v == "true"
+}
+
+// cleanupRules returns the content_cleanup rules applied to generated facts and
+// stories before they are stored.
+func (s *Scheduler) cleanupRules() []string {
+	v, _ := s.db.GetSetting("content_cleanup")
+	return ai.ParseCleanupRules(v)
+}
+
+// enforceMaxWords reports whether summary_max_words should be enforced by
+// truncating generated content, rather than treated only as a prompt hint.
+func (s *Scheduler) enforceMaxWords() bool {
+	v, _ := s.db.GetSetting("enforce_max_words")
+	return v == "true"
+}
+
+// useEmbeddings reports whether the similarity_mode setting asks for new facts
+// to be embedded and compared to existing ones by meaning as well as wording.
+func (s *Scheduler) useEmbeddings() bool {
+	v, _ := s.db.GetSetting("similarity_mode")
+	return v == "embedding"
+}
+
+// factRelevance scores generated facts against their topic according to the
+// fact_relevance_check setting ("off", "keyword", or "ai"). It returns nil scores
+// when the check is off or the AI check fails, so no facts are rejected.
+// Returns: scores, tokensUsed.
+func (s *Scheduler) factRelevance(ctx context.Context, opts ai.FactsOpts, facts []ai.GeneratedFact) ([]float64, int) {
+	mode, _ := s.db.GetSetting("fact_relevance_check")
+	switch mode {
+	case "keyword":
+		scores := make([]float64, len(facts))
+		for i, f := range facts {
+			scores[i] = ai.KeywordRelevance(f.Content, opts.Topic, opts.Description)
+		}
+		return scores, 0
+	case "ai":
+		contents := make([]string, len(facts))
+		for i, f := range facts {
+			contents[i] = f.Content
+		}
+		scores, tokens, err := s.ai.ScoreFactRelevance(ctx, opts, contents)
+		if err != nil {
+			slog.Warn("Relevance check failed, keeping all facts", "topic", opts.Topic, "error", err)
+			return nil, tokens
+		}
+		return scores, tokens
+	default:
+		return nil, 0
+	}
+}
+
+// factShortfallRetries returns how many follow-up requests a fact refresh may
+// make when fewer facts than requested survive filtering (0 disables them).
+func (s *Scheduler) factShortfallRetries() int {
+	v, _ := s.db.GetSetting("fact_shortfall_retries")
+	n, _ := strconv.Atoi(v)
+	return min(max(n, 0), 3)
+}
+
+// refreshConcurrency returns how many topics a tick refreshes at once, from the
+// named setting clamped to 1..8, or def when it is unset or invalid.
+func (s *Scheduler) refreshConcurrency(key string, def int) int {
+	v, _ := s.db.GetSetting(key)
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 1 {
+		return def
+	}
+	return min(n, 8)
+}
+
+// startupJitter returns the window over which topics found overdue at startup
+// are spread, from the startup_jitter_minutes setting. 0 turns it off.
+func (s *Scheduler) startupJitter() time.Duration {
+	v, _ := s.db.GetSetting("startup_jitter_minutes")
+	n, err := strconv.Atoi(v)
+	if err != nil || n < 0 {
+		return 5 * time.Minute
+	}
+	return time.Duration(n) * time.Minute
+}
+
+// sleepJitter waits a random time under jitter, reporting false if ctx ended
+// first.
+func sleepJitter(ctx context.Context, jitter time.Duration) bool {
+	if jitter <= 0 {
+		return true
+	}
+	t := time.NewTimer(rand.N(jitter))
+	defer t.Stop()
+	select {
+	case <-t.C:
+		return true
+	case <-ctx.Done():
+		return false
+	}
+}
+
+// relevanceThreshold returns the minimum relevance score (0–1) a fact needs to be kept.
+func (s *Scheduler) relevanceThreshold() float64 {
+	v, _ := s.db.GetSetting("fact_relevance_threshold")
+	if f, err := strconv.ParseFloat(v, 64); err == nil {
+		return f
+	}
+	return 0.5
+}
+
 // topicKey returns a unique key for per-topic locking.
 func topicKey(kind string, id int64) string {
 	return fmt.Sprintf("%s:%d", kind, id)
 }
 
+// seriesContextSize is how many of a series topic's latest facts are given to the
+// AI so a refresh continues the series.
+const seriesContextSize = 10
+
+// SourceFailureLimit is the accumulated failure count at which a news source
+// is auto-removed and a replacement is discovered.
+const SourceFailureLimit = 5
+
+// RefreshStatus reports the outcome of a manual refresh request.
+type RefreshStatus string
+
+const (
+	RefreshStarted        RefreshStatus = "started"
+	RefreshAlreadyRunning RefreshStatus = "already_running"
+)
+
+// isLocked reports whether a per-topic lock is currently held. It reads the
+// running set rather than the mutex, so status polls never hold the lock and
+// make a refresh starting at that moment think the topic is busy.
+func (s *Scheduler) isLocked(key string) bool {
+	_, ok := s.running.Load(key)
+	return ok
+}
+
+// IsTopicRefreshing reports whether a fact topic refresh is in progress.
+func (s *Scheduler) IsTopicRefreshing(topicID int64) bool {
+	return s.isLocked(topicKey("fact", topicID))
+}
+
+// IsNewsTopicRefreshing reports whether a news topic refresh or discovery is in progress.
+func (s *Scheduler) IsNewsTopicRefreshing(newsTopicID int64) bool {
+	return s.isLocked(topicKey("news", newsTopicID))
+}
+
+// topicLock is a held per-topic lock.
+type topicLock struct {
+	s   *Scheduler
+	key string
+	mu  *sync.Mutex
+}
+
+// Unlock releases the lock and marks the topic as no longer refreshing.
+func (l *topicLock) Unlock() {
+	l.s.running.Delete(l.key)
+	l.mu.Unlock()
+}
+
 // lockTopic acquires a per-topic mutex, creating it if needed.
-// Returns the mutex (caller must Unlock) and true if the lock was acquired.
+// Returns the lock (caller must Unlock) and true if the lock was acquired.
 // Returns nil and false if the topic is already locked (non-blocking).
-func (s *Scheduler) lockTopic(key string) (*sync.Mutex, bool) {
+func (s *Scheduler) lockTopic(key string) (*topicLock, bool) {
 	val, _ := s.locks.LoadOrStore(key, &sync.Mutex{})
 	mu := val.(*sync.Mutex)
-	if mu.TryLock() {
-		return mu, true
+	if !mu.TryLock() {
+		return nil, false
 	}
-	return nil, false
+	s.running.Store(key, struct{}{})
+	return &topicLock{s: s, key: key, mu: mu}, true
+}
+
+// pauseForMissingKey parks a topic whose refresh failed because its provider has
+// no API key, so scheduled refreshes stop retrying it every tick. It reports
+// whether err was a missing-key error.
+func (s *Scheduler) pauseForMissingKey(key string, err error) bool {
+	var mk *ai.MissingKeyError
+	if !errors.As(err, &mk) {
+		return false
+	}
+	if _, loaded := s.keyWait.LoadOrStore(key, mk.Provider); !loaded {
+		slog.Warn("Pausing scheduled refreshes until an API key is configured", "topic", key, "provider", mk.Provider)
+	}
+	return true
+}
+
+// waitingForKey reports whether a topic is paused for a missing API key. Once
+// the key has been saved the topic is resumed and false is returned.
+func (s *Scheduler) waitingForKey(key string) bool {
+	provider, ok := s.keyWait.Load(key)
+	if !ok {
+		return false
+	}
+	if !s.ai.HasAPIKey(provider.(string)) {
+		return true
+	}
+	s.keyWait.Delete(key)
+	slog.Info("API key configured, resuming scheduled refreshes", "topic", key, "provider", provider)
+	return false
+}
+
+// TopicsAwaitingAPIKey returns how many topics are paused until their
+// provider's API key is configured.
+func (s *Scheduler) TopicsAwaitingAPIKey() int {
+	n := 0
+	s.keyWait.Range(func(k, provider any) bool {
+		if !s.ai.HasAPIKey(provider.(string)) {
+			n++
+		}
+		return true
+	})
+	return n
 }
 
 func New(db *database.DB, aiClient *ai.Client, sim *similarity.Checker, sc *scraper.Scraper) *Scheduler {
-	return &Scheduler{db: db, ai: aiClient, sim: sim, scraper: sc}
+	s := &Scheduler{db: db, ai: aiClient, sim: sim, scraper: sc}
+	s.deliveryCtx, s.stopDeliveries = context.WithCancel(context.Background())
+	return s
 }
 
 // Run starts the scheduler loop. It checks for due topics every 60 seconds.
@@ -67,8 +315,8 @@ func (s *Scheduler) Run(ctx context.Context) {
 
 	slog.Info("Scheduler started")
 
-	// Run once immediately at startup
-	s.checkAndRefresh(ctx)
+	// Run once immediately at startup, spreading out a backlog of overdue topics
+	s.checkAndRefresh(ctx, s.startupJitter())
 
 	for {
 		select {
@@ -76,12 +324,15 @@ func (s *Scheduler) Run(ctx context.Context) {
 			slog.Info("Scheduler stopped")
 			return
 		case <-ticker.C:
-			s.checkAndRefresh(ctx)
+			s.checkAndRefresh(ctx, 0)
 		}
 	}
 }
 
-func (s *Scheduler) checkAndRefresh(ctx context.Context) {
+// checkAndRefresh runs one scheduler tick. When more topics are due than can
+// refresh at once, each waits a random delay under jitter before starting;
+// 0 starts them straight away.
+func (s *Scheduler) checkAndRefresh(ctx context.Context, jitter time.Duration) {
 	// Clean up expired sessions on each tick
 	if n, err := s.db.DeleteExpiredSessions(); err != nil {
 		slog.Error("Failed to delete expired sessions", "error", err)
@@ -89,20 +340,50 @@ func (s *Scheduler) checkAndRefresh(ctx context.Context) {
 		slog.Debug("Cleaned up expired sessions", "count", n)
 	}
 
-	// Refresh fact topics concurrently (up to 3 at a time)
+	// Scheduled AI refreshes stay paused while the authentication alert is raised
+	if s.AuthAlert() != "" {
+		slog.Debug("Scheduled refreshes paused by authentication alert")
+	} else {
+		s.checkAndRefreshFacts(ctx, jitter)
+		s.checkAndRefreshNews(ctx, jitter)
+	}
+
+	if _, err := s.pruneStories(); err != nil {
+		slog.Error("Failed to prune stories over the total limit", "error", err)
+	}
+
+	s.writeSnapshotsIfDue(time.Now())
+	s.backupIfDue(time.Now())
+}
+
+// checkAndRefreshFacts refreshes fact topics that are due, up to
+// fact_refresh_concurrency (default 3) at a time.
+func (s *Scheduler) checkAndRefreshFacts(ctx context.Context, jitter time.Duration) {
 	topics, err := s.db.TopicsDueForRefresh()
 	if err != nil {
 		slog.Error("Failed to query topics due for refresh", "error", err)
 	} else if len(topics) > 0 {
-		sem := make(chan struct{}, 3)
+		concurrency := s.refreshConcurrency("fact_refresh_concurrency", 3)
+		if len(topics) <= concurrency {
+			jitter = 0
+		} else if jitter > 0 {
+			slog.Info("Spreading out overdue topic refreshes", "topics", len(topics), "window", jitter)
+		}
+		sem := make(chan struct{}, concurrency)
 		var wg sync.WaitGroup
 		for _, topic := range topics {
 			if ctx.Err() != nil {
 				break
 			}
+			if s.waitingForKey(topicKey("fact", topic.ID)) {
+				continue
+			}
 			wg.Add(1)
 			go func(t models.Topic) {
 				defer wg.Done()
+				if !sleepJitter(ctx, jitter) {
+					return
+				}
 				sem <- struct{}{}
 				defer func() { <-sem }()
 				key := topicKey("fact", t.ID)
@@ -117,36 +398,92 @@ func (s *Scheduler) checkAndRefresh(ctx context.Context) {
 		}
 		wg.Wait()
 	}
+}
 
-	// Refresh news topics concurrently (up to 2 at a time)
-	s.checkAndRefreshNews(ctx)
+// pruneStories enforces the max_total_stories setting, deleting the oldest
+// stories across all news topics once the total exceeds it. 0 means no cap.
+// It returns how many stories were deleted.
+func (s *Scheduler) pruneStories() (int64, error) {
+	v, _ := s.db.GetSetting("max_total_stories")
+	limit, _ := strconv.Atoi(v)
+	if limit <= 0 {
+		return 0, nil
+	}
+	n, err := s.db.PruneStoriesOverLimit(limit)
+	if err == nil && n > 0 {
+		slog.Info("Pruned oldest stories over the total limit", "deleted", n, "limit", limit)
+	}
+	return n, err
 }
 
 func (s *Scheduler) refreshTopic(ctx context.Context, topic models.Topic) {
 	slog.Info("Refreshing topic", "topic", topic.Name, "id", topic.ID)
 	start := time.Now()
 
+	count, err := s.factsToRequest(topic)
+	if err != nil {
+		slog.Warn("Failed to count facts toward target", "topic", topic.Name, "error", err)
+	}
+	if count <= 0 {
+		slog.Info("Topic has reached its target fact count, skipping generation", "topic", topic.Name, "target", topic.TargetFactCount)
+		s.archiveExcessFacts(topic)
+		s.db.UpdateTopicRefreshTime(topic.ID)
+		s.db.LogRefresh(models.RefreshLog{
+			TopicType: "facts", TopicID: topic.ID, TopicName: topic.Name,
+			Status: "success", ErrorType: "target_reached",
+			DurationMs: time.Since(start).Milliseconds(),
+		})
+		return
+	}
+
 	customInstr, _ := s.db.GetSetting("ai_custom_instructions")
 	toneInstr, _ := s.db.GetSetting("ai_tone_instructions")
+	enforceMax := s.enforceMaxWords()
+	cleanup := s.cleanupRules()
 
-	aiCtx, aiCancel := context.WithTimeout(ctx, s.aiTimeout(topic.AIProvider, 5*time.Minute, 15*time.Minute))
+	aiCtx, aiCancel := context.WithTimeout(ctx, s.aiTimeout(s.ai.ContentProvider(ai.ContentFacts, topic.AIProvider), 5*time.Minute, 15*time.Minute))
 	defer aiCancel()
+	// Research, relevance scoring, and shortfall retries all count toward the logged cost
+	aiCtx, spend := ai.TrackSpend(aiCtx)
 
-	facts, tokensUsed, providerName, modelName, err := s.ai.GenerateFacts(aiCtx, ai.FactsOpts{
+	opts := ai.FactsOpts{
 		Topic:              topic.Name,
 		Description:        topic.Description,
 		CustomInstructions: customInstr,
 		ToneInstructions:   toneInstr,
-		Count:              topic.FactsPerRefresh,
+		Count:              count,
 		MinWords:           topic.SummaryMinWords,
 		MaxWords:           topic.SummaryMaxWords,
 		AIProvider:         topic.AIProvider,
 		IsNiche:            topic.IsNiche,
-	})
+		SkipResearch:       !topic.UseResearch,
+		RequireVerifiable:  topic.RequireVerifiable,
+		SeriesMode:         topic.SeriesMode,
+		Temperature:        topic.Temperature,
+		MaxTokens:          topic.MaxTokens,
+	}
+
+	// Series topics continue from their latest entries rather than starting over
+	nextIndex := 0
+	if topic.SeriesMode {
+		recent, err := s.db.LatestSeriesFacts(topic.ID, seriesContextSize)
+		if err != nil {
+			slog.Warn("Failed to load series so far", "topic", topic.Name, "error", err)
+		}
+		for _, f := range recent {
+			opts.SeriesSoFar = append(opts.SeriesSoFar, f.Content)
+		}
+		if nextIndex, err = s.db.NextSequenceIndex(topic.ID); err != nil {
+			slog.Warn("Failed to get next series index", "topic", topic.Name, "error", err)
+			nextIndex = 1
+		}
+	}
+
+	facts, tokensUsed, providerName, modelName, err := s.ai.GenerateFacts(aiCtx, opts)
 
 	logEntry := models.APIUsageLog{
 		TopicID:        &topic.ID,
-		FactsRequested: topic.FactsPerRefresh,
+		FactsRequested: count,
 		TokensUsed:     tokensUsed,
 		AIProvider:     providerName,
 		AIModel:        modelName,
@@ -154,7 +491,10 @@ func (s *Scheduler) refreshTopic(ctx context.Context, topic models.Topic) {
 
 	if err != nil {
 		slog.Error("Failed to generate facts", "topic", topic.Name, "error", err)
+		s.pauseForMissingKey(topicKey("fact", topic.ID), err)
+		s.noteAuthFailure(err)
 		logEntry.ErrorMessage = err.Error()
+		logEntry.EstimatedCost = spend.USD()
 		s.db.LogAPIUsage(logEntry)
 		s.db.LogRefresh(models.RefreshLog{
 			TopicType: "facts", TopicID: topic.ID, TopicName: topic.Name,
@@ -166,45 +506,130 @@ func (s *Scheduler) refreshTopic(ctx context.Context, topic models.Topic) {
 	}
 
 	// Get existing facts for similarity comparison
-	existingTrigrams := s.getExistingTrigrams(topic.ID)
+	existingTrigrams := s.getExistingTrigrams(topic)
+	minRelevance := s.relevanceThreshold()
+	useEmbeddings := s.useEmbeddings()
 
 	generated := 0
 	discarded := 0
-	for _, content := range facts {
-		if !ai.IsCompleteSentence(content, topic.SummaryMinWords) {
-			slog.Debug("Discarded incomplete fact", "topic", topic.Name, "content", content)
-			discarded++
-			continue
-		}
-		if s.sim.IsTooSimilar(content, existingTrigrams) {
-			discarded++
-			continue
-		}
+	var kept []string
+	saveFacts := func(facts []ai.GeneratedFact) {
+		relevance, relevanceTokens := s.factRelevance(aiCtx, opts, facts)
+		logEntry.TokensUsed += relevanceTokens
+
+		for i, gf := range facts {
+			if topic.TargetFactCount > 0 && generated >= count {
+				break // extra facts would overshoot the target
+			}
+			content := ai.SanitizeContent(gf.Content, cleanup)
+			if topic.RequireVerifiable && !ai.IsConfident(gf.Confidence) {
+				slog.Debug("Discarded low-confidence fact", "topic", topic.Name, "confidence", gf.Confidence, "content", content)
+				discarded++
+				continue
+			}
+			if relevance != nil && relevance[i] < minRelevance {
+				slog.Debug("Discarded off-topic fact", "topic", topic.Name, "score", relevance[i], "content", content)
+				discarded++
+				continue
+			}
+			if enforceMax && topic.SummaryMaxWords > 0 {
+				truncated, ok := ai.TruncateToWords(content, topic.SummaryMaxWords)
+				if !ok {
+					slog.Debug("Discarded fact over max words", "topic", topic.Name, "content", content)
+					discarded++
+					continue
+				}
+				content = truncated
+			}
+			if !ai.IsCompleteSentence(content, topic.SummaryMinWords) {
+				slog.Debug("Discarded incomplete fact", "topic", topic.Name, "content", content)
+				discarded++
+				continue
+			}
+			if s.sim.IsTooSimilar(content, nil, existingTrigrams) {
+				discarded++
+				continue
+			}
+			// Embedding costs a request, so only facts that pass the trigram check are embedded
+			var embedding []float32
+			if useEmbeddings {
+				vec, tokens, err := s.ai.Embed(aiCtx, content)
+				logEntry.TokensUsed += tokens
+				if err != nil {
+					slog.Warn("Failed to embed fact, checking trigrams only", "topic", topic.Name, "error", err)
+				} else if s.sim.IsTooSimilarEmbedding(vec, existingTrigrams) {
+					slog.Debug("Discarded paraphrased fact", "topic", topic.Name, "content", content)
+					discarded++
+					continue
+				}
+				embedding = vec
+			}
+
+			trigrams := s.sim.Trigrams(content)
+			fact := &models.Fact{
+				TopicID:     topic.ID,
+				Content:     content,
+				Trigrams:    s.sim.TrigramsToJSON(trigrams),
+				Embedding:   similarity.EmbeddingToJSON(embedding),
+				Source:      providerName,
+				AIProvider:  providerName,
+				AIModel:     modelName,
+				SourceTitle: gf.SourceTitle,
+				SourceURL:   gf.SourceURL,
+				Confidence:  gf.Confidence,
+			}
+			if topic.SeriesMode {
+				fact.SequenceIndex = nextIndex
+			}
+			if err := s.db.CreateFact(fact); err != nil {
+				slog.Error("Failed to save fact", "error", err)
+				continue
+			}
+			if topic.SeriesMode {
+				nextIndex++
+			}
 
-		trigrams := s.sim.Trigrams(content)
-		fact := &models.Fact{
-			TopicID:    topic.ID,
-			Content:    content,
-			Trigrams:   s.sim.TrigramsToJSON(trigrams),
-			Source:     providerName,
-			AIProvider: providerName,
-			AIModel:    modelName,
+			// Add to existing set so subsequent facts in this batch are also checked
+			existingTrigrams = append(existingTrigrams, similarity.StoredTrigrams{
+				ID:        fact.ID,
+				Trigrams:  fact.Trigrams,
+				Embedding: embedding,
+			})
+			kept = append(kept, content)
+			generated++
 		}
-		if err := s.db.CreateFact(fact); err != nil {
-			slog.Error("Failed to save fact", "error", err)
-			continue
+	}
+	saveFacts(facts)
+
+	// Top up a short batch with follow-up requests for the remainder
+	for retry := 0; generated < count && retry < s.factShortfallRetries(); retry++ {
+		retryOpts := opts
+		retryOpts.Count = count - generated
+		retryOpts.ExcludeFacts = kept
+		if topic.SeriesMode {
+			retryOpts.SeriesSoFar = append(opts.SeriesSoFar[:len(opts.SeriesSoFar):len(opts.SeriesSoFar)], kept...)
 		}
+		slog.Info("Requesting more facts to cover shortfall", "topic", topic.Name,
+			"missing", retryOpts.Count, "attempt", retry+1)
 
-		// Add to existing set so subsequent facts in this batch are also checked
-		existingTrigrams = append(existingTrigrams, similarity.StoredTrigrams{
-			ID:       fact.ID,
-			Trigrams: fact.Trigrams,
-		})
-		generated++
+		more, tokens, _, _, err := s.ai.GenerateFacts(aiCtx, retryOpts)
+		logEntry.TokensUsed += tokens
+		if err != nil {
+			slog.Warn("Follow-up fact request failed", "topic", topic.Name, "error", err)
+			break
+		}
+		saveFacts(more)
+	}
+	if generated < count {
+		slog.Warn("Topic refresh produced fewer facts than requested", "topic", topic.Name,
+			"requested", count, "generated", generated)
 	}
 
+	s.archiveExcessFacts(topic)
+
 	logEntry.FactsGenerated = generated
 	logEntry.FactsDiscarded = discarded
+	logEntry.EstimatedCost = spend.USD()
 	s.db.LogAPIUsage(logEntry)
 	s.db.UpdateTopicRefreshTime(topic.ID)
 
@@ -218,26 +643,69 @@ func (s *Scheduler) refreshTopic(ctx context.Context, topic models.Topic) {
 		"generated", generated, "discarded", discarded)
 }
 
-// RefreshNow triggers an immediate refresh for a single topic.
-func (s *Scheduler) RefreshNow(ctx context.Context, topicID int64) error {
+// archiveExcessFacts enforces a topic's max_facts cap, archiving its oldest
+// AI-generated facts beyond it. 0 means no cap. Custom facts are left alone
+// unless the archive_custom_facts setting opts them in.
+func (s *Scheduler) archiveExcessFacts(topic models.Topic) {
+	if topic.MaxFacts <= 0 {
+		return
+	}
+	includeCustom, _ := s.db.GetSetting("archive_custom_facts")
+	n, err := s.db.ArchiveFactsOverLimit(topic.ID, topic.MaxFacts, includeCustom == "true")
+	if err != nil {
+		slog.Error("Failed to archive facts over the topic limit", "topic", topic.Name, "error", err)
+		return
+	}
+	if n > 0 {
+		slog.Info("Archived oldest facts over the topic limit", "topic", topic.Name, "archived", n, "limit", topic.MaxFacts)
+	}
+}
+
+// maxFactsPerRequest caps how many facts one refresh asks for when topping a
+// topic up to its target, keeping the prompt and response a manageable size.
+// The rest come in later refreshes.
+const maxFactsPerRequest = 20
+
+// factsToRequest returns how many facts a refresh of topic should generate:
+// facts_per_refresh, or for a topic with a target total, the number still
+// missing (at most maxFactsPerRequest). It is 0 or less once the target is met.
+// If the facts can't be counted it falls back to facts_per_refresh.
+func (s *Scheduler) factsToRequest(topic models.Topic) (int, error) {
+	if topic.TargetFactCount <= 0 {
+		return topic.FactsPerRefresh, nil
+	}
+	current, err := s.db.CountFactsByTopic(topic.ID)
+	if err != nil {
+		return topic.FactsPerRefresh, err
+	}
+	return min(topic.TargetFactCount-current, maxFactsPerRequest), nil
+}
+
+// RefreshNow triggers an immediate refresh for a single topic and waits for it to finish.
+// If a scheduled or manual refresh already holds the topic, it returns
+// RefreshAlreadyRunning without waiting.
+func (s *Scheduler) RefreshNow(ctx context.Context, topicID int64) (RefreshStatus, error) {
 	key := topicKey("fact", topicID)
 	mu, ok := s.lockTopic(key)
 	if !ok {
-		return fmt.Errorf("topic is already being refreshed")
+		return RefreshAlreadyRunning, nil
 	}
 	defer mu.Unlock()
+	s.keyWait.Delete(key) // a manual refresh retries even while waiting for a key
 
 	topic, err := s.db.GetTopic(topicID)
 	if err != nil {
-		return err
+		return RefreshStarted, err
 	}
 	s.refreshTopic(ctx, topic)
-	return nil
+	return RefreshStarted, nil
 }
 
 // --- News / Updates scheduling ---
 
-func (s *Scheduler) checkAndRefreshNews(ctx context.Context) {
+// checkAndRefreshNews refreshes news topics that are due, up to
+// news_refresh_concurrency (default 2) at a time.
+func (s *Scheduler) checkAndRefreshNews(ctx context.Context, jitter time.Duration) {
 	newsTopics, err := s.db.NewsTopicsDueForRefresh()
 	if err != nil {
 		slog.Error("Failed to query news topics due for refresh", "error", err)
@@ -248,15 +716,33 @@ func (s *Scheduler) checkAndRefreshNews(ctx context.Context) {
 		return
 	}
 
-	sem := make(chan struct{}, 2)
+	// Topics refreshed this tick share one scrape of any URL they have in common
+	var batch *scraper.Batch
+	if s.sharedScrapeEnabled() {
+		batch = scraper.NewBatch()
+	}
+
+	concurrency := s.refreshConcurrency("news_refresh_concurrency", 2)
+	if len(newsTopics) <= concurrency {
+		jitter = 0
+	} else if jitter > 0 {
+		slog.Info("Spreading out overdue news topic refreshes", "topics", len(newsTopics), "window", jitter)
+	}
+	sem := make(chan struct{}, concurrency)
 	var wg sync.WaitGroup
 	for _, nt := range newsTopics {
 		if ctx.Err() != nil {
 			break
 		}
+		if s.waitingForKey(topicKey("news", nt.ID)) {
+			continue
+		}
 		wg.Add(1)
 		go func(id int64) {
 			defer wg.Done()
+			if !sleepJitter(ctx, jitter) {
+				return
+			}
 			sem <- struct{}{}
 			defer func() { <-sem }()
 			key := topicKey("news", id)
@@ -266,13 +752,25 @@ func (s *Scheduler) checkAndRefreshNews(ctx context.Context) {
 				return
 			}
 			defer mu.Unlock()
-			s.safeRefreshNewsTopic(ctx, id)
+			s.safeRefreshNewsTopic(ctx, id, batch)
 		}(nt.ID)
 	}
 	wg.Wait()
+	if batch != nil && batch.Hits() > 0 {
+		slog.Info("Shared source scrapes reused across news topics", "saved_scrapes", batch.Hits())
+	}
+}
+
+// sharedScrapeEnabled reports whether the news_shared_scrape setting is on, so a
+// source URL used by several topics due in the same tick is scraped only once.
+func (s *Scheduler) sharedScrapeEnabled() bool {
+	v, _ := s.db.GetSetting("news_shared_scrape")
+	return v == "true"
 }
 
-func (s *Scheduler) safeRefreshNewsTopic(ctx context.Context, newsTopicID int64) {
+// safeRefreshNewsTopic runs refreshNewsTopic, recovering from a panic. batch may
+// be nil.
+func (s *Scheduler) safeRefreshNewsTopic(ctx context.Context, newsTopicID int64, batch *scraper.Batch) {
 	defer func() {
 		if r := recover(); r != nil {
 			slog.Error("Panic in news topic refresh", "topic_id", newsTopicID, "panic", r, "stack", string(debug.Stack()))
@@ -284,10 +782,13 @@ func (s *Scheduler) safeRefreshNewsTopic(ctx context.Context, newsTopicID int64)
 			})
 		}
 	}()
-	s.refreshNewsTopic(ctx, newsTopicID)
+	s.refreshNewsTopic(ctx, newsTopicID, batch)
 }
 
-func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
+// refreshNewsTopic scrapes a news topic's sources and stores the stories the AI
+// summarizes from them. A non-nil batch shares scrapes with the other topics
+// refreshed in the same tick.
+func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64, batch *scraper.Batch) {
 	topic, err := s.db.GetNewsTopic(newsTopicID)
 	if err != nil {
 		slog.Error("News topic not found", "id", newsTopicID, "error", err)
@@ -311,8 +812,15 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 		return
 	}
 
-	// If no sources, try discovery first
+	// If no sources, try discovery first, unless an earlier discovery came up
+	// empty and automatic discovery is still cooling down
 	if len(sources) == 0 {
+		if until := topic.DiscoveryPausedUntil; until != nil && time.Now().Before(*until) {
+			pausedErr := fmt.Errorf("no working sources; automatic discovery paused until %s", until.Local().Format("Jan 2 15:04"))
+			s.handleNewsRefreshError(newsTopicID, pausedErr)
+			s.logNewsRefreshError(topic, start, pausedErr)
+			return
+		}
 		if err := s.discoverNewsSources(ctx, newsTopicID); err != nil {
 			s.handleNewsRefreshError(newsTopicID, fmt.Errorf("discover sources: %w", err))
 			s.logNewsRefreshError(topic, start, fmt.Errorf("discover sources: %w", err))
@@ -327,11 +835,20 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 		}
 	}
 
+	sources = s.capSources(topic, sources)
+
 	// Scrape content
 	scrapeCtx, cancel := context.WithTimeout(ctx, 5*time.Minute)
 	defer cancel()
 
-	scrapeResults := s.scraper.ScrapeSources(scrapeCtx, sources)
+	scrapeResults := s.scraper.ScrapeSourcesInBatch(scrapeCtx, sources, topic.ContentFocus, batch)
+	scrapedIDs := make([]int64, len(sources))
+	for i, src := range sources {
+		scrapedIDs[i] = src.ID
+	}
+	if err := s.db.MarkNewsSourcesScraped(scrapedIDs); err != nil {
+		slog.Warn("Failed to record scraped sources", "topic", topic.Name, "error", err)
+	}
 
 	// Process results and update source statuses.
 	// Failure count increments on each failed refresh and decrements by 1
@@ -339,7 +856,20 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 	// while chronically bad sources accumulate toward the removal threshold.
 	var scrapedContent []ai.ScrapedContent
 	var removedSourceCount int
+	hashes := make(map[int64]string, len(scrapeResults))
+	validators := make(map[int64]feedValidators)
+	unchanged := true
+	notModified := 0
 	for _, result := range scrapeResults {
+		if errors.Is(result.Error, scraper.ErrNotModified) {
+			// The feed is unchanged since it was last summarized, so it has
+			// nothing new for the summarizer
+			if result.Source.FailureCount > 0 {
+				s.db.UpdateNewsSourceStatus(result.Source.ID, true, result.Source.FailureCount-1, "")
+			}
+			notModified++
+			continue
+		}
 		if result.Error != nil {
 			newFailureCount := result.Source.FailureCount + 1
 
@@ -348,8 +878,8 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 				errMsg = errMsg[:500]
 			}
 
-			if newFailureCount >= 5 {
-				// Auto-remove source after accumulating 5 failures across refreshes
+			if newFailureCount >= SourceFailureLimit && !s.inSourceGrace(result.Source) {
+				// Auto-remove source after accumulating too many failures across refreshes
 				s.db.DeleteNewsSource(result.Source.ID)
 				removedSourceCount++
 				slog.Warn("Auto-removed failing news source",
@@ -365,6 +895,13 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 				s.db.UpdateNewsSourceStatus(result.Source.ID, true, result.Source.FailureCount-1, "")
 			}
 			scrapedContent = append(scrapedContent, *result.Content)
+			hashes[result.Source.ID] = contentHash(result.Content.Content)
+			if hashes[result.Source.ID] != result.Source.ContentHash {
+				unchanged = false
+			}
+			if result.Content.ETag != result.Source.ETag || result.Content.LastModified != result.Source.LastModified {
+				validators[result.Source.ID] = feedValidators{result.Content.ETag, result.Content.LastModified}
+			}
 		}
 	}
 
@@ -373,17 +910,31 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 		s.replaceRemovedSources(ctx, newsTopicID, removedSourceCount)
 	}
 
+	if topic.NeedsAttention && len(scrapedContent) > 0 {
+		s.db.ClearNewsTopicAttention(newsTopicID)
+	}
+
 	slog.Info("Scrape results", "topic", topic.Name, "total_sources", len(sources),
-		"scraped_ok", len(scrapedContent), "failed", len(sources)-len(scrapedContent),
-		"auto_removed", removedSourceCount)
+		"scraped_ok", len(scrapedContent), "not_modified", notModified,
+		"failed", len(sources)-len(scrapedContent)-notModified, "auto_removed", removedSourceCount)
 
-	if len(scrapedContent) == 0 {
+	if len(scrapedContent) == 0 && notModified == 0 {
 		noContentErr := fmt.Errorf("failed to scrape any content from %d active sources", len(sources))
 		s.handleNewsRefreshError(newsTopicID, noContentErr)
 		s.logNewsRefreshError(topic, start, noContentErr)
 		return
 	}
 
+	// Every source returned what was summarized last time or reported its feed
+	// unchanged, or the only sources that answered had nothing new, so the
+	// summarizer would only see the same content again
+	if unchanged && (len(scrapedContent)+notModified == len(sources) || len(scrapedContent) == 0) {
+		// The content matches what was summarized, so its validators can be kept
+		s.saveSourceState(topic, nil, validators)
+		s.handleUnchangedSources(topic, start)
+		return
+	}
+
 	// Summarize with AI
 	summarizeInstr, _ := s.db.GetSetting("news_summarizing_instructions")
 	toneInstr, _ := s.db.GetSetting("news_tone_instructions")
@@ -391,10 +942,12 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 	// Fetch recent story titles for deduplication context
 	existingTitles, _ := s.db.GetRecentStoryTitles(newsTopicID, 30)
 
-	sumCtx, sumCancel := context.WithTimeout(ctx, s.aiTimeout(topic.AIProvider, 8*time.Minute, 20*time.Minute))
+	sumCtx, sumCancel := context.WithTimeout(ctx, s.aiTimeout(s.ai.ContentProvider(ai.ContentNews, topic.AIProvider), 8*time.Minute, 20*time.Minute))
 	defer sumCancel()
+	// Both summarization passes count toward the logged cost
+	sumCtx, spend := ai.TrackSpend(sumCtx)
 
-	stories, _, storyProvider, storyModel, err := s.ai.SummarizeContent(sumCtx, ai.SummarizeOpts{
+	sumOpts := ai.SummarizeOpts{
 		TopicName:               topic.Name,
 		ScrapedContent:          scrapedContent,
 		SummarizingInstructions: summarizeInstr,
@@ -404,35 +957,85 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 		MaxWords:                topic.SummaryMaxWords,
 		AIProvider:              topic.AIProvider,
 		ExistingTitles:          existingTitles,
-	})
+		ContentFocus:            topic.ContentFocus,
+		IncludeQuotes:           topic.IncludeQuotes,
+		Voice:                   topic.Voice,
+		Temperature:             topic.Temperature,
+		MaxTokens:               topic.MaxTokens,
+	}
+	stories, tokensUsed, storyProvider, storyModel, err := s.ai.SummarizeContent(sumCtx, sumOpts)
 	if err != nil {
+		s.logNewsUsage(topic.ID, spend, tokensUsed, storyProvider, storyModel, err)
 		s.handleNewsRefreshError(newsTopicID, fmt.Errorf("summarize content: %w", err))
 		s.logNewsRefreshError(topic, start, fmt.Errorf("summarize content: %w", err))
 		return
 	}
 
+	// Scraping worked but the summarizer judged everything off-topic. Optionally
+	// retry once with looser filtering before reporting an empty refresh.
+	if len(stories) == 0 && s.relaxedRetryEnabled() {
+		slog.Warn("No stories passed the topic filter, retrying with relaxed filtering",
+			"topic", topic.Name, "scraped_sources", len(scrapedContent))
+		sumOpts.RelaxedFiltering = true
+		var retryTokens int
+		stories, retryTokens, storyProvider, storyModel, err = s.ai.SummarizeContent(sumCtx, sumOpts)
+		tokensUsed += retryTokens
+		if err != nil {
+			s.logNewsUsage(topic.ID, spend, tokensUsed, storyProvider, storyModel, err)
+			s.handleNewsRefreshError(newsTopicID, fmt.Errorf("summarize content (relaxed): %w", err))
+			s.logNewsRefreshError(topic, start, fmt.Errorf("summarize content (relaxed): %w", err))
+			return
+		}
+	}
+	s.logNewsUsage(topic.ID, spend, tokensUsed, storyProvider, storyModel, nil)
+	if len(stories) == 0 {
+		s.saveSourceState(topic, hashes, validators)
+		s.handleNoRelevantContent(topic, start, len(scrapedContent))
+		return
+	}
+
 	// Store stories, discarding any with incomplete summaries
-	storedCount := 0
+	enforceMax := s.enforceMaxWords()
+	cleanup := s.cleanupRules()
+	domains := newDomainLimiter(s.maxStoriesPerDomain())
+	bylines := collectBylines(scrapedContent)
+	var stored []models.Story
 	for _, story := range stories {
-		if !ai.IsCompleteSentence(story.Summary, topic.SummaryMinWords) {
-			slog.Debug("Discarded incomplete story", "topic", topic.Name, "title", story.Title, "summary", story.Summary)
+		story, reason := screenStory(story, topic, enforceMax, cleanup)
+		if reason != "" {
+			slog.Debug("Discarded story", "topic", topic.Name, "title", story.Title, "reason", reason, "summary", story.Summary)
+			continue
+		}
+		if !domains.allow(story.SourceURL) {
+			slog.Info("Dropped story over per-domain limit", "topic", topic.Name, "title", story.Title,
+				"domain", storyDomain(story.SourceURL), "limit", domains.limit)
 			continue
 		}
+		if topic.ShowSourceLink && story.SourceURL == "" {
+			slog.Warn("Story has no source URL", "topic", topic.Name, "title", story.Title)
+		}
 		dbStory := &models.Story{
 			NewsTopicID: newsTopicID,
 			Title:       story.Title,
 			Summary:     story.Summary,
+			Quote:       story.Quote,
 			SourceURL:   story.SourceURL,
 			SourceTitle: story.SourceTitle,
+			Author:      storyAuthor(story, bylines),
 			AIProvider:  storyProvider,
 			AIModel:     storyModel,
+			Importance:  int(story.Importance),
+			PublishedAt: storyPublishedAt(story.PublishedDate),
 		}
 		if err := s.db.CreateStory(dbStory); err != nil {
 			slog.Error("Failed to create story", "error", err)
 			continue
 		}
-		storedCount++
+		stored = append(stored, *dbStory)
 	}
+	storedCount := len(stored)
+
+	s.saveSourceState(topic, hashes, validators)
 
 	// Clean up old stories (keep 3x display count)
 	s.db.DeleteOldStories(newsTopicID, topic.StoriesPerRefresh*3)
@@ -441,7 +1044,7 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 	s.db.UpdateNewsRefreshStatus(&models.NewsRefreshStatus{
 		NewsTopicID: newsTopicID,
 		LastRefresh: time.Now(),
-		NextRefresh: time.Now().Add(time.Duration(topic.RefreshIntervalMinutes) * time.Minute),
+		NextRefresh: database.NextRefreshSlot(time.Now(), topic.RefreshIntervalMinutes, topic.ScheduleOffsetMinutes),
 		Status:      "completed",
 	})
 	s.db.UpdateNewsTopicRefreshTime(newsTopicID)
@@ -453,7 +1056,147 @@ func (s *Scheduler) refreshNewsTopic(ctx context.Context, newsTopicID int64) {
 	})
 
 	slog.Info("News topic refreshed", "topic", topic.Name,
-		"stories", storedCount, "discarded_incomplete", len(stories)-storedCount)
+		"stories", storedCount, "discarded", len(stories)-storedCount)
+
+	s.notifyStoryWebhooks(topic, stored)
+}
+
+// maxStoriesPerDomain returns the news_max_per_domain setting: the most stories a
+// single refresh may keep from one source domain (0 for no limit).
+func (s *Scheduler) maxStoriesPerDomain() int {
+	v, _ := s.db.GetSetting("news_max_per_domain")
+	n, _ := strconv.Atoi(v)
+	return max(n, 0)
+}
+
+// domainLimiter caps how many stories are kept per source domain, since the
+// summarizer does not always follow the prompt's diversity rule.
+type domainLimiter struct {
+	limit  int
+	counts map[string]int
+}
+
+func newDomainLimiter(limit int) *domainLimiter {
+	return &domainLimiter{limit: limit, counts: make(map[string]int)}
+}
+
+// allow records a story from sourceURL and reports whether it is within the
+// limit. Stories without a recognizable domain are always allowed.
+func (d *domainLimiter) allow(sourceURL string) bool {
+	domain := storyDomain(sourceURL)
+	if d.limit <= 0 || domain == "" {
+		return true
+	}
+	if d.counts[domain] >= d.limit {
+		return false
+	}
+	d.counts[domain]++
+	return true
+}
+
+// storyDomain returns the lower-cased host of a story URL without any "www.".
+func storyDomain(rawURL string) string {
+	u, err := url.Parse(strings.TrimSpace(rawURL))
+	if err != nil {
+		return ""
+	}
+	return strings.TrimPrefix(strings.ToLower(u.Hostname()), "www.")
+}
+
+// maxSourceNameLen caps a discovered source's name, in characters, so a rambling
+// name from the AI doesn't break the sources list.
+const maxSourceNameLen = 80
+
+// sourceName normalizes the name the AI gave a discovered source: control and
+// invisible formatting characters are removed, whitespace is collapsed, and long
+// names are cut at a word boundary. An empty name falls back to the URL's domain.
+func sourceName(name, rawURL string) string {
+	name = strings.Map(func(r rune) rune {
+		switch {
+		case unicode.IsControl(r):
+			return ' '
+		case unicode.Is(unicode.Cf, r):
+			return -1
+		}
+		return r
+	}, name)
+	name = strings.Join(strings.Fields(name), " ")
+	if name == "" {
+		return storyDomain(rawURL)
+	}
+	if utf8.RuneCountInString(name) > maxSourceNameLen {
+		cut := string([]rune(name)[:maxSourceNameLen-1])
+		if i := strings.LastIndex(cut, " "); i > len(cut)/2 {
+			cut = cut[:i]
+		}
+		name = strings.TrimRight(cut, " ,;:-–—") + "…"
+	}
+	return name
+}
+
+// screenStory cleans up a summarized story and applies the topic's length rules.
+// It returns the story, sanitized and truncated if enforceMax applies, and a
+// non-empty reason if the story should be discarded.
+func screenStory(story ai.SummarizedStory, topic models.NewsTopic, enforceMax bool, cleanup []string) (ai.SummarizedStory, string) {
+	story.Title = ai.SanitizeContent(story.Title, cleanup)
+	story.Summary = ai.SanitizeContent(story.Summary, cleanup)
+	// Quotes are shown verbatim inside quotation marks, so only the model's own
+	// wrapping quotes and stray whitespace are removed
+	story.Quote = ai.SanitizeContent(story.Quote, []string{ai.CleanupQuotes, ai.CleanupWhitespace})
+	if !topic.IncludeQuotes {
+		story.Quote = ""
+	}
+	story.Importance = min(max(story.Importance, 0), 10)
+	if enforceMax && topic.SummaryMaxWords > 0 {
+		truncated, ok := ai.TruncateToWords(story.Summary, topic.SummaryMaxWords)
+		if !ok {
+			return story, "over max words"
+		}
+		story.Summary = truncated
+	}
+	if !ai.IsCompleteSentence(story.Summary, topic.SummaryMinWords) {
+		return story, "incomplete summary"
+	}
+	return story, ""
+}
+
+// collectBylines merges the scraped sources' article bylines into one map keyed
+// by bylineKey.
+func collectBylines(scraped []ai.ScrapedContent) map[string]string {
+	bylines := make(map[string]string)
+	for _, sc := range scraped {
+		for link, author := range sc.Bylines {
+			bylines[bylineKey(link)] = author
+		}
+	}
+	return bylines
+}
+
+// bylineKey normalizes an article link so the summarizer's copy of it matches
+// the feed's despite a trailing slash or "www." prefix.
+func bylineKey(link string) string {
+	link = strings.TrimRight(strings.TrimSpace(link), "/")
+	return strings.Replace(link, "://www.", "://", 1)
+}
+
+// storyAuthor returns a story's byline. The feed's own byline for the story's
+// link is preferred over the summarizer's copy, which may be garbled.
+func storyAuthor(story ai.SummarizedStory, bylines map[string]string) string {
+	if author, ok := bylines[bylineKey(story.SourceURL)]; ok {
+		return author
+	}
+	return strings.TrimSpace(story.Author)
+}
+
+// storyPublishedAt parses the article date the summarizer reported for a story.
+// Missing, malformed, or future dates return the zero time, so the story is
+// dated when it was stored instead.
+func storyPublishedAt(date string) time.Time {
+	t, err := time.Parse("2006-01-02", strings.TrimSpace(date))
+	if err != nil || t.After(time.Now()) {
+		return time.Time{}
+	}
+	return t
 }
 
 func (s *Scheduler) discoverNewsSources(ctx context.Context, newsTopicID int64) error {
@@ -467,46 +1210,62 @@ func (s *Scheduler) discoverNewsSources(ctx context.Context, newsTopicID int64)
 	// Mine Reddit subreddits for frequently-shared external sources
 	communityDomains := s.mineRedditDomains(ctx, newsTopicID, topic.Name, topic.Description)
 
-	discoverCtx, discoverCancel := context.WithTimeout(ctx, s.aiTimeout(topic.AIProvider, 5*time.Minute, 15*time.Minute))
-	defer discoverCancel()
-
-	sources, _, _, _, err := s.ai.DiscoverSources(discoverCtx, ai.DiscoverOpts{
+	opts := ai.DiscoverOpts{
 		TopicName:            topic.Name,
 		Description:          topic.Description,
 		SourcingInstructions: sourcingInstr,
 		AIProvider:           topic.AIProvider,
+		DiscoveryProvider:    topic.DiscoveryProvider,
 		IsNiche:              topic.IsNiche,
 		CommunityDomains:     communityDomains,
-	})
+	}
+
+	discoverCtx, discoverCancel := context.WithTimeout(ctx, s.discoveryTimeout(opts))
+	defer discoverCancel()
+	discoverCtx, spend := ai.TrackSpend(discoverCtx)
+
+	sources, tokensUsed, providerName, modelName, err := s.ai.DiscoverSources(discoverCtx, opts)
+	s.logNewsUsage(newsTopicID, spend, tokensUsed, providerName, modelName, err)
 	if err != nil {
+		// A missing API key is a setup problem, not a topic without sources
+		var mk *ai.MissingKeyError
+		if !errors.As(err, &mk) {
+			s.flagNeedsAttention(topic)
+		}
 		return fmt.Errorf("discover sources: %w", err)
 	}
 
 	// Clear existing AI sources and add new ones
 	s.db.ClearAINewsSourcesForTopic(newsTopicID)
 
-	var accepted int
+	var candidates []ai.DiscoveredSource
 	for _, source := range sources {
 		if err := scraper.ValidateURL(source.URL); err != nil {
 			slog.Debug("Skipping invalid source URL", "url", source.URL, "error", err)
 			continue
 		}
+		candidates = append(candidates, source)
+	}
 
-		// Validate source: test-scrape + RSS auto-discovery
-		result := s.scraper.ValidateSource(ctx, source.URL, source.Name)
+	// Validate sources: test-scrape + RSS auto-discovery
+	var accepted int
+	for _, result := range s.scraper.ValidateSources(ctx, candidates) {
 		if !result.OK {
 			slog.Info("Rejected news source (validation failed)",
-				"url", source.URL, "name", source.Name, "reason", result.Reason)
+				"url", result.URL, "name", result.Name, "reason", result.Reason)
 			continue
 		}
 
-		finalURL := source.URL
+		finalURL := result.URL
 		if result.FeedURL != "" {
-			slog.Info("Discovered RSS feed for source", "original", source.URL, "rss", result.FeedURL)
+			slog.Info("Discovered RSS feed for source", "original", result.URL, "rss", result.FeedURL)
 			finalURL = result.FeedURL
+		} else if result.SitemapURL != "" {
+			slog.Info("Using sitemap for source without a feed", "original", result.URL, "sitemap", result.SitemapURL)
+			finalURL = result.SitemapURL
 		}
 
-		if _, err := s.db.AddNewsSource(newsTopicID, finalURL, source.Name, false); err != nil {
+		if _, err := s.db.AddNewsSource(newsTopicID, finalURL, sourceName(result.Name, result.URL), false); err != nil {
 			slog.Error("Failed to add news source", "error", err)
 			continue
 		}
@@ -514,9 +1273,48 @@ func (s *Scheduler) discoverNewsSources(ctx context.Context, newsTopicID int64)
 	}
 
 	slog.Info("Discovered news sources", "topic", topic.Name, "discovered", len(sources), "accepted", accepted)
+	if accepted == 0 {
+		s.flagNeedsAttention(topic)
+	} else if topic.NeedsAttention {
+		s.db.ClearNewsTopicAttention(newsTopicID)
+	}
 	return nil
 }
 
+// flagNeedsAttention marks a news topic whose source discovery found nothing
+// usable and pauses automatic discovery for news_discovery_cooldown_hours, so a
+// topic without viable sources isn't rediscovered (and paid for) every refresh.
+func (s *Scheduler) flagNeedsAttention(topic models.NewsTopic) {
+	var until *time.Time
+	if cooldown := s.discoveryCooldown(); cooldown > 0 {
+		t := time.Now().Add(cooldown)
+		until = &t
+	}
+	if err := s.db.SetNewsTopicNeedsAttention(topic.ID, until); err != nil {
+		slog.Error("Failed to flag news topic", "topic", topic.Name, "error", err)
+		return
+	}
+	slog.Warn("News topic needs attention: discovery found no working sources",
+		"topic", topic.Name, "discovery_paused_until", until)
+}
+
+// discoveryCooldown returns the news_discovery_cooldown_hours setting: how long
+// automatic discovery pauses after finding no working sources (0 for no pause).
+func (s *Scheduler) discoveryCooldown() time.Duration {
+	v, _ := s.db.GetSetting("news_discovery_cooldown_hours")
+	n, _ := strconv.Atoi(v)
+	return time.Duration(max(n, 0)) * time.Hour
+}
+
+// inSourceGrace reports whether a source is younger than the source_grace_hours
+// setting. Sources in their grace period still count failures but are not
+// auto-removed, so one that happens to be down when first added gets a fair chance.
+func (s *Scheduler) inSourceGrace(src models.NewsSource) bool {
+	v, _ := s.db.GetSetting("source_grace_hours")
+	n, _ := strconv.Atoi(v)
+	return n > 0 && time.Since(src.CreatedAt) < time.Duration(n)*time.Hour
+}
+
 // replaceRemovedSources discovers new sources to replace ones that were auto-removed due to failures.
 func (s *Scheduler) replaceRemovedSources(ctx context.Context, newsTopicID int64, count int) {
 	topic, err := s.db.GetNewsTopic(newsTopicID)
@@ -534,16 +1332,21 @@ func (s *Scheduler) replaceRemovedSources(ctx context.Context, newsTopicID int64
 		existingURLs[src.URL] = true
 	}
 
-	replaceCtx, replaceCancel := context.WithTimeout(ctx, s.aiTimeout(topic.AIProvider, 5*time.Minute, 15*time.Minute))
-	defer replaceCancel()
-
-	discovered, _, _, _, err := s.ai.DiscoverSources(replaceCtx, ai.DiscoverOpts{
+	opts := ai.DiscoverOpts{
 		TopicName:            topic.Name,
 		Description:          topic.Description,
 		SourcingInstructions: sourcingInstr,
 		AIProvider:           topic.AIProvider,
+		DiscoveryProvider:    topic.DiscoveryProvider,
 		IsNiche:              topic.IsNiche,
-	})
+	}
+
+	replaceCtx, replaceCancel := context.WithTimeout(ctx, s.discoveryTimeout(opts))
+	defer replaceCancel()
+	replaceCtx, spend := ai.TrackSpend(replaceCtx)
+
+	discovered, tokensUsed, providerName, modelName, err := s.ai.DiscoverSources(replaceCtx, opts)
+	s.logNewsUsage(newsTopicID, spend, tokensUsed, providerName, modelName, err)
 	if err != nil {
 		slog.Error("Failed to discover replacement sources", "topic", topic.Name, "error", err)
 		return
@@ -572,9 +1375,11 @@ func (s *Scheduler) replaceRemovedSources(ctx context.Context, newsTopicID int64
 		finalURL := source.URL
 		if result.FeedURL != "" {
 			finalURL = result.FeedURL
+		} else if result.SitemapURL != "" {
+			finalURL = result.SitemapURL
 		}
 
-		if _, err := s.db.AddNewsSource(newsTopicID, finalURL, source.Name, false); err != nil {
+		if _, err := s.db.AddNewsSource(newsTopicID, finalURL, sourceName(source.Name, source.URL), false); err != nil {
 			slog.Error("Failed to add replacement source", "error", err)
 			continue
 		}
@@ -657,8 +1462,23 @@ func (s *Scheduler) mineRedditDomains(ctx context.Context, newsTopicID int64, to
 	return domains
 }
 
+// capSources limits a refresh to the topic's max_sources_per_refresh, picking the
+// least recently scraped sources so the rest are rotated in on later refreshes.
+func (s *Scheduler) capSources(topic models.NewsTopic, sources []models.NewsSource) []models.NewsSource {
+	if topic.MaxSourcesPerRefresh <= 0 || len(sources) <= topic.MaxSourcesPerRefresh {
+		return sources
+	}
+	picked, err := s.db.NextSourcesForRefresh(topic.ID, topic.MaxSourcesPerRefresh)
+	if err != nil {
+		slog.Warn("Failed to pick sources for refresh, scraping all", "topic", topic.Name, "error", err)
+		return sources
+	}
+	return picked
+}
+
 func (s *Scheduler) handleNewsRefreshError(newsTopicID int64, err error) {
 	slog.Error("News refresh error", "topic_id", newsTopicID, "error", err)
+	s.pauseForMissingKey(topicKey("news", newsTopicID), err)
 	s.db.UpdateNewsRefreshStatus(&models.NewsRefreshStatus{
 		NewsTopicID:  newsTopicID,
 		NextRefresh:  time.Now().Add(5 * time.Minute),
@@ -667,6 +1487,84 @@ func (s *Scheduler) handleNewsRefreshError(newsTopicID int64, err error) {
 	})
 }
 
+// handleNoRelevantContent records a refresh where sources were scraped but the
+// summarizer returned no stories. It is logged as a no_relevant_content error, but
+// the topic keeps its normal schedule, since retrying sooner would see the same content.
+func (s *Scheduler) handleNoRelevantContent(topic models.NewsTopic, start time.Time, scrapedSources int) {
+	err := fmt.Errorf("no relevant content: summarizer returned no stories from %d scraped sources", scrapedSources)
+	slog.Warn("News refresh found no relevant content", "topic", topic.Name, "scraped_sources", scrapedSources)
+	s.db.UpdateNewsRefreshStatus(&models.NewsRefreshStatus{
+		NewsTopicID:  topic.ID,
+		LastRefresh:  time.Now(),
+		NextRefresh:  database.NextRefreshSlot(time.Now(), topic.RefreshIntervalMinutes, topic.ScheduleOffsetMinutes),
+		Status:       "failed",
+		ErrorMessage: err.Error(),
+	})
+	s.db.UpdateNewsTopicRefreshTime(topic.ID)
+	s.logNewsRefreshError(topic, start, err)
+}
+
+// handleUnchangedSources completes a news refresh whose sources all returned the
+// content summarized last time, without calling the AI. The topic keeps its
+// normal schedule.
+func (s *Scheduler) handleUnchangedSources(topic models.NewsTopic, start time.Time) {
+	slog.Info("News refresh skipped: no source changes", "topic", topic.Name)
+	s.db.UpdateNewsRefreshStatus(&models.NewsRefreshStatus{
+		NewsTopicID: topic.ID,
+		LastRefresh: time.Now(),
+		NextRefresh: database.NextRefreshSlot(time.Now(), topic.RefreshIntervalMinutes, topic.ScheduleOffsetMinutes),
+		Status:      "completed",
+	})
+	s.db.UpdateNewsTopicRefreshTime(topic.ID)
+	s.db.LogRefresh(models.RefreshLog{
+		TopicType: "news", TopicID: topic.ID, TopicName: topic.Name,
+		Status: "success", ErrorType: "no_source_changes",
+		DurationMs: time.Since(start).Milliseconds(),
+	})
+}
+
+// feedValidators are the ETag and Last-Modified headers a source's feed returned.
+type feedValidators struct {
+	etag, lastModified string
+}
+
+// saveSourceState records the hashes of the content a refresh summarized, and
+// the feed validators that came with it, so the next refresh can tell whether
+// anything changed. Validators are only saved once their content has been
+// summarized, or a failed refresh would leave the feed's changes unseen.
+func (s *Scheduler) saveSourceState(topic models.NewsTopic, hashes map[int64]string, validators map[int64]feedValidators) {
+	if err := s.db.SetNewsSourceContentHashes(hashes); err != nil {
+		slog.Warn("Failed to record source content hashes", "topic", topic.Name, "error", err)
+	}
+	for id, v := range validators {
+		if err := s.db.SetNewsSourceValidators(id, v.etag, v.lastModified); err != nil {
+			slog.Warn("Failed to record feed validators", "topic", topic.Name, "error", err)
+		}
+	}
+}
+
+// contentHash returns the hex SHA-256 of scraped content.
+func contentHash(content string) string {
+	sum := sha256.Sum256([]byte(content))
+	return hex.EncodeToString(sum[:])
+}
+
+// logNewsUsage records the tokens and estimated cost of a news topic's
+// summarization or source discovery in the api_usage_log table.
+func (s *Scheduler) logNewsUsage(newsTopicID int64, spend *ai.Spend, tokensUsed int, provider, model string, err error) {
+	logEntry := models.APIUsageLog{
+		NewsTopicID:   &newsTopicID,
+		TokensUsed:    tokensUsed,
+		EstimatedCost: spend.USD(),
+		AIProvider:    provider,
+		AIModel:       model,
+	}
+	if err != nil {
+		logEntry.ErrorMessage = err.Error()
+	}
+	s.db.LogAPIUsage(logEntry)
+}
+
 // logNewsRefreshError logs a news refresh error to the refresh_log table.
 func (s *Scheduler) logNewsRefreshError(topic models.NewsTopic, start time.Time, err error) {
 	s.db.LogRefresh(models.RefreshLog{
@@ -675,6 +1573,7 @@ func (s *Scheduler) logNewsRefreshError(topic models.NewsTopic, start time.Time,
 		DurationMs: time.Since(start).Milliseconds(),
 		AIProvider: topic.AIProvider,
 	})
+	s.noteAuthFailure(err)
 }
 
 // classifyError categorizes an error into a descriptive type for the refresh log.
@@ -691,6 +1590,8 @@ func classifyError(err error) string {
 		return "model_not_found"
 	case strings.Contains(msg, "no sources available"):
 		return "no_sources"
+	case strings.Contains(msg, "no relevant content"):
+		return "no_relevant_content"
 	case strings.Contains(msg, "failed to scrape any content") || strings.Contains(msg, "insufficient content"):
 		return "no_content"
 	case strings.Contains(msg, "discover sources"):
@@ -701,7 +1602,7 @@ func classifyError(err error) string {
 		return "parse_error"
 	case strings.Contains(msg, "status 429") || strings.Contains(msg, "rate limit"):
 		return "rate_limited"
-	case strings.Contains(msg, "status 401") || strings.Contains(msg, "status 403") || strings.Contains(msg, "API key"):
+	case isAuthError(err):
 		return "auth_error"
 	case strings.Contains(msg, "status 400"):
 		return "bad_request"
@@ -722,16 +1623,22 @@ func classifyError(err error) string {
 	}
 }
 
-// RefreshNewsNow triggers an immediate news topic refresh.
-func (s *Scheduler) RefreshNewsNow(ctx context.Context, newsTopicID int64) {
+// RefreshNewsNow starts an immediate news topic refresh in the background.
+// The topic lock is acquired before returning, so the status reliably reflects
+// whether this call started the refresh or one was already running.
+func (s *Scheduler) RefreshNewsNow(ctx context.Context, newsTopicID int64) RefreshStatus {
 	key := topicKey("news", newsTopicID)
 	mu, ok := s.lockTopic(key)
 	if !ok {
-		slog.Warn("News topic is already being refreshed", "topic_id", newsTopicID)
-		return
+		slog.Debug("News topic is already being refreshed", "topic_id", newsTopicID)
+		return RefreshAlreadyRunning
 	}
-	defer mu.Unlock()
-	s.safeRefreshNewsTopic(ctx, newsTopicID)
+	s.keyWait.Delete(key) // a manual refresh retries even while waiting for a key
+	go func() {
+		defer mu.Unlock()
+		s.safeRefreshNewsTopic(ctx, newsTopicID, nil)
+	}()
+	return RefreshStarted
 }
 
 // DiscoverSourcesNow triggers immediate source discovery for a news topic.
@@ -745,8 +1652,23 @@ func (s *Scheduler) DiscoverSourcesNow(ctx context.Context, newsTopicID int64) e
 	return s.discoverNewsSources(ctx, newsTopicID)
 }
 
-func (s *Scheduler) getExistingTrigrams(topicID int64) []similarity.StoredTrigrams {
-	dbTrigrams, err := s.db.GetFactTrigramsForTopic(topicID)
+// getExistingTrigrams returns the facts a topic's new facts are checked
+// against: the topic's own, or with the dedup_scope setting "global", those of
+// every active topic. A manually refreshed inactive topic still includes its
+// own facts. The trigrams are parsed once here rather than for every check.
+func (s *Scheduler) getExistingTrigrams(topic models.Topic) []similarity.StoredTrigrams {
+	var dbTrigrams []database.StoredTrigrams
+	var err error
+	if scope, _ := s.db.GetSetting("dedup_scope"); scope == "global" {
+		dbTrigrams, err = s.db.GetAllFactTrigrams()
+		if err == nil && !topic.IsActive {
+			var own []database.StoredTrigrams
+			own, err = s.db.GetFactTrigramsForTopic(topic.ID)
+			dbTrigrams = append(dbTrigrams, own...)
+		}
+	} else {
+		dbTrigrams, err = s.db.GetFactTrigramsForTopic(topic.ID)
+	}
 	if err != nil {
 		slog.Error("Failed to get existing trigrams", "error", err)
 		return nil
@@ -755,9 +1677,11 @@ func (s *Scheduler) getExistingTrigrams(topicID int64) []similarity.StoredTrigra
 	result := make([]similarity.StoredTrigrams, len(dbTrigrams))
 	for i, dt := range dbTrigrams {
 		result[i] = similarity.StoredTrigrams{
-			ID:       dt.ID,
-			Trigrams: dt.Trigrams,
+			ID:        dt.ID,
+			Trigrams:  dt.Trigrams,
+			Embedding: similarity.EmbeddingFromJSON(dt.Embedding),
 		}
 	}
+	s.sim.Prepare(result)
 	return result
 }
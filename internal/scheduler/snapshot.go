@@ -0,0 +1,49 @@
+package scheduler
+
+import (
+	"log/slog"
+	"strconv"
+	"time"
+)
+
+// SnapshotWriter writes static snapshots of the current stories to a
+// directory, returning how many topics it wrote. The server implements it,
+// since it owns the templates the pages are rendered with.
+type SnapshotWriter interface {
+	WriteSnapshots(dir string, now time.Time) (int, error)
+}
+
+// SetSnapshotWriter sets what writes story snapshots. Without one, the
+// snapshot_dir setting has no effect.
+func (s *Scheduler) SetSnapshotWriter(w SnapshotWriter) {
+	s.snapshots = w
+}
+
+// writeSnapshotsIfDue writes story snapshots to the snapshot_dir setting once
+// every snapshot_interval_minutes, starting with the first tick after startup.
+// A failed snapshot waits for the next interval rather than retrying each tick.
+func (s *Scheduler) writeSnapshotsIfDue(now time.Time) {
+	if s.snapshots == nil {
+		return
+	}
+	dir, _ := s.db.GetSetting("snapshot_dir")
+	if dir == "" {
+		return
+	}
+	v, _ := s.db.GetSetting("snapshot_interval_minutes")
+	minutes, err := strconv.Atoi(v)
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	if !s.lastSnapshot.IsZero() && now.Sub(s.lastSnapshot) < time.Duration(minutes)*time.Minute {
+		return
+	}
+	s.lastSnapshot = now
+
+	n, err := s.snapshots.WriteSnapshots(dir, now)
+	if err != nil {
+		slog.Error("Failed to write story snapshots", "dir", dir, "error", err)
+		return
+	}
+	slog.Info("Wrote story snapshots", "dir", dir, "topics", n)
+}
@@ -0,0 +1,157 @@
+package scheduler
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// webhookAttempts is how many times a story webhook is tried before the
+// delivery is given up; webhookRetryDelay is the wait before the first retry,
+// doubling after each.
+const (
+	webhookAttempts   = 3
+	webhookRetryDelay = 5 * time.Second
+)
+
+// storyWebhookPayload is the JSON body POSTed to a news topic's webhooks.
+type storyWebhookPayload struct {
+	Event     string         `json:"event"`
+	TopicID   int64          `json:"topic_id"`
+	TopicName string         `json:"topic_name"`
+	Stories   []webhookStory `json:"stories"`
+	Time      time.Time      `json:"time"`
+}
+
+type webhookStory struct {
+	Title   string `json:"title"`
+	Summary string `json:"summary"`
+	URL     string `json:"url,omitempty"`
+}
+
+// notifyStoryWebhooks sends a news topic's newly stored stories to each of its
+// webhooks. Deliveries run in the background through notify, so a slow or
+// failing endpoint never holds up the refresh.
+func (s *Scheduler) notifyStoryWebhooks(topic models.NewsTopic, stories []models.Story) {
+	if len(stories) == 0 {
+		return
+	}
+	hooks, err := s.db.ListWebhooksForNewsTopic(topic.ID)
+	if err != nil {
+		slog.Error("Failed to list webhooks", "topic", topic.Name, "error", err)
+		return
+	}
+	if len(hooks) == 0 {
+		return
+	}
+
+	payload := storyWebhookPayload{
+		Event:     "stories_published",
+		TopicID:   topic.ID,
+		TopicName: topic.Name,
+		Time:      time.Now().UTC(),
+	}
+	for _, st := range stories {
+		ws := webhookStory{Title: st.Title, Summary: st.Summary}
+		if topic.ShowSourceLink {
+			ws.URL = st.SourceURL
+		}
+		payload.Stories = append(payload.Stories, ws)
+	}
+	body, err := json.Marshal(payload)
+	if err != nil {
+		slog.Error("Failed to encode story webhook", "error", err)
+		return
+	}
+	apiKey, _ := s.db.GetSetting("api_key")
+	secret := WebhookSecret(apiKey, topic.ID)
+
+	for _, hook := range hooks {
+		s.notify(func(ctx context.Context) {
+			lastError := ""
+			if err := deliverWebhook(ctx, hook.URL, body, secret, webhookRetryDelay); err != nil {
+				slog.Warn("Failed to deliver story webhook", "topic", topic.Name, "url", hook.URL, "error", err)
+				lastError = err.Error()
+			} else {
+				slog.Info("Sent story webhook", "topic", topic.Name, "url", hook.URL, "stories", len(stories))
+			}
+			if err := s.db.SetWebhookResult(hook.ID, lastError); err != nil {
+				slog.Error("Failed to save webhook result", "id", hook.ID, "error", err)
+			}
+		})
+	}
+}
+
+// WebhookSecret returns the key a news topic's webhook requests are signed
+// with: the hex HMAC-SHA256 of "webhook:" and the topic ID, keyed with the API
+// key. Receivers are given this rather than the API key itself, so they can
+// check signatures without being able to read the API. It is "" when no API
+// key is set, and changes when the API key does.
+func WebhookSecret(apiKey string, newsTopicID int64) string {
+	if apiKey == "" {
+		return ""
+	}
+	mac := hmac.New(sha256.New, []byte(apiKey))
+	mac.Write([]byte("webhook:" + strconv.FormatInt(newsTopicID, 10)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// deliverWebhook POSTs body to url, retrying network errors, 429s, and 5xx
+// responses. With a signing secret, the X-Kibble-Signature header carries
+// "sha256=" and the hex HMAC-SHA256 of the body, so receivers can check the
+// request came from this Kibble. It gives up when ctx is canceled, including
+// while waiting to retry.
+func deliverWebhook(ctx context.Context, url string, body []byte, secret string, retryDelay time.Duration) error {
+	client := &http.Client{Timeout: 15 * time.Second}
+	var lastErr error
+	for attempt := range webhookAttempts {
+		if attempt > 0 {
+			timer := time.NewTimer(retryDelay << (attempt - 1))
+			select {
+			case <-ctx.Done():
+				timer.Stop()
+				return lastErr
+			case <-timer.C:
+			}
+		}
+		req, err := http.NewRequestWithContext(ctx, "POST", url, bytes.NewReader(body))
+		if err != nil {
+			return err
+		}
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set("User-Agent", "Kibble-Webhook/1.0")
+		if secret != "" {
+			mac := hmac.New(sha256.New, []byte(secret))
+			mac.Write(body)
+			req.Header.Set("X-Kibble-Signature", "sha256="+hex.EncodeToString(mac.Sum(nil)))
+		}
+
+		resp, err := client.Do(req)
+		if err != nil {
+			lastErr = err
+			if ctx.Err() != nil {
+				return lastErr
+			}
+			continue
+		}
+		resp.Body.Close()
+		if resp.StatusCode < 300 {
+			return nil
+		}
+		lastErr = fmt.Errorf("webhook returned status %d", resp.StatusCode)
+		if resp.StatusCode != http.StatusTooManyRequests && resp.StatusCode < 500 {
+			return lastErr
+		}
+	}
+	return lastErr
+}
@@ -0,0 +1,92 @@
+package scraper
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// Batch shares scrape results between the news topics refreshed in one
+// scheduler tick, so a URL referenced by several topics is fetched once and the
+// result handed to each of them. A Batch must not outlive the tick, or topics
+// would be given stale content.
+type Batch struct {
+	mu      sync.Mutex
+	entries map[string]*batchEntry
+	hits    int
+}
+
+// batchEntry is one URL's scrape. done is closed once content and err are set,
+// so topics that want the same URL meanwhile wait for the first scrape.
+type batchEntry struct {
+	done    chan struct{}
+	content *ai.ScrapedContent
+	err     error
+}
+
+// NewBatch returns an empty Batch.
+func NewBatch() *Batch {
+	return &Batch{entries: make(map[string]*batchEntry)}
+}
+
+// Hits returns how many scrapes the batch has saved so far.
+func (b *Batch) Hits() int {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	return b.hits
+}
+
+// SourceKey returns the key under which sources are considered the same URL:
+// lowercased, without trailing slashes.
+func SourceKey(rawURL string) string {
+	return strings.ToLower(strings.TrimRight(strings.TrimSpace(rawURL), "/"))
+}
+
+// scrape returns the batch's result for src, scraping it only if no other topic
+// in the batch has asked for the same URL and content focus with the same feed
+// validators and fetch settings. Validators are part of the key since a
+// conditional fetch can answer "not modified" for one topic's copy of the
+// source but not another's, and each copy's force-feed, timeout, and retry
+// settings decide how it is fetched.
+func (b *Batch) scrape(ctx context.Context, s *Scraper, src models.NewsSource, focus string) (*ai.ScrapedContent, error) {
+	key := fmt.Sprintf("%s\x00%s\x00%s\x00%s\x00%t\x00%d\x00%d", SourceKey(src.URL), focus,
+		src.ETag, src.LastModified, src.ForceFeed, src.TimeoutSeconds, src.MaxRetries)
+	b.mu.Lock()
+	entry, ok := b.entries[key]
+	if !ok {
+		entry = &batchEntry{done: make(chan struct{})}
+		b.entries[key] = entry
+	} else {
+		b.hits++
+	}
+	b.mu.Unlock()
+
+	if !ok {
+		func() {
+			// Waiting topics must be released even if the scrape panics
+			defer close(entry.done)
+			entry.err = fmt.Errorf("shared scrape of %s did not finish", src.URL)
+			entry.content, entry.err = s.scrapeSource(ctx, src, focus)
+		}()
+	} else {
+		select {
+		case <-entry.done:
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		}
+	}
+
+	if entry.err != nil {
+		return nil, entry.err
+	}
+	// Each topic keeps its own name for the source
+	content := *entry.content
+	if src.Name != "" {
+		content.SourceName = src.Name
+	}
+	return &content, nil
+}
@@ -0,0 +1,38 @@
+package scraper
+
+import "strings"
+
+// boilerplateMaxChars is the longest paragraph that can be dropped as
+// boilerplate. Longer text that happens to contain a phrase is real content.
+const boilerplateMaxChars = 300
+
+// boilerplatePhrases returns the scrape_boilerplate_phrases setting as
+// lowercased phrases, one per line. An empty setting turns filtering off.
+func (s *Scraper) boilerplatePhrases() []string {
+	if s.settings == nil {
+		return nil
+	}
+	v, _ := s.settings.GetSetting("scrape_boilerplate_phrases")
+	var phrases []string
+	for _, line := range strings.Split(v, "\n") {
+		if p := strings.ToLower(cleanText(line)); p != "" {
+			phrases = append(phrases, p)
+		}
+	}
+	return phrases
+}
+
+// isBoilerplate reports whether text, already cleaned, is a short paragraph
+// containing one of phrases, such as a newsletter prompt or cookie notice.
+func isBoilerplate(text string, phrases []string) bool {
+	if len(phrases) == 0 || len(text) > boilerplateMaxChars {
+		return false
+	}
+	lower := strings.ToLower(text)
+	for _, p := range phrases {
+		if strings.Contains(lower, p) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,104 @@
+package scraper
+
+import (
+	"encoding/xml"
+	"io"
+	"mime"
+	"strings"
+
+	"golang.org/x/net/html/charset"
+)
+
+// streamContentBudget stops incremental parsing once this many characters of item
+// text have been collected. It matches the cap applied in buildScrapedContent, so
+// reading further would only produce content that gets cut off anyway.
+const streamContentBudget = 50000
+
+// streamedFeed holds the items decoded incrementally from an RSS or Atom feed.
+type streamedFeed struct {
+	Title   string
+	Items   []rssItem
+	Entries []atomEntry
+}
+
+// newFeedDecoder returns an XML decoder that reads a feed as UTF-8 whatever its
+// encoding. A charset in the HTTP Content-Type wins, as the XML spec requires, and
+// the body is transcoded up front; otherwise an encoding declared in the XML
+// prolog (e.g. ISO-8859-1 or Windows-1252) is transcoded as the decoder reads it.
+func newFeedDecoder(r io.Reader, contentType string) *xml.Decoder {
+	if label := headerCharset(contentType); label != "" {
+		if tr, err := charset.NewReaderLabel(label, r); err == nil {
+			dec := xml.NewDecoder(tr)
+			// Already UTF-8, so ignore the prolog's (now stale) encoding
+			dec.CharsetReader = func(_ string, input io.Reader) (io.Reader, error) { return input, nil }
+			return dec
+		}
+	}
+	dec := xml.NewDecoder(r)
+	dec.CharsetReader = charset.NewReaderLabel
+	return dec
+}
+
+// headerCharset returns the charset parameter of a Content-Type header, or ""
+// when there is none or it is already UTF-8.
+func headerCharset(contentType string) string {
+	_, params, err := mime.ParseMediaType(contentType)
+	if err != nil {
+		return ""
+	}
+	label := strings.ToLower(strings.TrimSpace(params["charset"]))
+	if label == "utf-8" || label == "utf8" {
+		return ""
+	}
+	return label
+}
+
+// decodeFeedStream parses an RSS or Atom feed one item at a time instead of
+// unmarshalling the whole document. It stops early once streamContentBudget is
+// reached, and a syntax error partway through (e.g. a truncated document) keeps
+// the items decoded before it rather than failing the whole feed.
+func decodeFeedStream(r io.Reader, contentType string) streamedFeed {
+	var feed streamedFeed
+	dec := newFeedDecoder(r, contentType)
+	size := 0
+	sawItem := false
+
+	for size < streamContentBudget {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+
+		switch start.Name.Local {
+		case "title":
+			// The first title before any item belongs to the channel/feed itself
+			if !sawItem && feed.Title == "" {
+				var title string
+				if dec.DecodeElement(&title, &start) == nil {
+					feed.Title = title
+				}
+			}
+		case "item":
+			sawItem = true
+			var item rssItem
+			if err := dec.DecodeElement(&item, &start); err != nil {
+				return feed
+			}
+			feed.Items = append(feed.Items, item)
+			size += len(item.Title) + len(item.Description) + len(item.ContentEncoded)
+		case "entry":
+			sawItem = true
+			var entry atomEntry
+			if err := dec.DecodeElement(&entry, &start); err != nil {
+				return feed
+			}
+			feed.Entries = append(feed.Entries, entry)
+			size += len(entry.Title) + len(entry.Summary) + len(entry.Content)
+		}
+	}
+	return feed
+}
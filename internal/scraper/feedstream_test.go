@@ -0,0 +1,85 @@
+package scraper
+
+import (
+	"fmt"
+	"strings"
+	"testing"
+)
+
+func TestDecodeFeedStream(t *testing.T) {
+	rssItems := func(n int) string {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(&b, "<item><title>Story %d</title><link>https://example.com/%d</link><description>Summary %d</description></item>", i, i, i)
+		}
+		return b.String()
+	}
+	// Each entry carries 10,000 characters, so the content budget is spent after five
+	atomEntries := func(n int) string {
+		var b strings.Builder
+		for i := 1; i <= n; i++ {
+			fmt.Fprintf(&b, "<entry><title>Entry %d</title><summary>%s</summary></entry>", i, strings.Repeat("x", 10000))
+		}
+		return b.String()
+	}
+
+	tests := []struct {
+		name        string
+		doc         string
+		contentType string
+		wantTitle   string
+		wantItems   int
+		wantEntries int
+	}{
+		{
+			name:      "Complete RSS",
+			doc:       `<?xml version="1.0"?><rss><channel><title>Example News</title>` + rssItems(3) + `</channel></rss>`,
+			wantTitle: "Example News",
+			wantItems: 3,
+		},
+		{
+			name:      "Truncated RSS keeps the items before the cut",
+			doc:       `<?xml version="1.0"?><rss><channel><title>Example News</title>` + rssItems(2) + `<item><title>Story 3</title><descrip`,
+			wantTitle: "Example News",
+			wantItems: 2,
+		},
+		{
+			name:      "RSS cut off between items",
+			doc:       `<rss><channel><title>Example News</title>` + rssItems(4),
+			wantTitle: "Example News",
+			wantItems: 4,
+		},
+		{
+			name:        "Oversized Atom stops at the content budget",
+			doc:         `<feed xmlns="http://www.w3.org/2005/Atom"><title>Big Feed</title>` + atomEntries(20) + `</feed>`,
+			wantTitle:   "Big Feed",
+			wantEntries: 5,
+		},
+		{
+			name:        "Charset from the Content-Type header",
+			doc:         "<feed xmlns=\"http://www.w3.org/2005/Atom\"><title>Caf\xe9</title><entry><title>Entry</title></entry></feed>",
+			contentType: "application/atom+xml; charset=ISO-8859-1",
+			wantTitle:   "Café",
+			wantEntries: 1,
+		},
+		{
+			name: "Not a feed",
+			doc:  "<html><body>Nothing here</body></html>",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			feed := decodeFeedStream(strings.NewReader(tt.doc), tt.contentType)
+			if feed.Title != tt.wantTitle {
+				t.Errorf("Title = %q, want %q", feed.Title, tt.wantTitle)
+			}
+			if len(feed.Items) != tt.wantItems {
+				t.Errorf("got %d items, want %d", len(feed.Items), tt.wantItems)
+			}
+			if len(feed.Entries) != tt.wantEntries {
+				t.Errorf("got %d entries, want %d", len(feed.Entries), tt.wantEntries)
+			}
+		})
+	}
+}
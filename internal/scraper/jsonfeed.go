@@ -0,0 +1,139 @@
+package scraper
+
+import (
+	"cmp"
+	"encoding/json"
+	"strings"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// JSON Feed (https://www.jsonfeed.org) types. Version 1.1 lists authors in an
+// array; version 1 had a single author object, which is still read.
+type jsonFeed struct {
+	Version string         `json:"version"`
+	Title   string         `json:"title"`
+	Items   []jsonFeedItem `json:"items"`
+}
+
+type jsonFeedItem struct {
+	URL           string           `json:"url"`
+	ExternalURL   string           `json:"external_url"`
+	Title         string           `json:"title"`
+	ContentHTML   string           `json:"content_html"`
+	ContentText   string           `json:"content_text"`
+	Summary       string           `json:"summary"`
+	DatePublished string           `json:"date_published"`
+	DateModified  string           `json:"date_modified"`
+	Authors       []jsonFeedAuthor `json:"authors"`
+	Author        *jsonFeedAuthor  `json:"author"`
+}
+
+type jsonFeedAuthor struct {
+	Name string `json:"name"`
+}
+
+// isJSONContentType reports whether a Content-Type header names a JSON body,
+// such as application/feed+json or application/json.
+func isJSONContentType(contentType string) bool {
+	mediaType, _, _ := strings.Cut(strings.ToLower(contentType), ";")
+	mediaType = strings.TrimSpace(mediaType)
+	return mediaType == "application/json" || strings.HasSuffix(mediaType, "+json")
+}
+
+// parseJSONFeed decodes body as a JSON Feed document. It reports false for JSON
+// that is not a feed, recognized by the jsonfeed.org version URL.
+func parseJSONFeed(body []byte) (jsonFeed, bool) {
+	var feed jsonFeed
+	if err := json.Unmarshal(body, &feed); err != nil {
+		return jsonFeed{}, false
+	}
+	return feed, strings.HasPrefix(feed.Version, "https://jsonfeed.org/version/")
+}
+
+// formatJSONFeedItems formats JSON Feed items for the summarizer in the same
+// layout as formatRSSItems. Items without a title fall back to their summary
+// and are skipped when they have neither.
+func formatJSONFeedItems(source models.NewsSource, feedTitle string, items []jsonFeedItem, mode, focus string, parseAuthors, decode bool) *ai.ScrapedContent {
+	var content strings.Builder
+	bylines := make(map[string]string)
+	for _, item := range items {
+		title := cleanText(item.Title)
+		if title == "" {
+			title = cleanText(item.Summary)
+		}
+		if title == "" {
+			continue
+		}
+		link := item.URL
+		if link == "" {
+			link = item.ExternalURL
+		}
+		date := item.DatePublished
+		if date == "" {
+			date = item.DateModified
+		}
+		author := ""
+		if parseAuthors {
+			author = jsonFeedItemAuthor(item)
+			if author != "" && link != "" {
+				bylines[link] = author
+			}
+		}
+		if focus == ai.ContentFocusLinks {
+			desc := cmp.Or(item.Summary, item.ContentText, item.ContentHTML)
+			writeLinkPost(&content, title, link, date, author, desc, decode)
+			continue
+		}
+		content.WriteString("ARTICLE: ")
+		content.WriteString(title)
+		content.WriteString("\n")
+		if link != "" {
+			content.WriteString("LINK: ")
+			content.WriteString(link)
+			content.WriteString("\n")
+		}
+		if date != "" {
+			content.WriteString("DATE: ")
+			content.WriteString(date)
+			content.WriteString("\n")
+		}
+		if author != "" {
+			content.WriteString("AUTHOR: ")
+			content.WriteString(author)
+			content.WriteString("\n")
+		}
+		// Prefer the HTML body, then the plain-text one, then the summary
+		var body string
+		switch {
+		case item.ContentHTML != "":
+			body = formatFeedHTML(item.ContentHTML, mode, decode)
+		case item.ContentText != "":
+			body = cleanText(item.ContentText)
+		default:
+			body = cleanText(item.Summary)
+		}
+		if body != "" {
+			content.WriteString(body)
+			content.WriteString("\n\n")
+		}
+	}
+
+	return buildScrapedContent(source, feedTitle, content.String(), bylines)
+}
+
+// jsonFeedItemAuthor returns a JSON Feed item's authors' names joined with
+// commas, falling back to the version 1 author object.
+func jsonFeedItemAuthor(item jsonFeedItem) string {
+	var names []string
+	for _, a := range item.Authors {
+		if name := cleanText(a.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	if len(names) == 0 && item.Author != nil {
+		return cleanText(item.Author.Name)
+	}
+	return strings.Join(names, ", ")
+}
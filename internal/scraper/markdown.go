@@ -0,0 +1,140 @@
+package scraper
+
+import (
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+var blankLinesPattern = regexp.MustCompile(`\n{3,}`)
+
+// htmlToMarkdown converts common HTML tags from feed content into Markdown so
+// links, lists, and emphasis survive into the summarizer prompt. Unknown tags
+// are dropped and their text kept; script and style content is discarded.
+func htmlToMarkdown(s string) string {
+	var sb strings.Builder
+	var hrefs []string
+	skip := 0
+	opened := false // just wrote an opening marker; suppress the next leading space
+
+	z := html.NewTokenizer(strings.NewReader(s))
+	for {
+		tt := z.Next()
+		if tt == html.ErrorToken {
+			break
+		}
+		tok := z.Token()
+
+		switch tt {
+		case html.TextToken:
+			if skip > 0 {
+				continue
+			}
+			text := strings.Join(strings.Fields(tok.Data), " ")
+			if text == "" {
+				continue
+			}
+			// Keep a separating space when the source text had one
+			if !opened && (strings.HasPrefix(tok.Data, " ") || strings.HasPrefix(tok.Data, "\n")) {
+				writeSpace(&sb)
+			}
+			opened = false
+			sb.WriteString(text)
+			if strings.HasSuffix(tok.Data, " ") || strings.HasSuffix(tok.Data, "\n") {
+				sb.WriteString(" ")
+			}
+
+		case html.StartTagToken, html.SelfClosingTagToken:
+			switch tok.Data {
+			case "script", "style":
+				if tt == html.StartTagToken {
+					skip++
+				}
+			case "a":
+				href := ""
+				for _, attr := range tok.Attr {
+					if attr.Key == "href" {
+						href = attr.Val
+					}
+				}
+				hrefs = append(hrefs, href)
+				if href != "" {
+					writeSpace(&sb)
+					sb.WriteString("[")
+					opened = true
+				}
+			case "strong", "b":
+				sb.WriteString("**")
+				opened = true
+			case "em", "i":
+				sb.WriteString("*")
+				opened = true
+			case "li":
+				sb.WriteString("\n- ")
+			case "br":
+				sb.WriteString("\n")
+			case "p", "div", "ul", "ol", "blockquote":
+				sb.WriteString("\n\n")
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n\n" + strings.Repeat("#", int(tok.Data[1]-'0')) + " ")
+			}
+
+		case html.EndTagToken:
+			switch tok.Data {
+			case "script", "style":
+				if skip > 0 {
+					skip--
+				}
+			case "a":
+				if len(hrefs) == 0 {
+					continue
+				}
+				href := hrefs[len(hrefs)-1]
+				hrefs = hrefs[:len(hrefs)-1]
+				if href != "" {
+					trimTrailingSpace(&sb)
+					sb.WriteString("](" + href + ")")
+				}
+			case "strong", "b":
+				trimTrailingSpace(&sb)
+				sb.WriteString("**")
+			case "em", "i":
+				trimTrailingSpace(&sb)
+				sb.WriteString("*")
+			case "p", "div", "ul", "ol", "blockquote", "h1", "h2", "h3", "h4", "h5", "h6":
+				sb.WriteString("\n\n")
+			}
+		}
+	}
+
+	// Tidy up: trim each line and collapse runs of blank lines
+	lines := strings.Split(sb.String(), "\n")
+	for i, line := range lines {
+		lines[i] = strings.TrimSpace(line)
+	}
+	out := blankLinesPattern.ReplaceAllString(strings.Join(lines, "\n"), "\n\n")
+	return strings.TrimSpace(out)
+}
+
+// writeSpace adds a single space unless the builder is empty or already ends in whitespace.
+func writeSpace(sb *strings.Builder) {
+	str := sb.String()
+	if str == "" {
+		return
+	}
+	last := str[len(str)-1]
+	if last != ' ' && last != '\n' {
+		sb.WriteString(" ")
+	}
+}
+
+// trimTrailingSpace removes a trailing space so closing markers hug their text.
+func trimTrailingSpace(sb *strings.Builder) {
+	str := sb.String()
+	if strings.HasSuffix(str, " ") {
+		trimmed := strings.TrimRight(str, " ")
+		sb.Reset()
+		sb.WriteString(trimmed)
+	}
+}
@@ -0,0 +1,78 @@
+package scraper
+
+import (
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"golang.org/x/net/http/httpproxy"
+)
+
+// ParseProxyURL checks a scraper_proxy_url value. It returns nil for "" (no
+// proxy), or the parsed URL if it is an http, https, or socks5 URL with a host.
+func ParseProxyURL(raw string) (*url.URL, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return nil, nil
+	}
+	u, err := url.Parse(raw)
+	if err != nil {
+		return nil, fmt.Errorf("invalid proxy URL: %w", err)
+	}
+	switch u.Scheme {
+	case "http", "https", "socks5":
+	default:
+		return nil, fmt.Errorf("proxy URL must use http, https, or socks5")
+	}
+	if u.Host == "" {
+		return nil, fmt.Errorf("proxy URL must have a host")
+	}
+	return u, nil
+}
+
+// transport returns the round tripper feeds and pages are fetched with. When
+// scraper_proxy_url is set, requests go through that proxy, except to hosts
+// matched by scraper_no_proxy (a NO_PROXY-style list) and to localhost.
+// Otherwise it is http.DefaultTransport, which honors the HTTP_PROXY,
+// HTTPS_PROXY, and NO_PROXY environment variables.
+func (s *Scraper) transport() http.RoundTripper {
+	if s.settings == nil {
+		return http.DefaultTransport
+	}
+	proxyURL, _ := s.settings.GetSetting("scraper_proxy_url")
+	if proxyURL = strings.TrimSpace(proxyURL); proxyURL == "" {
+		return http.DefaultTransport
+	}
+	noProxy, _ := s.settings.GetSetting("scraper_no_proxy")
+
+	// The transport is kept while the settings are unchanged so connections
+	// to the proxy are reused between scrapes
+	s.proxyMu.Lock()
+	defer s.proxyMu.Unlock()
+	key := proxyURL + "\x00" + noProxy
+	if s.proxyTransport != nil && s.proxyKey == key {
+		return s.proxyTransport
+	}
+	if s.proxyTransport != nil {
+		s.proxyTransport.CloseIdleConnections()
+	}
+
+	proxyFunc := (&httpproxy.Config{
+		HTTPProxy:  proxyURL,
+		HTTPSProxy: proxyURL,
+		NoProxy:    noProxyList(noProxy),
+	}).ProxyFunc()
+	t := http.DefaultTransport.(*http.Transport).Clone()
+	t.Proxy = func(r *http.Request) (*url.URL, error) { return proxyFunc(r.URL) }
+	s.proxyTransport, s.proxyKey = t, key
+	return t
+}
+
+// noProxyList turns a scraper_no_proxy value, with hosts separated by commas,
+// spaces, or newlines, into the comma-separated form of NO_PROXY.
+func noProxyList(v string) string {
+	return strings.Join(strings.FieldsFunc(v, func(r rune) bool {
+		return r == ',' || r == ' ' || r == '\n' || r == '\r' || r == '\t'
+	}), ",")
+}
@@ -0,0 +1,105 @@
+package scraper
+
+import (
+	"bytes"
+	"fmt"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// Heuristics for pages that return text but scrape poorly: JavaScript-only
+// stubs, paywalls, and landing pages that are little more than navigation.
+// ValidateSource rejects such pages so discovery picks other sources.
+
+// jsStubMaxChars is the most scraped text a page showing an "enable
+// JavaScript" notice may have and still count as a JavaScript-only stub.
+const jsStubMaxChars = 2000
+
+// navLinkDensity is the share of a page's text inside links above which a page
+// with no article body counts as navigation.
+const navLinkDensity = 0.7
+
+// jsRequiredPhrases appear in the <noscript> notices of pages that render
+// their content with JavaScript.
+var jsRequiredPhrases = []string{
+	"enable javascript", "javascript is disabled", "javascript is required",
+	"requires javascript", "turn on javascript", "javascript must be enabled",
+	"javascript to be enabled", "enable js",
+}
+
+// paywallPhrases appear in the text of paywalled articles.
+var paywallPhrases = []string{
+	"subscribe to continue reading", "subscribe to read the full", "subscribe to read more",
+	"to continue reading, subscribe", "this article is for subscribers",
+	"this content is for subscribers", "subscribers only", "subscription required",
+	"you have reached your free article limit", "you've reached your free article limit",
+}
+
+// notFreeRe matches schema.org markup declaring an article not free to read.
+var notFreeRe = regexp.MustCompile(`(?i)"isAccessibleForFree"\s*:\s*"?false`)
+
+// pageProblem applies the checks to a page's HTML and its scraped text, taken
+// from the same response, and returns why scrapes of the page would be poor,
+// or "" if it looks fine.
+func pageProblem(body []byte, text string, boilerplate []string) string {
+	lowerText := strings.ToLower(text)
+	if notFreeRe.Match(body) {
+		return "paywalled: the page marks its article as not free to read"
+	}
+	for _, p := range paywallPhrases {
+		if strings.Contains(lowerText, p) {
+			return fmt.Sprintf("paywalled: the page says %q", p)
+		}
+	}
+
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+
+	if len(text) < jsStubMaxChars {
+		notice := strings.ToLower(noscriptText(doc))
+		for _, p := range jsRequiredPhrases {
+			if strings.Contains(notice, p) {
+				return fmt.Sprintf("needs JavaScript: the page asks to %q and only %d chars render without it", p, len(text))
+			}
+		}
+	}
+
+	if b := findElement(doc, "body"); b != nil && len(extractArticle(doc, boilerplate)) < readabilityMinChars {
+		if d := linkDensity(b); d >= navLinkDensity {
+			return fmt.Sprintf("mostly navigation: no article body found and %.0f%% of the page text is links", d*100)
+		}
+	}
+	return ""
+}
+
+// noscriptText returns the text of a page's <noscript> elements. The parser
+// keeps their contents as raw markup, so tags are stripped by parsing it again.
+func noscriptText(doc *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "noscript" {
+			for c := n.FirstChild; c != nil; c = c.NextSibling {
+				if c.Type != html.TextNode {
+					continue
+				}
+				if frag, err := html.Parse(strings.NewReader(c.Data)); err == nil {
+					sb.WriteString(nodeText(frag))
+				} else {
+					sb.WriteString(c.Data)
+				}
+				sb.WriteString(" ")
+			}
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+	return cleanText(sb.String())
+}
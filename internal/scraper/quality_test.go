@@ -0,0 +1,66 @@
+package scraper
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestPageProblem(t *testing.T) {
+	article := "<article>" + strings.Repeat("<p>The mission launched on schedule, and the crew reported that all systems were working as expected.</p>", 8) + "</article>"
+	articleText := strings.Repeat("The mission launched on schedule, and the crew reported that all systems were working as expected. ", 8)
+	nav := "<ul>" + strings.Repeat(`<li><a href="/section">Section link headline that is long enough</a></li>`, 80) + "</ul>"
+	blurbs := strings.Repeat("<p>A short teaser blurb for a story that is here, sixty chars.</p>", 6)
+
+	tests := []struct {
+		name string
+		html string
+		text string
+		want string // prefix of the reason; "" for no problem
+	}{
+		{
+			name: "Article",
+			html: "<html><body>" + article + "</body></html>",
+			text: articleText,
+		},
+		{
+			name: "Paywall phrase",
+			html: "<html><body>" + article + "<p>Subscribe to continue reading.</p></body></html>",
+			text: articleText + "Subscribe to continue reading.",
+			want: "paywalled",
+		},
+		{
+			name: "Not free to read",
+			html: `<html><head><script type="application/ld+json">{"@type":"NewsArticle","isAccessibleForFree": "False"}</script></head><body>` + article + "</body></html>",
+			text: articleText,
+			want: "paywalled",
+		},
+		{
+			name: "JavaScript stub",
+			html: `<html><body><noscript><p>Please enable JavaScript to view this site.</p></noscript><div id="root"></div><p>Loading the latest headlines for you now, one moment please.</p></body></html>`,
+			text: "Loading the latest headlines for you now, one moment please.",
+			want: "needs JavaScript",
+		},
+		{
+			name: "JavaScript notice on a full page",
+			html: `<html><body><noscript>Please enable JavaScript for comments.</noscript>` + article + "</body></html>",
+			text: strings.Repeat(articleText, 3),
+		},
+		{
+			name: "Navigation",
+			html: `<html><body><div class="list">` + nav + "</div><div>" + blurbs + "</div></body></html>",
+			text: strings.Repeat("x", 400),
+			want: "mostly navigation",
+		},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got := pageProblem([]byte(tt.html), tt.text, nil)
+			if tt.want == "" && got != "" {
+				t.Errorf("pageProblem() = %q, want no problem", got)
+			}
+			if tt.want != "" && !strings.HasPrefix(got, tt.want) {
+				t.Errorf("pageProblem() = %q, want a %q reason", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,295 @@
+package scraper
+
+import (
+	"bytes"
+	"regexp"
+	"strings"
+
+	"golang.org/x/net/html"
+)
+
+// readabilityMinChars is the least text the main-content extraction must find
+// to be used. Less usually means the scoring picked the wrong element, and the
+// selector-based scrape is used instead.
+const readabilityMinChars = 500
+
+var (
+	// unlikelyCandidate matches class and id values of page chrome that is
+	// skipped outright, unless maybeCandidate also matches
+	unlikelyCandidate = regexp.MustCompile(`(?i)banner|breadcrumb|combx|comment|community|consent|cookie|disqus|footer|header|menu|modal|\bnav|newsletter|pagination|popup|promo|related|remark|replies|share|shoutbox|sidebar|social|sponsor|subscribe|widget|\bads?\b|advert`)
+	maybeCandidate    = regexp.MustCompile(`(?i)article|body|column|content|main|story|entry|post|text`)
+	positiveClass     = regexp.MustCompile(`(?i)article|blog|body|content|entry|main|page|post|story|text`)
+	negativeClass     = regexp.MustCompile(`(?i)comment|cookie|footer|footnote|masthead|meta|newsletter|outbrain|promo|related|share|shoutbox|sidebar|sponsor|subscribe|widget`)
+)
+
+// skippedTags hold no article text.
+var skippedTags = map[string]bool{
+	"script": true, "style": true, "noscript": true, "nav": true, "header": true, "footer": true,
+	"aside": true, "form": true, "iframe": true, "svg": true, "button": true, "select": true,
+}
+
+// extractMainContent parses an HTML page and returns the text of its article
+// body, or "" if the page cannot be parsed.
+func extractMainContent(body []byte, boilerplate []string) string {
+	doc, err := html.Parse(bytes.NewReader(body))
+	if err != nil {
+		return ""
+	}
+	return extractArticle(doc, boilerplate)
+}
+
+// extractArticle finds the element of a parsed HTML page most likely to hold
+// the article body and returns its text, one paragraph per line. Each
+// paragraph adds to the score of its parent and, at half weight, its
+// grandparent: more for longer text and more commas. Scores are then scaled
+// down by the share of text inside links, so link lists and menus lose out to
+// prose. Sibling elements that also score well, such as an article split
+// across several divs, are included. Boilerplate paragraphs are dropped.
+func extractArticle(doc *html.Node, boilerplate []string) string {
+	scores := make(map[*html.Node]float64)
+	var candidates []*html.Node // in document order, so ties go to the first
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode {
+			if isUnlikelyNode(n) {
+				return
+			}
+			switch n.Data {
+			case "p", "pre", "td", "blockquote":
+				candidates = scoreParagraph(n, scores, candidates)
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(doc)
+
+	var top *html.Node
+	var topScore float64
+	for _, n := range candidates {
+		score := scores[n] * (1 - linkDensity(n))
+		scores[n] = score
+		if top == nil || score > topScore {
+			top, topScore = n, score
+		}
+	}
+	if top == nil {
+		return ""
+	}
+
+	var sb strings.Builder
+	threshold := max(10, topScore*0.2)
+	for sib := top.Parent.FirstChild; sib != nil; sib = sib.NextSibling {
+		if sib == top || isRelatedSibling(sib, scores, threshold) {
+			writeArticleText(&sb, sib, boilerplate)
+		}
+	}
+
+	text := sb.String()
+	if h1 := findElement(doc, "h1"); h1 != nil && !strings.Contains(text, "HEADLINE: ") {
+		if heading := cleanText(nodeText(h1)); heading != "" {
+			text = "HEADLINE: " + heading + "\n" + text
+		}
+	}
+	return strings.TrimSpace(text)
+}
+
+// scoreParagraph adds a paragraph's score to its parent and grandparent,
+// appending them to candidates when they are first scored.
+func scoreParagraph(n *html.Node, scores map[*html.Node]float64, candidates []*html.Node) []*html.Node {
+	text := cleanText(nodeText(n))
+	if len(text) < 25 {
+		return candidates
+	}
+	score := 1 + float64(strings.Count(text, ",")) + float64(min(len(text)/100, 3))
+
+	for ancestor, weight := n.Parent, 1.0; ancestor != nil && weight >= 0.5; ancestor, weight = ancestor.Parent, weight/2 {
+		if ancestor.Type != html.ElementNode {
+			break
+		}
+		if _, ok := scores[ancestor]; !ok {
+			scores[ancestor] = initialScore(ancestor)
+			candidates = append(candidates, ancestor)
+		}
+		scores[ancestor] += score * weight
+	}
+	return candidates
+}
+
+// initialScore favors elements that usually wrap articles and penalizes
+// lists and headings, then adjusts for hints in the class and id.
+func initialScore(n *html.Node) float64 {
+	var score float64
+	switch n.Data {
+	case "article", "main", "section", "div":
+		score = 5
+	case "pre", "td", "blockquote":
+		score = 3
+	case "address", "ol", "ul", "dl", "dd", "dt", "li", "form":
+		score = -3
+	case "h1", "h2", "h3", "h4", "h5", "h6", "th":
+		score = -5
+	}
+	for _, v := range []string{attr(n, "class"), attr(n, "id")} {
+		if v == "" {
+			continue
+		}
+		if negativeClass.MatchString(v) {
+			score -= 25
+		}
+		if positiveClass.MatchString(v) {
+			score += 25
+		}
+	}
+	return score
+}
+
+// isRelatedSibling reports whether a sibling of the top element belongs to the
+// article: it scored well itself, or it is a paragraph of prose.
+func isRelatedSibling(n *html.Node, scores map[*html.Node]float64, threshold float64) bool {
+	if n.Type != html.ElementNode || isUnlikelyNode(n) {
+		return false
+	}
+	if score, ok := scores[n]; ok && score >= threshold {
+		return true
+	}
+	if n.Data == "p" {
+		text := cleanText(nodeText(n))
+		return len(text) > 80 && linkDensity(n) < 0.25
+	}
+	return false
+}
+
+// isUnlikelyNode reports whether an element is page chrome rather than content.
+func isUnlikelyNode(n *html.Node) bool {
+	if skippedTags[n.Data] {
+		return true
+	}
+	if n.Data == "body" || n.Data == "article" || n.Data == "main" {
+		return false
+	}
+	hints := attr(n, "class") + " " + attr(n, "id")
+	return unlikelyCandidate.MatchString(hints) && !maybeCandidate.MatchString(hints)
+}
+
+// writeArticleText writes the paragraphs under n, one per line. Headings become
+// HEADLINE lines, and link-heavy or boilerplate paragraphs are skipped. Loose
+// text between blocks is gathered into paragraphs of its own.
+func writeArticleText(sb *strings.Builder, n *html.Node, boilerplate []string) {
+	var loose strings.Builder
+	flush := func() {
+		if text := cleanText(loose.String()); len(text) > 50 && !isBoilerplate(text, boilerplate) {
+			sb.WriteString(text)
+			sb.WriteString("\n")
+		}
+		loose.Reset()
+	}
+
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		switch n.Type {
+		case html.TextNode:
+			loose.WriteString(n.Data)
+			return
+		case html.ElementNode:
+			if isUnlikelyNode(n) {
+				return
+			}
+			switch n.Data {
+			case "h1", "h2", "h3", "h4", "h5", "h6":
+				flush()
+				if text := cleanText(nodeText(n)); len(text) > 10 && len(text) < 200 {
+					sb.WriteString("HEADLINE: ")
+					sb.WriteString(text)
+					sb.WriteString("\n")
+				}
+				return
+			case "p", "pre", "blockquote", "li":
+				flush()
+				text := cleanText(nodeText(n))
+				if text != "" && linkDensity(n) <= 0.5 && !isBoilerplate(text, boilerplate) {
+					sb.WriteString(text)
+					sb.WriteString("\n")
+				}
+				return
+			case "br":
+				flush()
+				return
+			case "div", "section", "article", "table", "tr", "ul", "ol":
+				flush()
+				defer flush()
+			}
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	flush()
+}
+
+// linkDensity returns the share of an element's text that is inside links.
+func linkDensity(n *html.Node) float64 {
+	total := len(cleanText(nodeText(n)))
+	if total == 0 {
+		return 0
+	}
+	var linked int
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.ElementNode && n.Data == "a" {
+			linked += len(cleanText(nodeText(n)))
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return float64(linked) / float64(total)
+}
+
+// nodeText returns the text under n, leaving out scripts and styles.
+func nodeText(n *html.Node) string {
+	var sb strings.Builder
+	var walk func(n *html.Node)
+	walk = func(n *html.Node) {
+		if n.Type == html.TextNode {
+			sb.WriteString(n.Data)
+			return
+		}
+		if n.Type == html.ElementNode && (n.Data == "script" || n.Data == "style" || n.Data == "noscript") {
+			return
+		}
+		for c := n.FirstChild; c != nil; c = c.NextSibling {
+			walk(c)
+		}
+	}
+	walk(n)
+	return sb.String()
+}
+
+// findElement returns the first element under n with the given tag, or nil.
+func findElement(n *html.Node, tag string) *html.Node {
+	if n.Type == html.ElementNode && n.Data == tag {
+		return n
+	}
+	for c := n.FirstChild; c != nil; c = c.NextSibling {
+		if found := findElement(c, tag); found != nil {
+			return found
+		}
+	}
+	return nil
+}
+
+// attr returns the value of an element's attribute, or "" if it has none.
+func attr(n *html.Node, key string) string {
+	for _, a := range n.Attr {
+		if a.Key == key {
+			return a.Val
+		}
+	}
+	return ""
+}
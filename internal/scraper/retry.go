@@ -0,0 +1,35 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/retry"
+)
+
+// timeout returns the request timeout for a source: its timeout_seconds, or
+// the scraper's default when that is 0.
+func (s *Scraper) timeout(source models.NewsSource) time.Duration {
+	if source.TimeoutSeconds > 0 {
+		return time.Duration(source.TimeoutSeconds) * time.Second
+	}
+	return s.requestTimeout
+}
+
+// scrapeRetryDelay returns the wait before retry number attempt+1.
+func scrapeRetryDelay(attempt int) time.Duration {
+	return retry.Backoff(retry.BaseDelay, attempt)
+}
+
+// isTransient reports whether a failed scrape may succeed if repeated: the
+// server returned 429 or a 5xx status, or the request timed out or never got a
+// response. Other failures, such as a 404 or a page with too little content,
+// would fail the same way again.
+func isTransient(err error) bool {
+	if errors.Is(err, ErrNotModified) || errors.Is(err, context.Canceled) {
+		return false
+	}
+	return retry.Transient(err)
+}
@@ -0,0 +1,33 @@
+package scraper
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net"
+	"testing"
+)
+
+func TestIsTransient(t *testing.T) {
+	tests := []struct {
+		err  error
+		want bool
+	}{
+		{fmt.Errorf("feed returned status 503 for https://example.com/feed"), true},
+		{fmt.Errorf("feed returned status 429 for https://example.com/feed"), true},
+		{fmt.Errorf("scrape error for https://example.com: Bad Gateway (status: 502)"), true},
+		{fmt.Errorf("scrape error for https://example.com: Not Found (status: 404)"), false},
+		{fmt.Errorf("feed returned status 403 for https://example.com/feed"), false},
+		{fmt.Errorf("fetch feed https://example.com/feed: %w", &net.OpError{Op: "dial", Err: errors.New("connection refused")}), true},
+		{fmt.Errorf("fetch feed https://example.com/feed: %w", context.DeadlineExceeded), true},
+		{fmt.Errorf("fetch feed https://example.com/feed: %w", context.Canceled), false},
+		{fmt.Errorf("scrape error for https://example.com: %w (status: 0)", &net.OpError{Op: "read", Err: errors.New("connection reset")}), true},
+		{ErrNotModified, false},
+		{fmt.Errorf("insufficient content scraped from https://example.com"), false},
+	}
+	for _, tt := range tests {
+		if got := isTransient(tt.err); got != tt.want {
+			t.Errorf("isTransient(%q) = %v, want %v", tt.err, got, tt.want)
+		}
+	}
+}
@@ -12,12 +12,16 @@ import (
 
 // DiscoverRSSFeed checks a web page for RSS/Atom feed <link> tags.
 // Returns the feed URL if found, or empty string if none discovered.
-func DiscoverRSSFeed(ctx context.Context, pageURL string) string {
+func (s *Scraper) DiscoverRSSFeed(ctx context.Context, pageURL string) string {
 	c := colly.NewCollector(
 		colly.UserAgent("Kibble/1.0 (RSS Discovery; +https://github.com/thinkscotty/kibble)"),
 		colly.MaxDepth(0),
 	)
 	c.SetRequestTimeout(10 * time.Second)
+	c.WithTransport(s.transport())
+	c.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("User-Agent", s.userAgentFor("Kibble/1.0 (RSS Discovery; +https://github.com/thinkscotty/kibble)"))
+	})
 
 	var feedURL string
 	var mu sync.Mutex
@@ -29,7 +33,7 @@ func DiscoverRSSFeed(ctx context.Context, pageURL string) string {
 			return // already found one
 		}
 		typ := strings.ToLower(e.Attr("type"))
-		if typ == "application/rss+xml" || typ == "application/atom+xml" {
+		if typ == "application/rss+xml" || typ == "application/atom+xml" || typ == "application/feed+json" {
 			href := e.Attr("href")
 			if href != "" {
 				feedURL = resolveURL(pageURL, href)
@@ -1,15 +1,20 @@
 package scraper
 
 import (
+	"bytes"
 	"context"
 	"encoding/xml"
+	"errors"
 	"fmt"
+	"html"
 	"io"
 	"log/slog"
 	"net/http"
 	"net/url"
+	"strconv"
 	"strings"
 	"sync"
+	"sync/atomic"
 	"time"
 
 	"github.com/gocolly/colly/v2"
@@ -18,14 +23,31 @@ import (
 	"github.com/thinkscotty/kibble/internal/reddit"
 )
 
+// SettingsGetter is a minimal interface so the scraper package does not import database.
+type SettingsGetter interface {
+	GetSetting(key string) (string, error)
+}
+
 // Scraper handles web scraping operations.
 type Scraper struct {
 	userAgent      string
 	requestTimeout time.Duration
 	parallelLimit  int
 	redditClient   *reddit.Client
+	settings       SettingsGetter
+
+	proxyMu        sync.Mutex
+	proxyTransport *http.Transport // used while scraper_proxy_url is set
+	proxyKey       string          // the proxy settings proxyTransport was built for
+
+	userAgentTurn atomic.Uint64 // rotates through scraper_user_agents
 }
 
+// ErrNotModified is returned for a feed that answered a conditional request
+// with 304 Not Modified: nothing has changed since the validators the source
+// recorded were sent.
+var ErrNotModified = errors.New("feed not modified")
+
 // ScrapeResult represents the result of scraping a single source.
 type ScrapeResult struct {
 	Source  models.NewsSource
@@ -33,43 +55,219 @@ type ScrapeResult struct {
 	Error   error
 }
 
-// New creates a new Scraper.
-func New() *Scraper {
+// New creates a new Scraper. Runtime options are read from sg on each scrape.
+func New(sg SettingsGetter) *Scraper {
 	return &Scraper{
 		userAgent:      "Kibble/1.0 (AI Facts & News Dashboard; +https://github.com/thinkscotty/kibble)",
 		requestTimeout: 30 * time.Second,
 		parallelLimit:  5,
 		redditClient:   reddit.New(),
+		settings:       sg,
+	}
+}
+
+// contentMode returns the feed_content_mode setting: "plain" or "markdown".
+func (s *Scraper) contentMode() string {
+	if s.settings == nil {
+		return "plain"
+	}
+	if v, _ := s.settings.GetSetting("feed_content_mode"); v == "markdown" {
+		return v
+	}
+	return "plain"
+}
+
+// parseAuthors reports whether feed item bylines are passed to the summarizer
+// (the feed_parse_authors setting, on unless set to "false").
+func (s *Scraper) parseAuthors() bool {
+	if s.settings == nil {
+		return true
+	}
+	v, _ := s.settings.GetSetting("feed_parse_authors")
+	return v != "false"
+}
+
+// decodeEntities reports whether HTML entities such as &amp; and &#8217; in
+// plain-text feed content are decoded into characters (the feed_decode_entities
+// setting, on unless set to "false").
+func (s *Scraper) decodeEntities() bool {
+	if s.settings == nil {
+		return true
+	}
+	v, _ := s.settings.GetSetting("feed_decode_entities")
+	return v != "false"
+}
+
+// defaultFeedMaxBytes is the feed body size read in one piece when
+// feed_max_bytes is unset or invalid.
+const defaultFeedMaxBytes = 1 << 20
+
+// feedMaxBytes returns the feed_max_bytes setting: the largest feed body that is
+// read whole and unmarshalled. Larger feeds are decoded incrementally.
+func (s *Scraper) feedMaxBytes() int64 {
+	if s.settings == nil {
+		return defaultFeedMaxBytes
+	}
+	v, _ := s.settings.GetSetting("feed_max_bytes")
+	if n, err := strconv.ParseInt(v, 10, 64); err == nil && n > 0 {
+		return n
 	}
+	return defaultFeedMaxBytes
 }
 
-// ScrapeSource scrapes content from a single source.
+// maxDepth returns the scrape_max_depth setting, the link depth the HTML
+// collector may follow from a source page. It is at least 1, since Colly
+// treats 0 as unlimited.
+func (s *Scraper) maxDepth() int {
+	if s.settings == nil {
+		return 1
+	}
+	v, _ := s.settings.GetSetting("scrape_max_depth")
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return n
+	}
+	return 1
+}
+
+// allowedDomains returns the hosts the collector may visit for a source: the
+// source's own host and its www/bare counterpart. Redirects elsewhere are refused.
+func allowedDomains(rawURL string) []string {
+	parsed, err := url.Parse(rawURL)
+	if err != nil || parsed.Hostname() == "" {
+		return nil
+	}
+	host := strings.ToLower(parsed.Hostname())
+	if bare, ok := strings.CutPrefix(host, "www."); ok {
+		return []string{host, bare}
+	}
+	return []string{host, "www." + host}
+}
+
+// ScrapeSource scrapes content from a single source, formatted as articles.
 func (s *Scraper) ScrapeSource(ctx context.Context, source models.NewsSource) (*ai.ScrapedContent, error) {
+	return s.scrapeSource(ctx, source, "")
+}
+
+// scrapeSource scrapes a single source, retrying transient failures up to the
+// source's max_retries times. focus is the topic's content focus;
+// ai.ContentFocusLinks formats feed and Reddit items as link posts, leading with
+// titles and scores and keeping only a short excerpt of each body.
+func (s *Scraper) scrapeSource(ctx context.Context, source models.NewsSource, focus string) (*ai.ScrapedContent, error) {
+	for attempt := 0; ; attempt++ {
+		content, err := s.scrapeSourceOnce(ctx, source, focus)
+		if err == nil || attempt >= source.MaxRetries || ctx.Err() != nil || !isTransient(err) {
+			return content, err
+		}
+
+		delay := scrapeRetryDelay(attempt)
+		slog.Info("Scrape failed, retrying", "url", source.URL,
+			"attempt", attempt+1, "max_retries", source.MaxRetries, "delay", delay, "error", err)
+		timer := time.NewTimer(delay)
+		select {
+		case <-ctx.Done():
+			timer.Stop()
+			return content, err
+		case <-timer.C:
+		}
+	}
+}
+
+// scrapeSourceOnce makes one attempt at scraping a source.
+func (s *Scraper) scrapeSourceOnce(ctx context.Context, source models.NewsSource, focus string) (*ai.ScrapedContent, error) {
 	if reddit.IsRedditURL(source.URL) {
-		return s.scrapeRedditSource(ctx, source)
+		return s.scrapeRedditSource(ctx, source, focus)
+	}
+
+	// Sources marked as feeds skip the URL heuristics and never fall back to HTML,
+	// since HTML scraping would only mangle the XML.
+	if source.ForceFeed {
+		return s.scrapeRSSFeed(ctx, source, focus)
+	}
+
+	// Sources found through a site's sitemap are scraped as its newest articles
+	if isSitemapURL(source.URL) {
+		return s.scrapeSitemap(ctx, source, focus)
 	}
 
 	// Try RSS/Atom feed parsing for URLs that look like feeds.
 	// This uses encoding/xml which properly handles XML content,
 	// unlike Colly's HTML parser which mangles RSS/Atom XML.
 	if isRSSURL(source.URL) {
-		content, err := s.scrapeRSSFeed(ctx, source)
-		if err == nil {
-			return content, nil
+		content, err := s.scrapeRSSFeed(ctx, source, focus)
+		if err == nil || errors.Is(err, ErrNotModified) {
+			return content, err
 		}
 		slog.Debug("RSS feed parsing failed, falling back to HTML scraping",
 			"url", source.URL, "error", err)
 	}
 
+	title, contentStr, _, err := s.scrapePage(ctx, source)
+	if err != nil {
+		return nil, err
+	}
+	if len(contentStr) < 100 {
+		return nil, fmt.Errorf("insufficient content scraped from %s", source.URL)
+	}
+
+	const maxLength = 50000
+	if len(contentStr) > maxLength {
+		contentStr = contentStr[:maxLength] + "..."
+	}
+
+	sourceName := source.Name
+	if sourceName == "" {
+		sourceName = title
+	}
+	if sourceName == "" {
+		if parsed, err := url.Parse(source.URL); err == nil {
+			sourceName = parsed.Host
+		}
+	}
+
+	return &ai.ScrapedContent{
+		URL:        source.URL,
+		SourceName: sourceName,
+		Content:    contentStr,
+	}, nil
+}
+
+// scrapePage scrapes an HTML page, returning its title and text, and its raw
+// HTML if it was served as HTML. The page's main article body is preferred,
+// falling back to text collected from content selectors, headlines, and
+// paragraphs. The request is abandoned when ctx is canceled.
+func (s *Scraper) scrapePage(ctx context.Context, source models.NewsSource) (title, text string, pageHTML []byte, err error) {
 	c := colly.NewCollector(
+		colly.StdlibContext(ctx),
 		colly.UserAgent(s.userAgent),
-		colly.MaxDepth(1),
+		colly.MaxDepth(s.maxDepth()),
+		colly.AllowedDomains(allowedDomains(source.URL)...),
 	)
-	c.SetRequestTimeout(s.requestTimeout)
+	c.SetRequestTimeout(s.timeout(source))
+	c.WithTransport(s.transport())
+	c.OnRequest(func(r *colly.Request) {
+		r.Headers.Set("User-Agent", s.userAgentFor(s.userAgent))
+	})
 
 	var content strings.Builder
-	var title string
 	var mu sync.Mutex
+	// Paragraphs that are obvious boilerplate only waste summarizer tokens
+	boilerplate := s.boilerplatePhrases()
+
+	// The main article body found by scoring the page is preferred; the
+	// selector callbacks below collect the fallback
+	var article strings.Builder
+	c.OnResponse(func(r *colly.Response) {
+		if !strings.Contains(r.Headers.Get("Content-Type"), "html") {
+			return
+		}
+		text := extractMainContent(r.Body, boilerplate)
+		mu.Lock()
+		defer mu.Unlock()
+		article.WriteString(text)
+		if pageHTML == nil {
+			pageHTML = r.Body
+		}
+	})
 
 	c.OnHTML("title", func(e *colly.HTMLElement) {
 		mu.Lock()
@@ -88,7 +286,7 @@ func (s *Scraper) ScrapeSource(ctx context.Context, source models.NewsSource) (*
 			mu.Lock()
 			defer mu.Unlock()
 			text := cleanText(e.Text)
-			if len(text) > 100 {
+			if len(text) > 100 && !isBoilerplate(text, boilerplate) {
 				content.WriteString(text)
 				content.WriteString("\n\n")
 			}
@@ -99,7 +297,7 @@ func (s *Scraper) ScrapeSource(ctx context.Context, source models.NewsSource) (*
 		mu.Lock()
 		defer mu.Unlock()
 		text := cleanText(e.Text)
-		if len(text) > 10 && len(text) < 200 {
+		if len(text) > 10 && len(text) < 200 && !isBoilerplate(text, boilerplate) {
 			content.WriteString("HEADLINE: ")
 			content.WriteString(text)
 			content.WriteString("\n")
@@ -110,7 +308,7 @@ func (s *Scraper) ScrapeSource(ctx context.Context, source models.NewsSource) (*
 		mu.Lock()
 		defer mu.Unlock()
 		text := cleanText(e.Text)
-		if len(text) > 50 && len(text) < 2000 {
+		if len(text) > 50 && len(text) < 2000 && !isBoilerplate(text, boilerplate) {
 			content.WriteString(text)
 			content.WriteString("\n")
 		}
@@ -147,43 +345,36 @@ func (s *Scraper) ScrapeSource(ctx context.Context, source models.NewsSource) (*
 	})
 
 	if err := c.Visit(source.URL); err != nil {
-		return nil, fmt.Errorf("failed to visit %s: %w", source.URL, err)
+		// The OnError message carries the response status
+		if scrapeErr != nil {
+			return "", "", nil, scrapeErr
+		}
+		return "", "", nil, fmt.Errorf("failed to visit %s: %w", source.URL, err)
 	}
 	c.Wait()
 
 	if scrapeErr != nil {
-		return nil, scrapeErr
+		return "", "", nil, scrapeErr
 	}
 
-	contentStr := content.String()
-	if len(contentStr) < 100 {
-		return nil, fmt.Errorf("insufficient content scraped from %s", source.URL)
-	}
-
-	const maxLength = 50000
-	if len(contentStr) > maxLength {
-		contentStr = contentStr[:maxLength] + "..."
-	}
-
-	sourceName := source.Name
-	if sourceName == "" {
-		sourceName = title
-	}
-	if sourceName == "" {
-		if parsed, err := url.Parse(source.URL); err == nil {
-			sourceName = parsed.Host
-		}
+	text = content.String()
+	if main := article.String(); len(main) >= readabilityMinChars {
+		slog.Debug("Extracted main content", "url", source.URL, "chars", len(main), "selector_chars", len(text))
+		text = main
 	}
+	return title, text, pageHTML, nil
+}
 
-	return &ai.ScrapedContent{
-		URL:        source.URL,
-		SourceName: sourceName,
-		Content:    contentStr,
-	}, nil
+// ScrapeSources scrapes multiple sources concurrently. focus is the topic's
+// content focus ("" for articles or ai.ContentFocusLinks).
+func (s *Scraper) ScrapeSources(ctx context.Context, sources []models.NewsSource, focus string) []ScrapeResult {
+	return s.ScrapeSourcesInBatch(ctx, sources, focus, nil)
 }
 
-// ScrapeSources scrapes multiple sources concurrently.
-func (s *Scraper) ScrapeSources(ctx context.Context, sources []models.NewsSource) []ScrapeResult {
+// ScrapeSourcesInBatch is ScrapeSources with results shared through batch, so
+// sources already scraped for another topic in the batch are not fetched
+// again. A nil batch scrapes every source.
+func (s *Scraper) ScrapeSourcesInBatch(ctx context.Context, sources []models.NewsSource, focus string, batch *Batch) []ScrapeResult {
 	var results []ScrapeResult
 	var mu sync.Mutex
 
@@ -214,7 +405,13 @@ func (s *Scraper) ScrapeSources(ctx context.Context, sources []models.NewsSource
 			sem <- struct{}{}
 			defer func() { <-sem }()
 
-			content, err := s.ScrapeSource(ctx, src)
+			var content *ai.ScrapedContent
+			var err error
+			if batch != nil {
+				content, err = batch.scrape(ctx, s, src, focus)
+			} else {
+				content, err = s.scrapeSource(ctx, src, focus)
+			}
 
 			mu.Lock()
 			results = append(results, ScrapeResult{
@@ -245,7 +442,7 @@ func ValidateURL(urlStr string) error {
 	return nil
 }
 
-func (s *Scraper) scrapeRedditSource(ctx context.Context, source models.NewsSource) (*ai.ScrapedContent, error) {
+func (s *Scraper) scrapeRedditSource(ctx context.Context, source models.NewsSource, focus string) (*ai.ScrapedContent, error) {
 	posts, err := s.redditClient.FetchPosts(ctx, source.URL)
 	if err != nil {
 		return nil, fmt.Errorf("failed to fetch Reddit posts: %w", err)
@@ -255,7 +452,19 @@ func (s *Scraper) scrapeRedditSource(ctx context.Context, source models.NewsSour
 	}
 
 	var content strings.Builder
+	bylines := make(map[string]string, len(posts))
 	for _, post := range posts {
+		if post.Author != "" {
+			bylines["https://reddit.com"+post.Permalink] = "u/" + post.Author
+		}
+		if focus == ai.ContentFocusLinks {
+			// Community interest is the signal here, so lead with it and keep the body short
+			fmt.Fprintf(&content, "LINK POST: %s\n", post.Title)
+			fmt.Fprintf(&content, "SCORE: %d | AUTHOR: u/%s\n", post.Score, post.Author)
+			fmt.Fprintf(&content, "LINK: https://reddit.com%s\n", post.Permalink)
+			fmt.Fprintf(&content, "EXCERPT: %s\n\n---\n\n", excerpt(post.Body))
+			continue
+		}
 		fmt.Fprintf(&content, "REDDIT POST: %s\n", post.Title)
 		fmt.Fprintf(&content, "LINK: https://reddit.com%s\n", post.Permalink)
 		fmt.Fprintf(&content, "SCORE: %d | AUTHOR: u/%s\n", post.Score, post.Author)
@@ -278,6 +487,7 @@ func (s *Scraper) scrapeRedditSource(ctx context.Context, source models.NewsSour
 		URL:        source.URL,
 		SourceName: sourceName,
 		Content:    contentStr,
+		Bylines:    bylines,
 	}, nil
 }
 
@@ -298,7 +508,7 @@ func cleanText(s string) string {
 
 // --- RSS/Atom feed parsing ---
 
-// isRSSURL checks if a URL looks like an RSS or Atom feed based on path patterns.
+// isRSSURL checks if a URL looks like an RSS, Atom, or JSON feed based on path patterns.
 func isRSSURL(u string) bool {
 	lower := strings.ToLower(u)
 	// Strip query string and fragment for path-based checks
@@ -313,6 +523,7 @@ func isRSSURL(u string) bool {
 		strings.HasSuffix(lower, ".xml") ||
 		strings.HasSuffix(lower, ".rss") ||
 		strings.HasSuffix(lower, ".atom") ||
+		strings.HasSuffix(lower, ".json") ||
 		strings.Contains(lower, "/feeds/") ||
 		strings.Contains(lower, "/rss/")
 }
@@ -334,6 +545,8 @@ type rssItem struct {
 	Description    string `xml:"description"`
 	PubDate        string `xml:"pubDate"`
 	ContentEncoded string `xml:"http://purl.org/rss/1.0/modules/content/ encoded"`
+	Author         string `xml:"author"`
+	Creator        string `xml:"http://purl.org/dc/elements/1.1/ creator"`
 }
 
 // Atom XML types
@@ -349,6 +562,9 @@ type atomEntry struct {
 	Summary string     `xml:"summary"`
 	Content string     `xml:"content"`
 	Updated string     `xml:"updated"`
+	Authors []struct {
+		Name string `xml:"name"`
+	} `xml:"author"`
 }
 
 type atomLink struct {
@@ -357,15 +573,22 @@ type atomLink struct {
 }
 
 // scrapeRSSFeed fetches and parses an RSS/Atom feed, returning structured content.
-func (s *Scraper) scrapeRSSFeed(ctx context.Context, source models.NewsSource) (*ai.ScrapedContent, error) {
-	client := &http.Client{Timeout: s.requestTimeout}
+func (s *Scraper) scrapeRSSFeed(ctx context.Context, source models.NewsSource, focus string) (content *ai.ScrapedContent, err error) {
+	client := &http.Client{Timeout: s.timeout(source), Transport: s.transport()}
 
 	req, err := http.NewRequestWithContext(ctx, "GET", source.URL, nil)
 	if err != nil {
 		return nil, fmt.Errorf("create request: %w", err)
 	}
-	req.Header.Set("User-Agent", s.userAgent)
-	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/xml, text/xml, */*")
+	req.Header.Set("User-Agent", s.userAgentFor(s.userAgent))
+	req.Header.Set("Accept", "application/rss+xml, application/atom+xml, application/feed+json, application/xml, text/xml, */*")
+	// Validators from the last summarized fetch let the server skip sending an unchanged feed
+	if source.ETag != "" {
+		req.Header.Set("If-None-Match", source.ETag)
+	}
+	if source.LastModified != "" {
+		req.Header.Set("If-Modified-Since", source.LastModified)
+	}
 
 	resp, err := client.Do(req)
 	if err != nil {
@@ -373,46 +596,117 @@ func (s *Scraper) scrapeRSSFeed(ctx context.Context, source models.NewsSource) (
 	}
 	defer resp.Body.Close()
 
+	if resp.StatusCode == http.StatusNotModified {
+		slog.Debug("Feed not modified", "url", source.URL)
+		return nil, ErrNotModified
+	}
 	if resp.StatusCode != 200 {
 		return nil, fmt.Errorf("feed returned status %d for %s", resp.StatusCode, source.URL)
 	}
 
-	// If the server explicitly returns HTML, this isn't a feed
+	// If the server explicitly returns HTML, this isn't a feed, unless the source
+	// is marked as one (some servers label feeds text/html)
 	contentType := resp.Header.Get("Content-Type")
-	if strings.Contains(contentType, "text/html") {
+	if !source.ForceFeed && strings.Contains(contentType, "text/html") {
 		return nil, fmt.Errorf("URL returned HTML content-type, not a feed")
 	}
 
-	body, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20)) // 1MB limit
+	defer func() {
+		if content != nil {
+			content.ETag = resp.Header.Get("ETag")
+			content.LastModified = resp.Header.Get("Last-Modified")
+		}
+	}()
+
+	maxBytes := s.feedMaxBytes()
+	body, err := io.ReadAll(io.LimitReader(resp.Body, maxBytes+1))
 	if err != nil {
 		return nil, fmt.Errorf("read feed body: %w", err)
 	}
 
+	// JSON Feed. Items are not decoded incrementally, so it must fit in the limit
+	if isJSONContentType(contentType) || bytes.HasPrefix(bytes.TrimSpace(body), []byte("{")) {
+		return s.scrapeJSONFeed(source, body, maxBytes, focus)
+	}
+
+	mode := s.contentMode()
+	authors := s.parseAuthors()
+	decode := s.decodeEntities()
+
+	// A feed over the limit would be cut off mid-document and fail to unmarshal,
+	// so decode it item by item, continuing past what has already been read
+	if int64(len(body)) > maxBytes {
+		feed := decodeFeedStream(io.MultiReader(bytes.NewReader(body), resp.Body), contentType)
+		if len(feed.Items) > 0 {
+			slog.Info("Parsed large RSS feed incrementally", "url", source.URL, "items", len(feed.Items),
+				"title", feed.Title)
+			return formatRSSItems(source, feed.Title, feed.Items, mode, focus, authors, decode), nil
+		}
+		if len(feed.Entries) > 0 {
+			slog.Info("Parsed large Atom feed incrementally", "url", source.URL, "entries", len(feed.Entries),
+				"title", feed.Title)
+			return formatAtomEntries(source, feed.Title, feed.Entries, mode, focus, authors, decode), nil
+		}
+		return nil, fmt.Errorf("URL %s is not a recognized RSS/Atom feed", source.URL)
+	}
+
 	// Try RSS 2.0
 	var rss rssFeed
-	if xml.Unmarshal(body, &rss) == nil && len(rss.Channel.Items) > 0 {
+	if newFeedDecoder(bytes.NewReader(body), contentType).Decode(&rss) == nil && len(rss.Channel.Items) > 0 {
 		slog.Info("Parsed RSS feed", "url", source.URL, "items", len(rss.Channel.Items),
 			"title", rss.Channel.Title)
-		return formatRSSItems(source, rss.Channel.Title, rss.Channel.Items), nil
+		return formatRSSItems(source, rss.Channel.Title, rss.Channel.Items, mode, focus, authors, decode), nil
 	}
 
 	// Try Atom
 	var atom atomFeed
-	if xml.Unmarshal(body, &atom) == nil && len(atom.Entries) > 0 {
+	if newFeedDecoder(bytes.NewReader(body), contentType).Decode(&atom) == nil && len(atom.Entries) > 0 {
 		slog.Info("Parsed Atom feed", "url", source.URL, "entries", len(atom.Entries),
 			"title", atom.Title)
-		return formatAtomEntries(source, atom.Title, atom.Entries), nil
+		return formatAtomEntries(source, atom.Title, atom.Entries, mode, focus, authors, decode), nil
 	}
 
 	return nil, fmt.Errorf("URL %s is not a recognized RSS/Atom feed", source.URL)
 }
 
-func formatRSSItems(source models.NewsSource, feedTitle string, items []rssItem) *ai.ScrapedContent {
+// scrapeJSONFeed parses a feed body served as JSON, which is read whole.
+func (s *Scraper) scrapeJSONFeed(source models.NewsSource, body []byte, maxBytes int64, focus string) (*ai.ScrapedContent, error) {
+	if int64(len(body)) > maxBytes {
+		return nil, fmt.Errorf("JSON feed %s is larger than %d bytes", source.URL, maxBytes)
+	}
+	feed, ok := parseJSONFeed(body)
+	if !ok || len(feed.Items) == 0 {
+		return nil, fmt.Errorf("URL %s is not a recognized JSON feed", source.URL)
+	}
+	slog.Info("Parsed JSON feed", "url", source.URL, "items", len(feed.Items), "title", feed.Title)
+	return formatJSONFeedItems(source, feed.Title, feed.Items, s.contentMode(), focus, s.parseAuthors(), s.decodeEntities()), nil
+}
+
+// formatRSSItems formats RSS items for the summarizer. With parseAuthors set,
+// each item's byline is written on an AUTHOR line and recorded by link. With
+// decode set, entities in plain-text content are decoded.
+func formatRSSItems(source models.NewsSource, feedTitle string, items []rssItem, mode, focus string, parseAuthors, decode bool) *ai.ScrapedContent {
 	var content strings.Builder
+	bylines := make(map[string]string)
 	for _, item := range items {
 		if item.Title == "" {
 			continue
 		}
+		author := ""
+		if parseAuthors {
+			author = rssItemAuthor(item)
+			if author != "" && item.Link != "" {
+				bylines[item.Link] = author
+			}
+		}
+		if focus == ai.ContentFocusLinks {
+			desc := item.Description
+			if desc == "" {
+				desc = item.ContentEncoded
+			}
+			writeLinkPost(&content, item.Title, item.Link, item.PubDate, author, desc, decode)
+			continue
+		}
 		content.WriteString("ARTICLE: ")
 		content.WriteString(item.Title)
 		content.WriteString("\n")
@@ -426,26 +720,49 @@ func formatRSSItems(source models.NewsSource, feedTitle string, items []rssItem)
 			content.WriteString(item.PubDate)
 			content.WriteString("\n")
 		}
+		if author != "" {
+			content.WriteString("AUTHOR: ")
+			content.WriteString(author)
+			content.WriteString("\n")
+		}
 		// Prefer content:encoded (full article) over description (summary)
 		desc := item.ContentEncoded
 		if desc == "" {
 			desc = item.Description
 		}
 		if desc != "" {
-			content.WriteString(cleanText(stripHTMLTags(desc)))
+			content.WriteString(formatFeedHTML(desc, mode, decode))
 			content.WriteString("\n\n")
 		}
 	}
 
-	return buildScrapedContent(source, feedTitle, content.String())
+	return buildScrapedContent(source, feedTitle, content.String(), bylines)
 }
 
-func formatAtomEntries(source models.NewsSource, feedTitle string, entries []atomEntry) *ai.ScrapedContent {
+// formatAtomEntries formats Atom entries for the summarizer. With parseAuthors
+// set, each entry's byline is written on an AUTHOR line and recorded by link.
+func formatAtomEntries(source models.NewsSource, feedTitle string, entries []atomEntry, mode, focus string, parseAuthors, decode bool) *ai.ScrapedContent {
 	var content strings.Builder
+	bylines := make(map[string]string)
 	for _, entry := range entries {
 		if entry.Title == "" {
 			continue
 		}
+		author := ""
+		if parseAuthors {
+			author = atomEntryAuthor(entry)
+			if link := atomEntryLink(entry); author != "" && link != "" {
+				bylines[link] = author
+			}
+		}
+		if focus == ai.ContentFocusLinks {
+			desc := entry.Summary
+			if desc == "" {
+				desc = entry.Content
+			}
+			writeLinkPost(&content, entry.Title, atomEntryLink(entry), entry.Updated, author, desc, decode)
+			continue
+		}
 		content.WriteString("ARTICLE: ")
 		content.WriteString(entry.Title)
 		content.WriteString("\n")
@@ -459,18 +776,51 @@ func formatAtomEntries(source models.NewsSource, feedTitle string, entries []ato
 			content.WriteString(entry.Updated)
 			content.WriteString("\n")
 		}
+		if author != "" {
+			content.WriteString("AUTHOR: ")
+			content.WriteString(author)
+			content.WriteString("\n")
+		}
 		// Prefer content over summary
 		desc := entry.Content
 		if desc == "" {
 			desc = entry.Summary
 		}
 		if desc != "" {
-			content.WriteString(cleanText(stripHTMLTags(desc)))
+			content.WriteString(formatFeedHTML(desc, mode, decode))
 			content.WriteString("\n\n")
 		}
 	}
 
-	return buildScrapedContent(source, feedTitle, content.String())
+	return buildScrapedContent(source, feedTitle, content.String(), bylines)
+}
+
+// rssItemAuthor returns an RSS item's byline. dc:creator holds a plain name and
+// is preferred; <author> is meant to be an email address, often followed by the
+// name in parentheses ("jane@example.com (Jane Doe)"), so the name is taken
+// from the parentheses when present.
+func rssItemAuthor(item rssItem) string {
+	if name := cleanText(item.Creator); name != "" {
+		return name
+	}
+	author := cleanText(item.Author)
+	if open := strings.Index(author, "("); open >= 0 {
+		if end := strings.LastIndex(author, ")"); end > open+1 {
+			return strings.TrimSpace(author[open+1 : end])
+		}
+	}
+	return author
+}
+
+// atomEntryAuthor returns an Atom entry's authors' names joined with commas.
+func atomEntryAuthor(entry atomEntry) string {
+	var names []string
+	for _, a := range entry.Authors {
+		if name := cleanText(a.Name); name != "" {
+			names = append(names, name)
+		}
+	}
+	return strings.Join(names, ", ")
 }
 
 // atomEntryLink extracts the best link from an Atom entry.
@@ -487,7 +837,51 @@ func atomEntryLink(entry atomEntry) string {
 	return ""
 }
 
-func buildScrapedContent(source models.NewsSource, feedTitle, contentStr string) *ai.ScrapedContent {
+// writeLinkPost writes a feed item from a link aggregator: the title and link
+// carry the story, and the body is usually thin or just a comments link, so only
+// a short excerpt is kept.
+func writeLinkPost(sb *strings.Builder, title, link, date, author, body string, decode bool) {
+	sb.WriteString("LINK POST: ")
+	sb.WriteString(title)
+	sb.WriteString("\n")
+	if link != "" {
+		sb.WriteString("LINK: ")
+		sb.WriteString(link)
+		sb.WriteString("\n")
+	}
+	if date != "" {
+		sb.WriteString("DATE: ")
+		sb.WriteString(date)
+		sb.WriteString("\n")
+	}
+	if author != "" {
+		sb.WriteString("AUTHOR: ")
+		sb.WriteString(author)
+		sb.WriteString("\n")
+	}
+	if ex := excerpt(plainFeedText(body, decode)); ex != "" {
+		sb.WriteString("EXCERPT: ")
+		sb.WriteString(ex)
+		sb.WriteString("\n")
+	}
+	sb.WriteString("\n")
+}
+
+// excerpt shortens s to about 300 characters, breaking at a word boundary.
+func excerpt(s string) string {
+	const maxLen = 300
+	s = cleanText(s)
+	if len(s) <= maxLen {
+		return s
+	}
+	cut := s[:maxLen]
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
+
+func buildScrapedContent(source models.NewsSource, feedTitle, contentStr string, bylines map[string]string) *ai.ScrapedContent {
 	const maxLength = 50000
 	if len(contentStr) > maxLength {
 		contentStr = contentStr[:maxLength] + "..."
@@ -507,7 +901,29 @@ func buildScrapedContent(source models.NewsSource, feedTitle, contentStr string)
 		URL:        source.URL,
 		SourceName: sourceName,
 		Content:    contentStr,
+		Bylines:    bylines,
+	}
+}
+
+// formatFeedHTML converts HTML from a feed item body into text for the summarizer.
+// In "markdown" mode links, lists, and emphasis are preserved as Markdown;
+// otherwise all markup is stripped. Markdown conversion always decodes entities.
+func formatFeedHTML(s, mode string, decode bool) string {
+	if mode == "markdown" {
+		return htmlToMarkdown(s)
+	}
+	return plainFeedText(s, decode)
+}
+
+// plainFeedText strips the markup from feed HTML. With decode set, entities are
+// then decoded, so "&amp;" becomes "&" and "&#8217;" a curly apostrophe. Decoding
+// after stripping keeps escaped markup such as "&lt;b&gt;" as literal text.
+func plainFeedText(s string, decode bool) string {
+	text := stripHTMLTags(s)
+	if decode {
+		text = html.UnescapeString(text)
 	}
+	return cleanText(text)
 }
 
 // stripHTMLTags removes HTML tags from a string. RSS feed content often contains
@@ -0,0 +1,328 @@
+package scraper
+
+import (
+	"cmp"
+	"context"
+	"encoding/xml"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"net/url"
+	"path"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/ai"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// Sites without a feed often still publish a sitemap listing their articles.
+// ValidateSource falls back to one when feed discovery fails, and the source
+// is then stored as the sitemap URL, so each refresh scrapes the site's newest
+// articles rather than its landing page.
+
+// sitemapPaths are where sites usually serve their sitemap or sitemap index,
+// tried in order.
+var sitemapPaths = []string{"/sitemap.xml", "/sitemap_index.xml"}
+
+const (
+	// sitemapArticleCount is how many of a sitemap's newest articles are
+	// scraped each refresh.
+	sitemapArticleCount = 5
+
+	// sitemapMaxAttempts is how many article URLs are tried to get
+	// sitemapArticleCount of them, so a site that blocks every article costs
+	// a few requests rather than one per article it lists.
+	sitemapMaxAttempts = 2 * sitemapArticleCount
+
+	// sitemapArticleMaxChars caps the text kept from each article, so the
+	// articles share the content limit instead of the first filling it.
+	sitemapArticleMaxChars = 10000
+
+	// sitemapMaxChildren is how many sitemaps listed in a sitemap index are
+	// read. The newest are picked, preferring news sitemaps.
+	sitemapMaxChildren = 3
+
+	// sitemapMaxAge drops articles last modified longer ago than this. Articles
+	// without a date are kept, after the dated ones.
+	sitemapMaxAge = 30 * 24 * time.Hour
+
+	// maxSitemapBytes caps how much of a sitemap is read. Sitemaps can list
+	// 50,000 URLs, and entries past the cap are ignored.
+	maxSitemapBytes = 10 << 20
+)
+
+// nonArticleSegments are path segments of listing pages rather than articles.
+var nonArticleSegments = map[string]bool{
+	"tag": true, "tags": true, "category": true, "categories": true, "topic": true, "topics": true,
+	"author": true, "authors": true, "page": true, "search": true, "about": true, "contact": true,
+}
+
+// sitemapEntry is a <url> in a sitemap or a <sitemap> in a sitemap index.
+// Google News sitemaps give an article's publication date in a news:news
+// element, which is preferred over lastmod.
+type sitemapEntry struct {
+	Loc     string `xml:"loc"`
+	LastMod string `xml:"lastmod"`
+	News    struct {
+		PublicationDate string `xml:"publication_date"`
+		Title           string `xml:"title"`
+	} `xml:"news"`
+}
+
+// date returns when the entry was published or last modified, or the zero
+// time if it has no date.
+func (e sitemapEntry) date() time.Time {
+	for _, v := range []string{e.News.PublicationDate, e.LastMod} {
+		v = strings.TrimSpace(v)
+		for _, layout := range []string{time.RFC3339, "2006-01-02T15:04Z07:00", "2006-01-02"} {
+			if t, err := time.Parse(layout, v); err == nil {
+				return t
+			}
+		}
+	}
+	return time.Time{}
+}
+
+// isSitemapURL reports whether a URL names an XML sitemap, such as
+// /sitemap.xml or /post-sitemap.xml.
+func isSitemapURL(u string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil {
+		return false
+	}
+	base := strings.ToLower(path.Base(parsed.Path))
+	return strings.Contains(base, "sitemap") && strings.HasSuffix(base, ".xml")
+}
+
+// discoverSitemap looks for a sitemap at the root of a page's site and returns
+// its URL if it lists any recent articles, or "" if none is found.
+func (s *Scraper) discoverSitemap(ctx context.Context, pageURL string) string {
+	parsed, err := url.Parse(pageURL)
+	if err != nil || parsed.Host == "" {
+		return ""
+	}
+	for _, p := range sitemapPaths {
+		sitemapURL := (&url.URL{Scheme: parsed.Scheme, Host: parsed.Host, Path: p}).String()
+		articles, err := s.sitemapArticles(ctx, sitemapURL, 10*time.Second)
+		if err != nil {
+			slog.Debug("No usable sitemap", "url", sitemapURL, "error", err)
+			continue
+		}
+		if len(articles) > 0 {
+			return sitemapURL
+		}
+	}
+	return ""
+}
+
+// scrapeSitemap scrapes the newest articles listed in a sitemap, formatted
+// like feed items. Articles that fail to scrape are skipped, trying at most
+// sitemapMaxAttempts of them.
+func (s *Scraper) scrapeSitemap(ctx context.Context, source models.NewsSource, focus string) (*ai.ScrapedContent, error) {
+	articles, err := s.sitemapArticles(ctx, source.URL, s.timeout(source))
+	if err != nil {
+		return nil, err
+	}
+	if len(articles) == 0 {
+		return nil, fmt.Errorf("sitemap %s lists no recent articles", source.URL)
+	}
+
+	var content strings.Builder
+	scraped := 0
+	for _, entry := range articles[:min(len(articles), sitemapMaxAttempts)] {
+		if scraped == sitemapArticleCount || ctx.Err() != nil {
+			break
+		}
+		page := models.NewsSource{URL: entry.Loc, TimeoutSeconds: source.TimeoutSeconds}
+		title, text, _, err := s.scrapePage(ctx, page)
+		if err != nil || len(text) < 100 {
+			slog.Debug("Skipping sitemap article", "url", entry.Loc, "error", err)
+			continue
+		}
+		scraped++
+		if len(text) > sitemapArticleMaxChars {
+			text = text[:sitemapArticleMaxChars] + "..."
+		}
+		title = cmp.Or(strings.TrimSpace(entry.News.Title), title, entry.Loc)
+		date := ""
+		if t := entry.date(); !t.IsZero() {
+			date = t.Format(time.RFC1123Z)
+		}
+
+		if focus == ai.ContentFocusLinks {
+			writeLinkPost(&content, title, entry.Loc, date, "", text, false)
+			continue
+		}
+		content.WriteString("ARTICLE: ")
+		content.WriteString(title)
+		content.WriteString("\n")
+		content.WriteString("LINK: ")
+		content.WriteString(entry.Loc)
+		content.WriteString("\n")
+		if date != "" {
+			content.WriteString("DATE: ")
+			content.WriteString(date)
+			content.WriteString("\n")
+		}
+		content.WriteString(text)
+		content.WriteString("\n\n")
+	}
+	if scraped == 0 {
+		return nil, fmt.Errorf("no articles could be scraped from sitemap %s", source.URL)
+	}
+
+	slog.Info("Scraped articles from sitemap", "url", source.URL, "articles", scraped)
+	return buildScrapedContent(source, "", content.String(), nil), nil
+}
+
+// sitemapArticles returns the recent article URLs in a sitemap on the same
+// site, newest first. For a sitemap index, the articles come from its newest
+// few sitemaps.
+func (s *Scraper) sitemapArticles(ctx context.Context, sitemapURL string, timeout time.Duration) ([]sitemapEntry, error) {
+	urls, children, err := s.fetchSitemap(ctx, sitemapURL, timeout)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(children) > 0 {
+		slices.SortStableFunc(children, func(a, b sitemapEntry) int {
+			aNews, bNews := isNewsSitemap(a.Loc), isNewsSitemap(b.Loc)
+			if aNews != bNews {
+				if aNews {
+					return -1
+				}
+				return 1
+			}
+			return b.date().Compare(a.date())
+		})
+		for _, child := range children[:min(len(children), sitemapMaxChildren)] {
+			childURLs, _, err := s.fetchSitemap(ctx, child.Loc, timeout)
+			if err != nil {
+				slog.Debug("Failed to fetch sitemap from index", "index", sitemapURL, "url", child.Loc, "error", err)
+				continue
+			}
+			urls = append(urls, childURLs...)
+		}
+	}
+
+	host := ""
+	if parsed, err := url.Parse(sitemapURL); err == nil {
+		host = strings.TrimPrefix(parsed.Host, "www.")
+	}
+	cutoff := time.Now().Add(-sitemapMaxAge)
+	seen := make(map[string]bool)
+	var articles []sitemapEntry
+	for _, entry := range urls {
+		entry.Loc = strings.TrimSpace(entry.Loc)
+		if seen[entry.Loc] || !isArticleURL(entry.Loc, host) {
+			continue
+		}
+		if t := entry.date(); !t.IsZero() && t.Before(cutoff) {
+			continue
+		}
+		seen[entry.Loc] = true
+		articles = append(articles, entry)
+	}
+	slices.SortStableFunc(articles, func(a, b sitemapEntry) int {
+		return b.date().Compare(a.date())
+	})
+	return articles, nil
+}
+
+// fetchSitemap fetches a sitemap, returning the page URLs it lists or, for a
+// sitemap index, the sitemaps it lists. A document cut off by maxSitemapBytes
+// keeps the entries read before the cut.
+func (s *Scraper) fetchSitemap(ctx context.Context, sitemapURL string, timeout time.Duration) (urls, children []sitemapEntry, err error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", sitemapURL, nil)
+	if err != nil {
+		return nil, nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", s.userAgentFor(s.userAgent))
+	req.Header.Set("Accept", "application/xml, text/xml, */*")
+
+	client := &http.Client{Timeout: timeout, Transport: s.transport()}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, nil, fmt.Errorf("fetch sitemap %s: %w", sitemapURL, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != 200 {
+		return nil, nil, fmt.Errorf("sitemap returned status %d for %s", resp.StatusCode, sitemapURL)
+	}
+	contentType := resp.Header.Get("Content-Type")
+	if strings.Contains(contentType, "text/html") {
+		return nil, nil, fmt.Errorf("URL returned HTML content-type, not a sitemap")
+	}
+
+	dec := newFeedDecoder(io.LimitReader(resp.Body, maxSitemapBytes), contentType)
+	sawRoot := false
+	for {
+		tok, err := dec.Token()
+		if err != nil {
+			break
+		}
+		start, ok := tok.(xml.StartElement)
+		if !ok {
+			continue
+		}
+		if !sawRoot {
+			if start.Name.Local != "urlset" && start.Name.Local != "sitemapindex" {
+				return nil, nil, fmt.Errorf("URL %s is not a sitemap", sitemapURL)
+			}
+			sawRoot = true
+			continue
+		}
+		if start.Name.Local != "url" && start.Name.Local != "sitemap" {
+			continue
+		}
+		var entry sitemapEntry
+		if err := dec.DecodeElement(&entry, &start); err != nil {
+			break
+		}
+		if entry.Loc == "" {
+			continue
+		}
+		if start.Name.Local == "url" {
+			urls = append(urls, entry)
+		} else {
+			children = append(children, entry)
+		}
+	}
+	if !sawRoot {
+		return nil, nil, fmt.Errorf("URL %s is not a sitemap", sitemapURL)
+	}
+	return urls, children, nil
+}
+
+// isNewsSitemap reports whether a sitemap in an index looks like it lists news
+// articles, such as /news-sitemap.xml or /sitemap-news.xml.
+func isNewsSitemap(u string) bool {
+	return strings.Contains(strings.ToLower(u), "news")
+}
+
+// isArticleURL reports whether a sitemap URL looks like an article on host
+// (or its www. form): not the home page or a tag, category, or author
+// listing, and ending in a slug or ID.
+func isArticleURL(u, host string) bool {
+	parsed, err := url.Parse(u)
+	if err != nil || (parsed.Scheme != "http" && parsed.Scheme != "https") {
+		return false
+	}
+	if host != "" && strings.TrimPrefix(parsed.Host, "www.") != host {
+		return false
+	}
+	segments := strings.FieldsFunc(strings.ToLower(parsed.Path), func(r rune) bool { return r == '/' })
+	if len(segments) == 0 {
+		return false
+	}
+	for _, seg := range segments {
+		if nonArticleSegments[seg] {
+			return false
+		}
+	}
+	last := strings.TrimSuffix(segments[len(segments)-1], ".html")
+	return strings.Contains(last, "-") || strings.ContainsAny(last, "0123456789")
+}
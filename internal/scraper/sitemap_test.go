@@ -0,0 +1,156 @@
+package scraper
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"slices"
+	"testing"
+	"time"
+)
+
+func TestIsSitemapURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		want bool
+	}{
+		{"https://example.com/sitemap.xml", true},
+		{"https://example.com/sitemap_index.xml", true},
+		{"https://example.com/post-sitemap.xml?page=2", true},
+		{"https://example.com/Sitemap-News.XML", true},
+		{"https://example.com/feed.xml", false},
+		{"https://example.com/sitemap", false},
+		{"https://example.com/sitemap.xml.gz", false},
+		{"https://example.com/", false},
+	}
+	for _, tt := range tests {
+		if got := isSitemapURL(tt.url); got != tt.want {
+			t.Errorf("isSitemapURL(%q) = %v, want %v", tt.url, got, tt.want)
+		}
+	}
+}
+
+func TestIsArticleURL(t *testing.T) {
+	tests := []struct {
+		url  string
+		host string
+		want bool
+	}{
+		{"https://example.com/2026/10/moon-landing-anniversary", "example.com", true},
+		{"https://www.example.com/news/12345", "example.com", true},
+		{"https://example.com/story-title.html", "example.com", true},
+		{"https://example.com/", "example.com", false},
+		{"https://example.com/news", "example.com", false},
+		{"https://example.com/tag/space-news", "example.com", false},
+		{"https://example.com/category/science-2026", "example.com", false},
+		{"https://example.com/author/jane-doe", "example.com", false},
+		{"https://other.com/moon-landing-anniversary", "example.com", false},
+		{"ftp://example.com/moon-landing-anniversary", "example.com", false},
+	}
+	for _, tt := range tests {
+		if got := isArticleURL(tt.url, tt.host); got != tt.want {
+			t.Errorf("isArticleURL(%q, %q) = %v, want %v", tt.url, tt.host, got, tt.want)
+		}
+	}
+}
+
+func TestSitemapEntryDate(t *testing.T) {
+	tests := []struct {
+		name  string
+		entry sitemapEntry
+		want  time.Time
+	}{
+		{"RFC 3339", sitemapEntry{LastMod: "2026-10-17T06:30:00Z"}, time.Date(2026, 10, 17, 6, 30, 0, 0, time.UTC)},
+		{"Fractional seconds", sitemapEntry{LastMod: "2026-10-17T06:30:00.123Z"}, time.Date(2026, 10, 17, 6, 30, 0, 123e6, time.UTC)},
+		{"No seconds", sitemapEntry{LastMod: "2026-10-17T06:30Z"}, time.Date(2026, 10, 17, 6, 30, 0, 0, time.UTC)},
+		{"Date only", sitemapEntry{LastMod: " 2026-10-17 "}, time.Date(2026, 10, 17, 0, 0, 0, 0, time.UTC)},
+		{"Invalid", sitemapEntry{LastMod: "yesterday"}, time.Time{}},
+		{"None", sitemapEntry{}, time.Time{}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.entry.date(); !got.Equal(tt.want) {
+				t.Errorf("date() = %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	var news sitemapEntry
+	news.LastMod = "2026-10-17"
+	news.News.PublicationDate = "2026-10-16T12:00:00Z"
+	if got, want := news.date(), time.Date(2026, 10, 16, 12, 0, 0, 0, time.UTC); !got.Equal(want) {
+		t.Errorf("date() with a news publication date = %v, want %v", got, want)
+	}
+}
+
+func TestFetchSitemap(t *testing.T) {
+	docs := map[string]string{
+		"/urlset.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<urlset xmlns="http://www.sitemaps.org/schemas/sitemap/0.9" xmlns:news="http://www.google.com/schemas/sitemap-news/0.9">
+  <url><loc>https://example.com/first-story</loc><lastmod>2026-10-17</lastmod></url>
+  <url><loc>https://example.com/second-story</loc>
+    <news:news><news:publication_date>2026-10-16T12:00:00Z</news:publication_date><news:title>Second Story</news:title></news:news>
+  </url>
+  <url><lastmod>2026-10-17</lastmod></url>
+</urlset>`,
+		"/index.xml": `<?xml version="1.0" encoding="UTF-8"?>
+<sitemapindex xmlns="http://www.sitemaps.org/schemas/sitemap/0.9">
+  <sitemap><loc>https://example.com/post-sitemap.xml</loc><lastmod>2026-10-17</lastmod></sitemap>
+  <sitemap><loc>https://example.com/page-sitemap.xml</loc></sitemap>
+</sitemapindex>`,
+		"/truncated.xml": `<urlset><url><loc>https://example.com/first-story</loc></url><url><loc>https://exa`,
+		"/feed.xml":      `<rss version="2.0"><channel><title>Feed</title></channel></rss>`,
+	}
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		doc, ok := docs[r.URL.Path]
+		if !ok {
+			http.NotFound(w, r)
+			return
+		}
+		w.Header().Set("Content-Type", "application/xml")
+		w.Write([]byte(doc))
+	}))
+	defer srv.Close()
+
+	tests := []struct {
+		path         string
+		wantURLs     []string
+		wantChildren []string
+		wantErr      bool
+	}{
+		{"/urlset.xml", []string{"https://example.com/first-story", "https://example.com/second-story"}, nil, false},
+		{"/index.xml", nil, []string{"https://example.com/post-sitemap.xml", "https://example.com/page-sitemap.xml"}, false},
+		{"/truncated.xml", []string{"https://example.com/first-story"}, nil, false},
+		{"/feed.xml", nil, nil, true},
+		{"/missing.xml", nil, nil, true},
+	}
+
+	s := New(nil)
+	for _, tt := range tests {
+		t.Run(tt.path, func(t *testing.T) {
+			urls, children, err := s.fetchSitemap(context.Background(), srv.URL+tt.path, 5*time.Second)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("error = %v, want error %v", err, tt.wantErr)
+			}
+			if got := entryLocs(urls); !slices.Equal(got, tt.wantURLs) {
+				t.Errorf("urls = %q, want %q", got, tt.wantURLs)
+			}
+			if got := entryLocs(children); !slices.Equal(got, tt.wantChildren) {
+				t.Errorf("children = %q, want %q", got, tt.wantChildren)
+			}
+		})
+	}
+
+	urls, _, _ := s.fetchSitemap(context.Background(), srv.URL+"/urlset.xml", 5*time.Second)
+	if len(urls) == 2 && urls[1].News.Title != "Second Story" {
+		t.Errorf("news title = %q, want %q", urls[1].News.Title, "Second Story")
+	}
+}
+
+func entryLocs(entries []sitemapEntry) []string {
+	var locs []string
+	for _, e := range entries {
+		locs = append(locs, e.Loc)
+	}
+	return locs
+}
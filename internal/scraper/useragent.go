@@ -0,0 +1,26 @@
+package scraper
+
+import "strings"
+
+// userAgentFor returns the User-Agent for the next request: the next entry of
+// the scraper_user_agents list in turn when it is set, otherwise the
+// scraper_user_agent override, otherwise def.
+func (s *Scraper) userAgentFor(def string) string {
+	if s.settings == nil {
+		return def
+	}
+	if v, _ := s.settings.GetSetting("scraper_user_agents"); strings.TrimSpace(v) != "" {
+		var agents []string
+		for _, line := range strings.Split(v, "\n") {
+			if line = strings.TrimSpace(line); line != "" {
+				agents = append(agents, line)
+			}
+		}
+		n := s.userAgentTurn.Add(1) - 1
+		return agents[n%uint64(len(agents))]
+	}
+	if v, _ := s.settings.GetSetting("scraper_user_agent"); strings.TrimSpace(v) != "" {
+		return strings.TrimSpace(v)
+	}
+	return def
+}
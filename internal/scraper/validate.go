@@ -3,8 +3,12 @@ package scraper
 import (
 	"context"
 	"fmt"
+	"log/slog"
+	"strconv"
+	"sync"
 	"time"
 
+	"github.com/thinkscotty/kibble/internal/ai"
 	"github.com/thinkscotty/kibble/internal/models"
 	"github.com/thinkscotty/kibble/internal/reddit"
 )
@@ -16,11 +20,83 @@ type ValidationResult struct {
 	OK      bool
 	Reason  string // why it failed, if !OK
 	FeedURL string // RSS feed URL, if discovered during validation
+
+	// SitemapURL is the site's sitemap, used when no feed was discovered
+	SitemapURL string
+}
+
+// defaultBulkValidateConcurrency is how many sources ValidateSources tests at
+// once when bulk_validate_concurrency is unset or invalid.
+const defaultBulkValidateConcurrency = 3
+
+// bulkValidateConcurrency returns the bulk_validate_concurrency setting, capped
+// at the scrape parallelism so a bulk check never outweighs scheduled refreshes.
+func (s *Scraper) bulkValidateConcurrency() int {
+	n := defaultBulkValidateConcurrency
+	if s.settings != nil {
+		v, _ := s.settings.GetSetting("bulk_validate_concurrency")
+		if parsed, err := strconv.Atoi(v); err == nil && parsed > 0 {
+			n = parsed
+		}
+	}
+	return min(n, s.parallelLimit)
+}
+
+// defaultValidationMinChars is the content a test-scrape must return for
+// ValidateSource to accept a source when source_validation_min_chars is unset.
+// It is well above ScrapeSource's own minimum, so a page that yields only a
+// navigation blob is rejected at discovery rather than failing every refresh.
+const defaultValidationMinChars = 500
+
+// validationMinChars returns the source_validation_min_chars setting.
+func (s *Scraper) validationMinChars() int {
+	if s.settings == nil {
+		return defaultValidationMinChars
+	}
+	v, _ := s.settings.GetSetting("source_validation_min_chars")
+	if n, err := strconv.Atoi(v); err == nil && n > 0 {
+		return n
+	}
+	return defaultValidationMinChars
+}
+
+// ValidateSources runs ValidateSource over many sources, a few at a time as set
+// by bulk_validate_concurrency. Results are returned in the order of sources.
+func (s *Scraper) ValidateSources(ctx context.Context, sources []ai.DiscoveredSource) []ValidationResult {
+	results := make([]ValidationResult, len(sources))
+	sem := make(chan struct{}, s.bulkValidateConcurrency())
+	var wg sync.WaitGroup
+
+	for i, src := range sources {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			defer func() {
+				if r := recover(); r != nil {
+					results[i] = ValidationResult{URL: src.URL, Name: src.Name, Reason: fmt.Sprintf("panic while validating: %v", r)}
+				}
+			}()
+
+			sem <- struct{}{}
+			defer func() { <-sem }()
+
+			if ctx.Err() != nil {
+				results[i] = ValidationResult{URL: src.URL, Name: src.Name, Reason: ctx.Err().Error()}
+				return
+			}
+			results[i] = s.ValidateSource(ctx, src.URL, src.Name)
+		}()
+	}
+
+	wg.Wait()
+	return results
 }
 
 // ValidateSource performs a lightweight test-scrape of a source URL to confirm
 // it returns usable content. If the source is a web page (not Reddit), it also
-// attempts RSS feed auto-discovery and prefers the feed URL if found.
+// attempts RSS feed auto-discovery and prefers the feed URL if found. Failing
+// that, it prefers the site's sitemap, whose newest articles make better
+// content than a landing page; the page itself is tried if those won't scrape.
 func (s *Scraper) ValidateSource(ctx context.Context, sourceURL, name string) ValidationResult {
 	result := ValidationResult{URL: sourceURL, Name: name}
 
@@ -28,9 +104,14 @@ func (s *Scraper) ValidateSource(ctx context.Context, sourceURL, name string) Va
 
 	// Try RSS discovery for non-Reddit URLs
 	if !reddit.IsRedditURL(sourceURL) {
-		if feedURL := DiscoverRSSFeed(ctx, sourceURL); feedURL != "" {
+		if feedURL := s.DiscoverRSSFeed(ctx, sourceURL); feedURL != "" {
 			result.FeedURL = feedURL
 			testURL = feedURL // validate the feed URL instead
+		} else if !isRSSURL(sourceURL) {
+			if sitemapURL := s.discoverSitemap(ctx, sourceURL); sitemapURL != "" {
+				result.SitemapURL = sitemapURL
+				testURL = sitemapURL
+			}
 		}
 	}
 
@@ -44,19 +125,56 @@ func (s *Scraper) ValidateSource(ctx context.Context, sourceURL, name string) Va
 	valCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
 	defer cancel()
 
-	content, err := s.ScrapeSource(valCtx, testSource)
+	text, pageHTML, err := s.validationScrape(valCtx, testSource)
+	if err != nil && result.SitemapURL != "" {
+		slog.Debug("Sitemap articles failed to scrape, trying the page", "url", sourceURL, "sitemap", result.SitemapURL, "error", err)
+		result.SitemapURL = ""
+		testSource.URL = sourceURL
+		text, pageHTML, err = s.validationScrape(valCtx, testSource)
+	}
 	if err != nil {
 		result.OK = false
 		result.Reason = err.Error()
 		return result
 	}
 
-	if len(content.Content) < 200 {
+	if minChars := s.validationMinChars(); len(text) < minChars {
 		result.OK = false
-		result.Reason = fmt.Sprintf("insufficient content: %d chars", len(content.Content))
+		result.Reason = fmt.Sprintf("insufficient content: %d chars (need %d)", len(text), minChars)
 		return result
 	}
 
+	// A web page can return plenty of text that is still useless to summarize
+	if pageHTML != nil {
+		if reason := pageProblem(pageHTML, text, s.boilerplatePhrases()); reason != "" {
+			result.OK = false
+			result.Reason = reason
+			return result
+		}
+	}
+
 	result.OK = true
 	return result
 }
+
+// validationScrape test-scrapes a source and returns its content. A web page
+// is scraped directly so its HTML is returned too, for pageProblem; feeds,
+// sitemaps, and Reddit go through ScrapeSource and return no HTML.
+func (s *Scraper) validationScrape(ctx context.Context, source models.NewsSource) (string, []byte, error) {
+	if reddit.IsRedditURL(source.URL) || isRSSURL(source.URL) || isSitemapURL(source.URL) {
+		content, err := s.ScrapeSource(ctx, source)
+		if err != nil {
+			return "", nil, err
+		}
+		return content.Content, nil, nil
+	}
+
+	_, text, pageHTML, err := s.scrapePage(ctx, source)
+	if err != nil {
+		return "", nil, err
+	}
+	if len(text) < 100 {
+		return "", nil, fmt.Errorf("insufficient content scraped from %s", source.URL)
+	}
+	return text, pageHTML, nil
+}
@@ -1,11 +1,20 @@
 package server
 
 import (
+	"cmp"
 	"encoding/json"
+	"encoding/xml"
+	"fmt"
 	"log/slog"
 	"math/rand"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/thinkscotty/kibble/internal/database"
+	"github.com/thinkscotty/kibble/internal/models"
 )
 
 func (s *Server) handleAPITopics(w http.ResponseWriter, r *http.Request) {
@@ -20,6 +29,8 @@ func (s *Server) handleAPITopics(w http.ResponseWriter, r *http.Request) {
 		ID        int64  `json:"id"`
 		Name      string `json:"name"`
 		FactCount int    `json:"fact_count"`
+		Overview  string `json:"overview,omitempty"`
+		IsPaused  bool   `json:"is_paused"`
 	}
 
 	var result []topicResp
@@ -29,12 +40,17 @@ func (s *Server) handleAPITopics(w http.ResponseWriter, r *http.Request) {
 			ID:        t.ID,
 			Name:      t.Name,
 			FactCount: count,
+			Overview:  t.Overview,
+			IsPaused:  t.IsPaused,
 		})
 	}
 
 	jsonResponse(w, map[string]any{"topics": result})
 }
 
+// maxAPIFactsPage caps the limit parameter of the facts endpoint.
+const maxAPIFactsPage = 100
+
 func (s *Server) handleAPIFacts(w http.ResponseWriter, r *http.Request) {
 	topicIDStr := r.URL.Query().Get("topic_id")
 	if topicIDStr == "" {
@@ -51,17 +67,40 @@ func (s *Server) handleAPIFacts(w http.ResponseWriter, r *http.Request) {
 	limit := 10
 	if v := r.URL.Query().Get("limit"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
-			limit = n
+			limit = min(n, maxAPIFactsPage)
+		}
+	}
+	offset := 0
+	if v := r.URL.Query().Get("offset"); v != "" {
+		n, err := strconv.Atoi(v)
+		if err != nil || n < 0 {
+			jsonError(w, "Invalid offset", 400)
+			return
 		}
+		offset = n
 	}
 
+	// Archived facts are history most clients don't want
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+
 	topic, err := s.db.GetTopic(topicID)
 	if err != nil {
 		jsonError(w, "Topic not found", 404)
 		return
 	}
 
-	facts, err := s.db.ListFactsByTopic(topicID, limit)
+	var total int
+	if includeArchived {
+		total, err = s.db.CountAllFactsByTopic(topicID)
+	} else {
+		total, err = s.db.CountFactsByTopic(topicID)
+	}
+	if err != nil {
+		slog.Error("API: failed to count facts", "error", err)
+		jsonError(w, "Failed to list facts", 500)
+		return
+	}
+	facts, err := s.db.ListFactsByTopicPage(topicID, limit, offset, includeArchived)
 	if err != nil {
 		slog.Error("API: failed to list facts", "error", err)
 		jsonError(w, "Failed to list facts", 500)
@@ -69,21 +108,95 @@ func (s *Server) handleAPIFacts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type factResp struct {
-		ID      int64  `json:"id"`
-		Content string `json:"content"`
+		ID            int64  `json:"id"`
+		Content       string `json:"content"`
+		SourceTitle   string `json:"source_title,omitempty"`
+		SourceURL     string `json:"source_url,omitempty"`
+		WordCount     int    `json:"word_count"`
+		SequenceIndex int    `json:"sequence_index,omitempty"`
+		Confidence    string `json:"confidence,omitempty"`
+		IsArchived    bool   `json:"is_archived,omitempty"`
 	}
 
 	var factList []factResp
 	for _, f := range facts {
-		factList = append(factList, factResp{ID: f.ID, Content: f.Content})
+		factList = append(factList, factResp{ID: f.ID, Content: f.Content, SourceTitle: f.SourceTitle, SourceURL: f.SourceURL, WordCount: f.WordCount, SequenceIndex: f.SequenceIndex, Confidence: f.Confidence, IsArchived: f.IsArchived})
+	}
+
+	// next_offset is null on the last page
+	var nextOffset *int
+	if next := offset + len(facts); next < total && len(facts) > 0 {
+		nextOffset = &next
 	}
 
 	jsonResponse(w, map[string]any{
-		"topic": topic.Name,
-		"facts": factList,
+		"topic":       topic.Name,
+		"facts":       factList,
+		"total_count": total,
+		"next_offset": nextOffset,
 	})
 }
 
+func (s *Server) handleAPIFactCreate(w http.ResponseWriter, r *http.Request) {
+	topicID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid topic ID", 400)
+		return
+	}
+
+	if _, err := s.db.GetTopic(topicID); err != nil {
+		jsonError(w, "Topic not found", 404)
+		return
+	}
+
+	var req struct {
+		Content string `json:"content"`
+	}
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, 64<<10)).Decode(&req); err != nil {
+		jsonError(w, "Invalid JSON body", 400)
+		return
+	}
+	content := strings.TrimSpace(req.Content)
+	if content == "" {
+		jsonError(w, "content is required", 400)
+		return
+	}
+
+	// Trigrams and the embedding let later AI-generated facts be checked against this one for duplicates
+	trigrams := s.sim.Trigrams(content)
+	fact := &models.Fact{
+		TopicID:   topicID,
+		Content:   content,
+		Trigrams:  s.sim.TrigramsToJSON(trigrams),
+		Embedding: s.embedFact(r.Context(), content),
+		IsCustom:  true,
+		Source:    "api",
+	}
+	if err := s.db.CreateFact(fact); err != nil {
+		slog.Error("API: failed to create fact", "topic_id", topicID, "error", err)
+		jsonError(w, "Failed to create fact", 500)
+		return
+	}
+
+	type factResp struct {
+		ID        int64  `json:"id"`
+		TopicID   int64  `json:"topic_id"`
+		Content   string `json:"content"`
+		IsCustom  bool   `json:"is_custom"`
+		WordCount int    `json:"word_count"`
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(map[string]any{"fact": factResp{
+		ID:        fact.ID,
+		TopicID:   fact.TopicID,
+		Content:   fact.Content,
+		IsCustom:  fact.IsCustom,
+		WordCount: fact.WordCount,
+	}})
+}
+
 func (s *Server) handleAPIAllFacts(w http.ResponseWriter, r *http.Request) {
 	topics, err := s.db.ListActiveTopics()
 	if err != nil || len(topics) == 0 {
@@ -91,9 +204,41 @@ func (s *Server) handleAPIAllFacts(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Optional topic_ids=1,2,3 narrows the response to those topics
+	var topicIDs []int64
+	if v := r.URL.Query().Get("topic_ids"); v != "" {
+		for _, part := range strings.Split(v, ",") {
+			id, err := strconv.ParseInt(strings.TrimSpace(part), 10, 64)
+			if err != nil {
+				jsonError(w, "Invalid topic_ids", 400)
+				return
+			}
+			topicIDs = append(topicIDs, id)
+		}
+	}
+
+	limitPerTopic := 0
+	if v := r.URL.Query().Get("limit_per_topic"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limitPerTopic = n
+		}
+	}
+
+	grouped, err := s.db.AllFactsGroupedByTopic(topicIDs, limitPerTopic)
+	if err != nil {
+		slog.Error("API: failed to list facts", "error", err)
+		jsonError(w, "Failed to list facts", 500)
+		return
+	}
+
 	type factResp struct {
-		ID      int64  `json:"id"`
-		Content string `json:"content"`
+		ID            int64  `json:"id"`
+		Content       string `json:"content"`
+		SourceTitle   string `json:"source_title,omitempty"`
+		SourceURL     string `json:"source_url,omitempty"`
+		WordCount     int    `json:"word_count"`
+		SequenceIndex int    `json:"sequence_index,omitempty"`
+		Confidence    string `json:"confidence,omitempty"`
 	}
 	type topicFacts struct {
 		TopicID   int64      `json:"topic_id"`
@@ -101,16 +246,19 @@ func (s *Server) handleAPIAllFacts(w http.ResponseWriter, r *http.Request) {
 		Facts     []factResp `json:"facts"`
 	}
 
+	wanted := make(map[int64]bool, len(topicIDs))
+	for _, id := range topicIDs {
+		wanted[id] = true
+	}
+
 	var result []topicFacts
 	for _, t := range topics {
-		facts, err := s.db.ListFactsByTopic(t.ID, 100000)
-		if err != nil {
-			slog.Error("API: failed to list facts", "topic_id", t.ID, "error", err)
+		if len(wanted) > 0 && !wanted[t.ID] {
 			continue
 		}
 		var fl []factResp
-		for _, f := range facts {
-			fl = append(fl, factResp{ID: f.ID, Content: f.Content})
+		for _, f := range grouped[t.ID] {
+			fl = append(fl, factResp{ID: f.ID, Content: f.Content, SourceTitle: f.SourceTitle, SourceURL: f.SourceURL, WordCount: f.WordCount, SequenceIndex: f.SequenceIndex, Confidence: f.Confidence})
 		}
 		result = append(result, topicFacts{
 			TopicID:   t.ID,
@@ -118,6 +266,10 @@ func (s *Server) handleAPIAllFacts(w http.ResponseWriter, r *http.Request) {
 			Facts:     fl,
 		})
 	}
+	if len(result) == 0 {
+		jsonError(w, "No matching active topics found", 404)
+		return
+	}
 
 	jsonResponse(w, map[string]any{"topics": result})
 }
@@ -130,8 +282,13 @@ func (s *Server) handleAPIRecentFacts(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type factResp struct {
-		ID      int64  `json:"id"`
-		Content string `json:"content"`
+		ID            int64  `json:"id"`
+		Content       string `json:"content"`
+		SourceTitle   string `json:"source_title,omitempty"`
+		SourceURL     string `json:"source_url,omitempty"`
+		WordCount     int    `json:"word_count"`
+		SequenceIndex int    `json:"sequence_index,omitempty"`
+		Confidence    string `json:"confidence,omitempty"`
 	}
 	type topicFacts struct {
 		TopicID   int64      `json:"topic_id"`
@@ -148,7 +305,7 @@ func (s *Server) handleAPIRecentFacts(w http.ResponseWriter, r *http.Request) {
 		}
 		var fl []factResp
 		for _, f := range facts {
-			fl = append(fl, factResp{ID: f.ID, Content: f.Content})
+			fl = append(fl, factResp{ID: f.ID, Content: f.Content, SourceTitle: f.SourceTitle, SourceURL: f.SourceURL, WordCount: f.WordCount, SequenceIndex: f.SequenceIndex, Confidence: f.Confidence})
 		}
 		result = append(result, topicFacts{
 			TopicID:   t.ID,
@@ -169,9 +326,14 @@ func (s *Server) handleAPIRandomFact(w http.ResponseWriter, r *http.Request) {
 
 	// Collect all facts from active topics
 	type factWithTopic struct {
-		ID      int64  `json:"id"`
-		Topic   string `json:"topic"`
-		Content string `json:"content"`
+		ID            int64  `json:"id"`
+		Topic         string `json:"topic"`
+		Content       string `json:"content"`
+		SourceTitle   string `json:"source_title,omitempty"`
+		SourceURL     string `json:"source_url,omitempty"`
+		WordCount     int    `json:"word_count"`
+		SequenceIndex int    `json:"sequence_index,omitempty"`
+		Confidence    string `json:"confidence,omitempty"`
 	}
 
 	var allFacts []factWithTopic
@@ -179,9 +341,14 @@ func (s *Server) handleAPIRandomFact(w http.ResponseWriter, r *http.Request) {
 		facts, _ := s.db.ListFactsByTopic(t.ID, 100)
 		for _, f := range facts {
 			allFacts = append(allFacts, factWithTopic{
-				ID:      f.ID,
-				Topic:   t.Name,
-				Content: f.Content,
+				ID:            f.ID,
+				Topic:         t.Name,
+				Content:       f.Content,
+				SourceTitle:   f.SourceTitle,
+				SourceURL:     f.SourceURL,
+				WordCount:     f.WordCount,
+				SequenceIndex: f.SequenceIndex,
+				Confidence:    f.Confidence,
 			})
 		}
 	}
@@ -203,11 +370,16 @@ func (s *Server) handleAPIStories(w http.ResponseWriter, r *http.Request) {
 	}
 
 	type storyResp struct {
-		ID          int64  `json:"id"`
-		Title       string `json:"title"`
-		Summary     string `json:"summary"`
-		SourceURL   string `json:"source_url"`
-		SourceTitle string `json:"source_title"`
+		ID          int64     `json:"id"`
+		Title       string    `json:"title"`
+		Summary     string    `json:"summary"`
+		Quote       string    `json:"quote,omitempty"`
+		SourceURL   string    `json:"source_url"`
+		SourceTitle string    `json:"source_title"`
+		Author      string    `json:"author,omitempty"`
+		WordCount   int       `json:"word_count"`
+		Importance  int       `json:"importance"`
+		PublishedAt time.Time `json:"published_at"`
 	}
 	type topicStories struct {
 		TopicID   int64       `json:"topic_id"`
@@ -215,9 +387,10 @@ func (s *Server) handleAPIStories(w http.ResponseWriter, r *http.Request) {
 		Stories   []storyResp `json:"stories"`
 	}
 
+	order := s.storyOrder(r)
 	var result []topicStories
 	for _, nt := range newsTopics {
-		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, 100000)
+		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, 100000, order)
 		if err != nil {
 			slog.Error("API: failed to list stories", "topic_id", nt.ID, "error", err)
 			continue
@@ -228,8 +401,13 @@ func (s *Server) handleAPIStories(w http.ResponseWriter, r *http.Request) {
 				ID:          st.ID,
 				Title:       st.Title,
 				Summary:     st.Summary,
-				SourceURL:   st.SourceURL,
+				Quote:       st.Quote,
+				SourceURL:   visibleSourceURL(nt, st),
 				SourceTitle: st.SourceTitle,
+				Author:      st.Author,
+				WordCount:   st.WordCount,
+				Importance:  st.Importance,
+				PublishedAt: st.PublishedAt,
 			})
 		}
 		result = append(result, topicStories{
@@ -250,11 +428,16 @@ func (s *Server) handleAPIStoriesRecent(w http.ResponseWriter, r *http.Request)
 	}
 
 	type storyResp struct {
-		ID          int64  `json:"id"`
-		Title       string `json:"title"`
-		Summary     string `json:"summary"`
-		SourceURL   string `json:"source_url"`
-		SourceTitle string `json:"source_title"`
+		ID          int64     `json:"id"`
+		Title       string    `json:"title"`
+		Summary     string    `json:"summary"`
+		Quote       string    `json:"quote,omitempty"`
+		SourceURL   string    `json:"source_url"`
+		SourceTitle string    `json:"source_title"`
+		Author      string    `json:"author,omitempty"`
+		WordCount   int       `json:"word_count"`
+		Importance  int       `json:"importance"`
+		PublishedAt time.Time `json:"published_at"`
 	}
 	type topicStories struct {
 		TopicID   int64       `json:"topic_id"`
@@ -262,9 +445,10 @@ func (s *Server) handleAPIStoriesRecent(w http.ResponseWriter, r *http.Request)
 		Stories   []storyResp `json:"stories"`
 	}
 
+	order := s.storyOrder(r)
 	var result []topicStories
 	for _, nt := range newsTopics {
-		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, 100)
+		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, 100, order)
 		if err != nil {
 			slog.Error("API: failed to list stories", "topic_id", nt.ID, "error", err)
 			continue
@@ -275,8 +459,13 @@ func (s *Server) handleAPIStoriesRecent(w http.ResponseWriter, r *http.Request)
 				ID:          st.ID,
 				Title:       st.Title,
 				Summary:     st.Summary,
-				SourceURL:   st.SourceURL,
+				Quote:       st.Quote,
+				SourceURL:   visibleSourceURL(nt, st),
 				SourceTitle: st.SourceTitle,
+				Author:      st.Author,
+				WordCount:   st.WordCount,
+				Importance:  st.Importance,
+				PublishedAt: st.PublishedAt,
 			})
 		}
 		result = append(result, topicStories{
@@ -289,6 +478,26 @@ func (s *Server) handleAPIStoriesRecent(w http.ResponseWriter, r *http.Request)
 	jsonResponse(w, map[string]any{"topics": result})
 }
 
+// storyOrder returns the ?order= query parameter when it names a story order,
+// otherwise the story_order setting.
+func (s *Server) storyOrder(r *http.Request) string {
+	switch order := r.URL.Query().Get("order"); order {
+	case database.StoryOrderCreated, database.StoryOrderPublished, database.StoryOrderImportance:
+		return order
+	}
+	order, _ := s.db.GetSetting("story_order")
+	return order
+}
+
+// visibleSourceURL returns a story's source URL, or "" when its topic hides
+// source links.
+func visibleSourceURL(nt models.NewsTopic, st models.Story) string {
+	if !nt.ShowSourceLink {
+		return ""
+	}
+	return st.SourceURL
+}
+
 func (s *Server) handleAPIRandomStory(w http.ResponseWriter, r *http.Request) {
 	newsTopics, err := s.db.ListActiveNewsTopics()
 	if err != nil || len(newsTopics) == 0 {
@@ -301,21 +510,27 @@ func (s *Server) handleAPIRandomStory(w http.ResponseWriter, r *http.Request) {
 		Topic       string `json:"topic"`
 		Title       string `json:"title"`
 		Summary     string `json:"summary"`
+		Quote       string `json:"quote,omitempty"`
 		SourceURL   string `json:"source_url"`
 		SourceTitle string `json:"source_title"`
+		Author      string `json:"author,omitempty"`
+		WordCount   int    `json:"word_count"`
 	}
 
 	var allStories []storyWithTopic
 	for _, nt := range newsTopics {
-		stories, _ := s.db.ListStoriesByNewsTopic(nt.ID, 100)
+		stories, _ := s.db.ListStoriesByNewsTopic(nt.ID, 100, database.StoryOrderCreated)
 		for _, st := range stories {
 			allStories = append(allStories, storyWithTopic{
 				ID:          st.ID,
 				Topic:       nt.Name,
 				Title:       st.Title,
 				Summary:     st.Summary,
-				SourceURL:   st.SourceURL,
+				Quote:       st.Quote,
+				SourceURL:   visibleSourceURL(nt, st),
 				SourceTitle: st.SourceTitle,
+				Author:      st.Author,
+				WordCount:   st.WordCount,
 			})
 		}
 	}
@@ -329,6 +544,281 @@ func (s *Server) handleAPIRandomStory(w http.ResponseWriter, r *http.Request) {
 	jsonResponse(w, map[string]any{"story": chosen})
 }
 
+// handleAPINewsTopicSources lists a news topic's configured sources, for backup
+// and monitoring scripts.
+func (s *Server) handleAPINewsTopicSources(w http.ResponseWriter, r *http.Request) {
+	topicID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid topic ID", 400)
+		return
+	}
+
+	nt, err := s.db.GetNewsTopic(topicID)
+	if err != nil {
+		jsonError(w, "News topic not found", 404)
+		return
+	}
+
+	sources, err := s.db.GetSourcesForNewsTopic(topicID)
+	if err != nil {
+		slog.Error("API: failed to list news sources", "topic_id", topicID, "error", err)
+		jsonError(w, "Failed to list sources", 500)
+		return
+	}
+
+	type sourceResp struct {
+		ID           int64  `json:"id"`
+		URL          string `json:"url"`
+		Name         string `json:"name"`
+		IsManual     bool   `json:"is_manual"`
+		IsActive     bool   `json:"is_active"`
+		FailureCount int    `json:"failure_count"`
+		LastError    string `json:"last_error"`
+	}
+	sl := []sourceResp{}
+	for _, src := range sources {
+		sl = append(sl, sourceResp{
+			ID:           src.ID,
+			URL:          src.URL,
+			Name:         src.Name,
+			IsManual:     src.IsManual,
+			IsActive:     src.IsActive,
+			FailureCount: src.FailureCount,
+			LastError:    src.LastError,
+		})
+	}
+
+	jsonResponse(w, map[string]any{
+		"topic_id":   nt.ID,
+		"topic_name": nt.Name,
+		"sources":    sl,
+	})
+}
+
+func (s *Server) handleAPIRefreshLog(w http.ResponseWriter, r *http.Request) {
+	q := r.URL.Query()
+
+	var since time.Time
+	if v := q.Get("since"); v != "" {
+		t, err := time.Parse(time.RFC3339, v)
+		if err != nil {
+			t, err = time.Parse("2006-01-02", v)
+		}
+		if err != nil {
+			jsonError(w, "Invalid since (use RFC 3339 or YYYY-MM-DD)", 400)
+			return
+		}
+		since = t
+	}
+
+	topicType := q.Get("type")
+	if topicType != "" && topicType != "facts" && topicType != "news" {
+		jsonError(w, "Invalid type (use facts or news)", 400)
+		return
+	}
+
+	limit := 1000
+	if v := q.Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			limit = min(n, 10000)
+		}
+	}
+
+	logs, err := s.db.ListRefreshLogs(since, q.Get("status"), topicType, limit)
+	if err != nil {
+		slog.Error("API: failed to list refresh log", "error", err)
+		jsonError(w, "Failed to list refresh log", 500)
+		return
+	}
+	if logs == nil {
+		logs = []models.RefreshLog{}
+	}
+
+	jsonResponse(w, map[string]any{"entries": logs})
+}
+
+// RSS 2.0 output types for the topic feeds
+type rssOutput struct {
+	XMLName xml.Name      `xml:"rss"`
+	Version string        `xml:"version,attr"`
+	Channel rssOutChannel `xml:"channel"`
+}
+
+type rssOutChannel struct {
+	Title         string       `xml:"title"`
+	Link          string       `xml:"link"`
+	Description   string       `xml:"description"`
+	LastBuildDate string       `xml:"lastBuildDate,omitempty"`
+	Items         []rssOutItem `xml:"item"`
+}
+
+type rssOutItem struct {
+	Title       string     `xml:"title"`
+	Link        string     `xml:"link,omitempty"`
+	Description string     `xml:"description"`
+	GUID        rssOutGUID `xml:"guid"`
+	PubDate     string     `xml:"pubDate"`
+}
+
+type rssOutGUID struct {
+	IsPermaLink bool   `xml:"isPermaLink,attr"`
+	Value       string `xml:",chardata"`
+}
+
+// maxFeedItems caps the limit parameter of the topic feeds.
+const maxFeedItems = 200
+
+// feedLimit returns the item count for a topic feed from the limit query
+// parameter, defaulting to 50.
+func feedLimit(r *http.Request) int {
+	if v := r.URL.Query().Get("limit"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
+			return min(n, maxFeedItems)
+		}
+	}
+	return 50
+}
+
+// siteURL returns the address the request reached Kibble at, for feed links.
+func siteURL(r *http.Request) string {
+	scheme := "http"
+	if isHTTPS(r) {
+		scheme = "https"
+	}
+	return scheme + "://" + r.Host + "/"
+}
+
+// feedTitle shortens a fact to a one-line feed item title, cutting at a word
+// boundary, or at a character boundary for text without spaces.
+func feedTitle(s string) string {
+	const maxLen = 80
+	s = strings.Join(strings.Fields(s), " ")
+	if len(s) <= maxLen {
+		return s
+	}
+	n := maxLen
+	for n > 0 && !utf8.RuneStart(s[n]) {
+		n--
+	}
+	cut := s[:n]
+	if i := strings.LastIndex(cut, " "); i > 0 {
+		cut = cut[:i]
+	}
+	return cut + "..."
+}
+
+// feedBuildDate returns a feed's lastBuildDate: when its newest item was
+// added, or "" for an empty feed. It only changes with the items, so the
+// ETag of an unchanged feed stays the same between polls.
+func feedBuildDate(created []time.Time) string {
+	var newest time.Time
+	for _, t := range created {
+		if t.After(newest) {
+			newest = t
+		}
+	}
+	if newest.IsZero() {
+		return ""
+	}
+	return newest.UTC().Format(time.RFC1123Z)
+}
+
+func writeRSS(w http.ResponseWriter, channel rssOutChannel) {
+	out, err := xml.MarshalIndent(rssOutput{Version: "2.0", Channel: channel}, "", "  ")
+	if err != nil {
+		slog.Error("API: failed to encode feed", "error", err)
+		jsonError(w, "Failed to encode feed", 500)
+		return
+	}
+	w.Header().Set("Content-Type", "application/rss+xml; charset=utf-8")
+	w.Write([]byte(xml.Header))
+	w.Write(out)
+}
+
+// handleAPITopicFeed serves a fact topic's latest facts as an RSS 2.0 feed.
+func (s *Server) handleAPITopicFeed(w http.ResponseWriter, r *http.Request) {
+	topicID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid topic ID", 400)
+		return
+	}
+
+	topic, err := s.db.GetTopic(topicID)
+	if err != nil {
+		jsonError(w, "Topic not found", 404)
+		return
+	}
+
+	facts, err := s.db.ListFactsByTopic(topicID, feedLimit(r))
+	if err != nil {
+		slog.Error("API: failed to list facts", "topic_id", topicID, "error", err)
+		jsonError(w, "Failed to list facts", 500)
+		return
+	}
+
+	channel := rssOutChannel{
+		Title:       "Kibble: " + topic.Name,
+		Link:        siteURL(r),
+		Description: cmp.Or(topic.Description, "Facts about "+topic.Name),
+	}
+	var created []time.Time
+	for _, f := range facts {
+		created = append(created, f.CreatedAt)
+		channel.Items = append(channel.Items, rssOutItem{
+			Title:       feedTitle(f.Content),
+			Link:        f.SourceURL,
+			Description: f.Content,
+			GUID:        rssOutGUID{Value: fmt.Sprintf("kibble-fact-%d", f.ID)},
+			PubDate:     f.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	channel.LastBuildDate = feedBuildDate(created)
+	writeRSS(w, channel)
+}
+
+// handleAPINewsTopicFeed serves a news topic's latest stories as an RSS 2.0
+// feed, newest first. Items link to their source unless the topic hides
+// source links.
+func (s *Server) handleAPINewsTopicFeed(w http.ResponseWriter, r *http.Request) {
+	topicID, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid topic ID", 400)
+		return
+	}
+
+	nt, err := s.db.GetNewsTopic(topicID)
+	if err != nil {
+		jsonError(w, "News topic not found", 404)
+		return
+	}
+
+	stories, err := s.db.ListStoriesByNewsTopic(topicID, feedLimit(r), database.StoryOrderCreated)
+	if err != nil {
+		slog.Error("API: failed to list stories", "topic_id", topicID, "error", err)
+		jsonError(w, "Failed to list stories", 500)
+		return
+	}
+
+	channel := rssOutChannel{
+		Title:       "Kibble: " + nt.Name,
+		Link:        siteURL(r),
+		Description: cmp.Or(nt.Description, "News about "+nt.Name),
+	}
+	var created []time.Time
+	for _, st := range stories {
+		created = append(created, st.CreatedAt)
+		channel.Items = append(channel.Items, rssOutItem{
+			Title:       st.Title,
+			Link:        visibleSourceURL(nt, st),
+			Description: st.Summary,
+			GUID:        rssOutGUID{Value: fmt.Sprintf("kibble-story-%d", st.ID)},
+			PubDate:     st.CreatedAt.UTC().Format(time.RFC1123Z),
+		})
+	}
+	channel.LastBuildDate = feedBuildDate(created)
+	writeRSS(w, channel)
+}
+
 func jsonResponse(w http.ResponseWriter, data any) {
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(data)
@@ -3,6 +3,7 @@ package server
 import (
 	"log/slog"
 	"net/http"
+	"strconv"
 	"strings"
 	"time"
 
@@ -94,6 +95,13 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 		s.db.DeleteSession(cookie.Value)
 	}
 
+	clearSessionCookie(w, r)
+
+	http.Redirect(w, r, "/login", http.StatusSeeOther)
+}
+
+// clearSessionCookie expires the browser's session cookie.
+func clearSessionCookie(w http.ResponseWriter, r *http.Request) {
 	http.SetCookie(w, &http.Cookie{
 		Name:     "kibble_session",
 		Value:    "",
@@ -103,7 +111,83 @@ func (s *Server) handleLogout(w http.ResponseWriter, r *http.Request) {
 		SameSite: http.SameSiteLaxMode,
 		MaxAge:   -1,
 	})
+}
+
+// currentSession returns the session behind the request's cookie. Handlers
+// behind requireAuth can rely on it succeeding unless the session was just revoked.
+func (s *Server) currentSession(r *http.Request) (models.Session, error) {
+	cookie, err := r.Cookie("kibble_session")
+	if err != nil {
+		return models.Session{}, err
+	}
+	return s.db.GetSession(cookie.Value)
+}
+
+func (s *Server) handleSessionsPage(w http.ResponseWriter, r *http.Request) {
+	current, err := s.currentSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	sessions, err := s.db.ListSessions(current.UserID)
+	if err != nil {
+		slog.Error("Failed to list sessions", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+
+	s.render(w, "sessions", map[string]any{
+		"Page":      "settings",
+		"Sessions":  sessions,
+		"CurrentID": current.ID,
+	})
+}
+
+func (s *Server) handleSessionRevoke(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid session ID", 400)
+		return
+	}
+	current, err := s.currentSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	if err := s.db.DeleteUserSession(current.UserID, id); err != nil {
+		slog.Error("Failed to revoke session", "error", err)
+		http.Error(w, "Failed to revoke session", 500)
+		return
+	}
+	slog.Info("Session revoked", "session_id", id)
+
+	// Revoking the session in use is the same as logging out
+	if id == current.ID {
+		clearSessionCookie(w, r)
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+	http.Redirect(w, r, "/settings/sessions", http.StatusSeeOther)
+}
 
+func (s *Server) handleSessionsRevokeAll(w http.ResponseWriter, r *http.Request) {
+	current, err := s.currentSession(r)
+	if err != nil {
+		http.Redirect(w, r, "/login", http.StatusSeeOther)
+		return
+	}
+
+	n, err := s.db.DeleteUserSessions(current.UserID)
+	if err != nil {
+		slog.Error("Failed to revoke sessions", "error", err)
+		http.Error(w, "Failed to revoke sessions", 500)
+		return
+	}
+	slog.Info("Logged out everywhere", "sessions", n)
+
+	clearSessionCookie(w, r)
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
 
@@ -176,5 +260,14 @@ func (s *Server) handleSetupSubmit(w http.ResponseWriter, r *http.Request) {
 	s.hasUsers.Store(true)
 
 	slog.Info("Admin account created", "username", username)
+
+	if r.FormValue("seed_examples") == "1" {
+		if facts, news, err := s.seedExampleTopics(); err != nil {
+			slog.Error("Failed to seed example topics", "error", err)
+		} else {
+			slog.Info("Seeded example topics", "fact_topics", facts, "news_topics", news)
+		}
+	}
+
 	http.Redirect(w, r, "/login", http.StatusSeeOther)
 }
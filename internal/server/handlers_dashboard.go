@@ -6,17 +6,71 @@ import (
 	"strconv"
 
 	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/scheduler"
 )
 
 func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
-	topics, err := s.db.ListActiveTopics()
+	settings, _ := s.db.GetAllSettings()
+	topicsWithFacts, newsTopicsWithStories, err := s.dashboardTopics(settings)
 	if err != nil {
 		slog.Error("Failed to list active topics", "error", err)
 		http.Error(w, "Internal error", 500)
 		return
 	}
 
+	failedSources, err := s.db.RecentlyFailedSources(10)
+	if err != nil {
+		slog.Error("Failed to list failed sources", "error", err)
+	}
+
+	data := map[string]any{
+		"Page":               "dashboard",
+		"Topics":             topicsWithFacts,
+		"NewsTopics":         newsTopicsWithStories,
+		"FailedSources":      failedSources,
+		"SourceFailureLimit": scheduler.SourceFailureLimit,
+		"AwaitingAPIKey":     s.sched.TopicsAwaitingAPIKey(),
+		"AuthAlert":          s.sched.AuthAlert(),
+		"Settings":           settings,
+	}
+
+	s.render(w, "dashboard", data)
+}
+
+// handlePublicDashboard renders a read-only dashboard of active topics' facts
+// and stories that needs no login, for a shared screen. It only exists while
+// the public_dashboard setting is on, and shows no controls or admin details.
+func (s *Server) handlePublicDashboard(w http.ResponseWriter, r *http.Request) {
 	settings, _ := s.db.GetAllSettings()
+	if settings["public_dashboard"] != "true" {
+		http.NotFound(w, r)
+		return
+	}
+
+	topicsWithFacts, newsTopicsWithStories, err := s.dashboardTopics(settings)
+	if err != nil {
+		slog.Error("Failed to list active topics", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+
+	s.render(w, "public", map[string]any{
+		"Page":       "public",
+		"Topics":     topicsWithFacts,
+		"NewsTopics": newsTopicsWithStories,
+		"Settings":   settings,
+	})
+}
+
+// dashboardTopics loads active fact topics with their latest facts and active
+// news topics with their latest stories, as many of each as the dashboard
+// display settings allow.
+func (s *Server) dashboardTopics(settings map[string]string) ([]models.TopicWithFacts, []models.NewsTopicWithStories, error) {
+	topics, err := s.db.ListActiveTopics()
+	if err != nil {
+		return nil, nil, err
+	}
+
 	limit := 5
 	if v, ok := settings["facts_per_topic_display"]; ok {
 		if n, err := strconv.Atoi(v); err == nil {
@@ -33,14 +87,14 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 		}
 		topicsWithFacts = append(topicsWithFacts, models.TopicWithFacts{
 			Topic: topic,
-			Facts: facts,
+			Facts: seriesOrder(topic, facts),
 		})
 	}
 
 	// Load news topics with stories
 	storiesLimit := 5
 	if v, ok := settings["stories_per_topic_display"]; ok {
-		if n, err := strconv.Atoi(v); err == nil {
+		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			storiesLimit = n
 		}
 	}
@@ -48,7 +102,7 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 	activeNewsTopics, _ := s.db.ListActiveNewsTopics()
 	var newsTopicsWithStories []models.NewsTopicWithStories
 	for _, nt := range activeNewsTopics {
-		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, storiesLimit)
+		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, storiesLimit, settings["story_order"])
 		if err != nil {
 			slog.Error("Failed to list stories", "topic_id", nt.ID, "error", err)
 			continue
@@ -58,13 +112,19 @@ func (s *Server) handleDashboard(w http.ResponseWriter, r *http.Request) {
 			Stories:   stories,
 		})
 	}
+	return topicsWithFacts, newsTopicsWithStories, nil
+}
 
-	data := map[string]any{
-		"Page":       "dashboard",
-		"Topics":     topicsWithFacts,
-		"NewsTopics": newsTopicsWithStories,
-		"Settings":   settings,
+// handleAuthAlertAck clears the authentication alert, resuming scheduled
+// refreshes. htmx requests get an empty body so the alert is swapped out.
+func (s *Server) handleAuthAlertAck(w http.ResponseWriter, r *http.Request) {
+	if err := s.sched.AcknowledgeAuthAlert(); err != nil {
+		slog.Error("Failed to clear authentication alert", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
 	}
-
-	s.render(w, "dashboard", data)
+	if r.Header.Get("HX-Request") == "true" {
+		return
+	}
+	http.Redirect(w, r, "/", http.StatusSeeOther)
 }
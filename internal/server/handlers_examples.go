@@ -0,0 +1,110 @@
+package server
+
+import (
+	"fmt"
+	"log/slog"
+	"net/http"
+	"strings"
+
+	kibble "github.com/thinkscotty/kibble"
+	"github.com/thinkscotty/kibble/internal/config"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// handleSeedExamples creates the bundled example topics. HTMX requests reload the
+// current page; plain form posts are redirected to the topics page.
+func (s *Server) handleSeedExamples(w http.ResponseWriter, r *http.Request) {
+	facts, news, err := s.seedExampleTopics()
+	if err != nil {
+		slog.Error("Failed to seed example topics", "error", err)
+		http.Error(w, "Failed to seed example topics", 500)
+		return
+	}
+
+	slog.Info("Seeded example topics", "fact_topics", facts, "news_topics", news)
+	if r.Header.Get("HX-Request") == "true" {
+		w.Header().Set("HX-Refresh", "true")
+		return
+	}
+	http.Redirect(w, r, "/topics", http.StatusSeeOther)
+}
+
+// seedExampleTopics creates the fact and news topics from examples.yaml,
+// skipping any whose name already exists. Returns the number of each created.
+func (s *Server) seedExampleTopics() (int, int, error) {
+	ex, err := config.ParseExamples(kibble.ExamplesYAML)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	existing := make(map[string]bool)
+	topics, err := s.db.ListTopics()
+	if err != nil {
+		return 0, 0, fmt.Errorf("list topics: %w", err)
+	}
+	for _, t := range topics {
+		existing[strings.ToLower(t.Name)] = true
+	}
+
+	var factCount int
+	for _, e := range ex.FactTopics {
+		if existing[strings.ToLower(e.Name)] {
+			continue
+		}
+		topic := &models.Topic{
+			Name:                   e.Name,
+			Description:            e.Description,
+			IsActive:               true,
+			FactsPerRefresh:        withDefault(e.FactsPerRefresh, 5),
+			RefreshIntervalMinutes: withDefault(e.RefreshIntervalMinutes, 1440),
+			SummaryMinWords:        e.SummaryMinWords,
+			SummaryMaxWords:        e.SummaryMaxWords,
+			IsNiche:                e.IsNiche,
+			UseResearch:            true,
+		}
+		if err := s.db.CreateTopic(topic); err != nil {
+			return factCount, 0, fmt.Errorf("create topic %q: %w", e.Name, err)
+		}
+		factCount++
+	}
+
+	existing = make(map[string]bool)
+	newsTopics, err := s.db.ListNewsTopics()
+	if err != nil {
+		return factCount, 0, fmt.Errorf("list news topics: %w", err)
+	}
+	for _, t := range newsTopics {
+		existing[strings.ToLower(t.Name)] = true
+	}
+
+	var newsCount int
+	for _, e := range ex.NewsTopics {
+		if existing[strings.ToLower(e.Name)] {
+			continue
+		}
+		topic := &models.NewsTopic{
+			Name:                   e.Name,
+			Description:            e.Description,
+			IsActive:               true,
+			StoriesPerRefresh:      withDefault(e.StoriesPerRefresh, 5),
+			RefreshIntervalMinutes: withDefault(e.RefreshIntervalMinutes, 120),
+			SummaryMinWords:        e.SummaryMinWords,
+			SummaryMaxWords:        e.SummaryMaxWords,
+			ShowSourceLink:         true,
+			IsNiche:                e.IsNiche,
+		}
+		if err := s.db.CreateNewsTopic(topic); err != nil {
+			return factCount, newsCount, fmt.Errorf("create news topic %q: %w", e.Name, err)
+		}
+		newsCount++
+	}
+
+	return factCount, newsCount, nil
+}
+
+func withDefault(v, def int) int {
+	if v > 0 {
+		return v
+	}
+	return def
+}
@@ -1,13 +1,31 @@
 package server
 
 import (
+	"context"
 	"log/slog"
 	"net/http"
 	"strconv"
 
 	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/similarity"
 )
 
+// embedFact returns the JSON embedding of a custom fact when similarity_mode is
+// "embedding", so AI-generated facts are checked against its meaning as well
+// as its wording. It returns "" when embeddings are off or the request fails;
+// Backfill Embeddings on the Statistics page fills those in later.
+func (s *Server) embedFact(ctx context.Context, content string) string {
+	if mode, _ := s.db.GetSetting("similarity_mode"); mode != "embedding" {
+		return ""
+	}
+	vec, _, err := s.ai.Embed(ctx, content)
+	if err != nil {
+		slog.Warn("Failed to embed fact, checking trigrams only", "error", err)
+		return ""
+	}
+	return similarity.EmbeddingToJSON(vec)
+}
+
 func (s *Server) handleFactCreate(w http.ResponseWriter, r *http.Request) {
 	content := r.FormValue("content")
 	if content == "" {
@@ -24,11 +42,12 @@ func (s *Server) handleFactCreate(w http.ResponseWriter, r *http.Request) {
 
 	trigrams := s.sim.Trigrams(content)
 	fact := &models.Fact{
-		TopicID:  topicID,
-		Content:  content,
-		Trigrams: s.sim.TrigramsToJSON(trigrams),
-		IsCustom: true,
-		Source:   "user",
+		TopicID:   topicID,
+		Content:   content,
+		Trigrams:  s.sim.TrigramsToJSON(trigrams),
+		Embedding: s.embedFact(r.Context(), content),
+		IsCustom:  true,
+		Source:    "user",
 	}
 
 	if err := s.db.CreateFact(fact); err != nil {
@@ -78,6 +97,7 @@ func (s *Server) handleFactUpdate(w http.ResponseWriter, r *http.Request) {
 	fact.Content = content
 	trigrams := s.sim.Trigrams(content)
 	fact.Trigrams = s.sim.TrigramsToJSON(trigrams)
+	fact.Embedding = s.embedFact(r.Context(), content)
 
 	if err := s.db.UpdateFact(&fact); err != nil {
 		slog.Error("Failed to update fact", "error", err)
@@ -105,6 +125,34 @@ func (s *Server) handleFactDelete(w http.ResponseWriter, r *http.Request) {
 	w.WriteHeader(200)
 }
 
+// handleFactArchive archives a fact, or unarchives it when archived is
+// "false". Archived facts are kept but hidden from the dashboard and the API.
+func (s *Server) handleFactArchive(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid fact ID", 400)
+		return
+	}
+
+	fact, err := s.db.GetFact(id)
+	if err != nil {
+		http.Error(w, "Fact not found", 404)
+		return
+	}
+
+	fact.IsArchived = r.FormValue("archived") != "false"
+	if err := s.db.SetFactArchived(id, fact.IsArchived); err != nil {
+		slog.Error("Failed to archive fact", "error", err)
+		http.Error(w, "Failed to archive fact", 500)
+		return
+	}
+
+	if topic, err := s.db.GetTopic(fact.TopicID); err == nil {
+		fact.TopicName = topic.Name
+	}
+	s.renderPartial(w, "fact_item", &fact)
+}
+
 func (s *Server) handleFactSearch(w http.ResponseWriter, r *http.Request) {
 	query := r.URL.Query().Get("q")
 	if query == "" {
@@ -119,7 +167,8 @@ func (s *Server) handleFactSearch(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
-	facts, err := s.db.SearchFacts(query, topicID)
+	includeArchived := r.URL.Query().Get("include_archived") == "true"
+	facts, err := s.db.SearchFacts(query, topicID, includeArchived)
 	if err != nil {
 		slog.Error("Failed to search facts", "error", err)
 		http.Error(w, "Search failed", 500)
@@ -1,13 +1,21 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"encoding/json"
+	"encoding/xml"
 	"fmt"
 	"log/slog"
 	"net/http"
 	"strconv"
+	"strings"
+	"time"
 
+	"github.com/thinkscotty/kibble/internal/ai"
+	"github.com/thinkscotty/kibble/internal/feeds"
 	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/scheduler"
 	"github.com/thinkscotty/kibble/internal/scraper"
 )
 
@@ -20,20 +28,32 @@ func (s *Server) handleNewsPage(w http.ResponseWriter, r *http.Request) {
 	}
 
 	var topicsWithSources []models.NewsTopicWithSources
+	var needsAttention []string
 	for _, nt := range newsTopics {
 		sources, _ := s.db.GetSourcesForNewsTopic(nt.ID)
+		webhooks, _ := s.db.ListWebhooksForNewsTopic(nt.ID)
 		topicsWithSources = append(topicsWithSources, models.NewsTopicWithSources{
 			NewsTopic: nt,
 			Sources:   sources,
+			Webhooks:  webhooks,
 		})
+		if nt.NeedsAttention {
+			needsAttention = append(needsAttention, nt.Name)
+		}
 	}
 
 	settings, _ := s.db.GetAllSettings()
+	sharedSources, err := s.db.SharedSources()
+	if err != nil {
+		slog.Error("Failed to list shared sources", "error", err)
+	}
 
 	data := map[string]any{
-		"Page":       "news",
-		"NewsTopics": topicsWithSources,
-		"Settings":   settings,
+		"Page":           "news",
+		"NewsTopics":     topicsWithSources,
+		"NeedsAttention": needsAttention,
+		"SharedSources":  sharedSources,
+		"Settings":       settings,
 	}
 	s.render(w, "news", data)
 }
@@ -44,6 +64,9 @@ func (s *Server) handleNewsTopicCreate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Topic name is required", 400)
 		return
 	}
+	if s.rejectDuplicateNewsTopicName(w, name, 0) {
+		return
+	}
 
 	storiesPerRefresh := 5
 	if v := r.FormValue("stories_per_refresh"); v != "" {
@@ -71,6 +94,26 @@ func (s *Server) handleNewsTopicCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var maxSources int
+	if v := r.FormValue("max_sources_per_refresh"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxSources = n
+		}
+	}
+
+	var temperature float64
+	if v := r.FormValue("temperature"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 2 {
+			temperature = f
+		}
+	}
+	var maxTokens int
+	if v := r.FormValue("max_tokens"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxTokens = n
+		}
+	}
+
 	nt := &models.NewsTopic{
 		Name:                   name,
 		Description:            r.FormValue("description"),
@@ -79,7 +122,15 @@ func (s *Server) handleNewsTopicCreate(w http.ResponseWriter, r *http.Request) {
 		RefreshIntervalMinutes: refreshInterval,
 		SummaryMinWords:        summaryMinWords,
 		SummaryMaxWords:        summaryMaxWords,
+		MaxSourcesPerRefresh:   maxSources,
+		Temperature:            temperature,
+		MaxTokens:              maxTokens,
 		AIProvider:             r.FormValue("ai_provider"),
+		DiscoveryProvider:      r.FormValue("discovery_provider"),
+		ContentFocus:           r.FormValue("content_focus"),
+		Voice:                  newsVoice(r.FormValue("voice")),
+		IncludeQuotes:          r.FormValue("include_quotes") == "1",
+		ShowSourceLink:         r.FormValue("show_source_link") == "1",
 		IsNiche:                r.FormValue("is_niche") == "1",
 	}
 
@@ -103,6 +154,25 @@ func (s *Server) handleNewsTopicCreate(w http.ResponseWriter, r *http.Request) {
 	s.renderPartial(w, "news_topic_row", data)
 }
 
+// rejectDuplicateNewsTopicName writes a 409 response and returns true if
+// enforce_unique_topic_names is on and another news topic already uses name.
+func (s *Server) rejectDuplicateNewsTopicName(w http.ResponseWriter, name string, excludeID int64) bool {
+	if v, _ := s.db.GetSetting("enforce_unique_topic_names"); v != "true" {
+		return false
+	}
+	exists, err := s.db.NewsTopicNameExists(name, excludeID)
+	if err != nil {
+		slog.Error("Failed to check news topic name", "error", err)
+		http.Error(w, "Internal error", 500)
+		return true
+	}
+	if exists {
+		http.Error(w, fmt.Sprintf("A news topic named %q already exists", name), http.StatusConflict)
+		return true
+	}
+	return false
+}
+
 func (s *Server) handleNewsTopicEditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
@@ -133,6 +203,9 @@ func (s *Server) handleNewsTopicUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if name := r.FormValue("name"); name != "" {
+		if s.rejectDuplicateNewsTopicName(w, name, id) {
+			return
+		}
 		nt.Name = name
 	}
 	nt.Description = r.FormValue("description")
@@ -147,6 +220,11 @@ func (s *Server) handleNewsTopicUpdate(w http.ResponseWriter, r *http.Request) {
 			nt.RefreshIntervalMinutes = n
 		}
 	}
+	if v := r.FormValue("schedule_offset_minutes"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			nt.ScheduleOffsetMinutes = n
+		}
+	}
 	if v := r.FormValue("summary_min_words"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
 			nt.SummaryMinWords = n
@@ -157,7 +235,27 @@ func (s *Server) handleNewsTopicUpdate(w http.ResponseWriter, r *http.Request) {
 			nt.SummaryMaxWords = n
 		}
 	}
+	if v := r.FormValue("max_sources_per_refresh"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			nt.MaxSourcesPerRefresh = n
+		}
+	}
+	if v := r.FormValue("temperature"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 2 {
+			nt.Temperature = f
+		}
+	}
+	if v := r.FormValue("max_tokens"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			nt.MaxTokens = n
+		}
+	}
 	nt.AIProvider = r.FormValue("ai_provider")
+	nt.DiscoveryProvider = r.FormValue("discovery_provider")
+	nt.ContentFocus = r.FormValue("content_focus")
+	nt.Voice = newsVoice(r.FormValue("voice"))
+	nt.IncludeQuotes = r.FormValue("include_quotes") == "1"
+	nt.ShowSourceLink = r.FormValue("show_source_link") == "1"
 	nt.IsNiche = r.FormValue("is_niche") == "1"
 
 	if err := s.db.UpdateNewsTopic(&nt); err != nil {
@@ -167,9 +265,11 @@ func (s *Server) handleNewsTopicUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
 	data := models.NewsTopicWithSources{
 		NewsTopic: nt,
 		Sources:   sources,
+		Webhooks:  webhooks,
 	}
 	s.renderPartial(w, "news_topic_row", data)
 }
@@ -206,9 +306,41 @@ func (s *Server) handleNewsTopicToggle(w http.ResponseWriter, r *http.Request) {
 
 	nt, _ := s.db.GetNewsTopic(id)
 	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
 	data := models.NewsTopicWithSources{
 		NewsTopic: nt,
 		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+func (s *Server) handleNewsTopicSnooze(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	until, err := snoozeUntil(r.FormValue("duration"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if err := s.db.SnoozeNewsTopic(id, until); err != nil {
+		slog.Error("Failed to snooze news topic", "error", err)
+		http.Error(w, "Failed to snooze news topic", 500)
+		return
+	}
+
+	nt, _ := s.db.GetNewsTopic(id)
+	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
 	}
 	s.renderPartial(w, "news_topic_row", data)
 }
@@ -220,10 +352,158 @@ func (s *Server) handleNewsTopicRefresh(w http.ResponseWriter, r *http.Request)
 		return
 	}
 
-	go s.sched.RefreshNewsNow(context.Background(), id)
+	msg := "Refresh started..."
+	if s.sched.RefreshNewsNow(context.Background(), id) == scheduler.RefreshAlreadyRunning {
+		msg = "Refresh already in progress"
+	}
 
-	w.Header().Set("Content-Type", "text/html; charset=utf-8")
-	fmt.Fprint(w, `<span class="text-success text-sm">Refresh started...</span>`)
+	s.renderPartial(w, "refresh_status", map[string]any{
+		"Running": true,
+		"Message": msg,
+		"PollURL": fmt.Sprintf("/news-topics/%d/refresh/status", id),
+	})
+}
+
+// handleNewsTopicRefreshStatus is polled while a news refresh is in progress and
+// reports the outcome once the topic is unlocked.
+func (s *Server) handleNewsTopicRefreshStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	if s.sched.IsNewsTopicRefreshing(id) {
+		s.renderPartial(w, "refresh_status", map[string]any{
+			"Running": true,
+			"Message": "Refreshing...",
+			"PollURL": fmt.Sprintf("/news-topics/%d/refresh/status", id),
+		})
+		return
+	}
+
+	data := map[string]any{"Message": "Refresh complete"}
+	if status, err := s.db.GetNewsRefreshStatus(id); err == nil && status.Status == "failed" {
+		data["Message"] = "Refresh failed: " + status.ErrorMessage
+		data["Failed"] = true
+	}
+	s.renderPartial(w, "refresh_status", data)
+}
+
+// handleNewsTopicDryRun runs the topic's scrape and summarize pipeline without
+// saving anything and returns the candidate stories and per-source results as JSON.
+func (s *Server) handleNewsTopicDryRun(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		jsonError(w, "Invalid topic ID", 400)
+		return
+	}
+
+	result, err := s.sched.DryRunNews(r.Context(), id)
+	if err != nil {
+		jsonError(w, "News topic not found", 404)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(result)
+}
+
+// handleNewsSuggestFeeds previews the curated feeds that source discovery would
+// suggest for a topic name and description, before the topic is created.
+func (s *Server) handleNewsSuggestFeeds(w http.ResponseWriter, r *http.Request) {
+	name := strings.TrimSpace(r.URL.Query().Get("name"))
+	description := strings.TrimSpace(r.URL.Query().Get("description"))
+	matches := feeds.FindRelevant(name, description)
+
+	if r.Header.Get("HX-Request") == "true" {
+		s.renderPartial(w, "suggested_feeds", map[string]any{
+			"Feeds": matches,
+			"Query": name != "" || description != "",
+		})
+		return
+	}
+
+	type suggestedFeed struct {
+		Name     string `json:"name"`
+		URL      string `json:"url"`
+		Category string `json:"category"`
+	}
+	result := make([]suggestedFeed, 0, len(matches))
+	for _, f := range matches {
+		result = append(result, suggestedFeed{Name: f.Name, URL: f.URL, Category: f.Category})
+	}
+	jsonResponse(w, result)
+}
+
+// OPML 2.0 export types
+type opmlDocument struct {
+	XMLName xml.Name      `xml:"opml"`
+	Version string        `xml:"version,attr"`
+	Title   string        `xml:"head>title"`
+	Created string        `xml:"head>dateCreated"`
+	Body    []opmlOutline `xml:"body>outline"`
+}
+
+type opmlOutline struct {
+	Text     string        `xml:"text,attr"`
+	Title    string        `xml:"title,attr,omitempty"`
+	Type     string        `xml:"type,attr,omitempty"`
+	XMLURL   string        `xml:"xmlUrl,attr,omitempty"`
+	Outlines []opmlOutline `xml:"outline"`
+}
+
+// handleNewsExportOPML downloads every news topic's sources as an OPML 2.0
+// document, with one category outline per topic.
+func (s *Server) handleNewsExportOPML(w http.ResponseWriter, r *http.Request) {
+	newsTopics, err := s.db.ListNewsTopics()
+	if err != nil {
+		slog.Error("Failed to list news topics", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+
+	doc := opmlDocument{
+		Version: "2.0",
+		Title:   "Kibble news sources",
+		Created: time.Now().UTC().Format(time.RFC1123),
+		Body:    []opmlOutline{},
+	}
+	for _, nt := range newsTopics {
+		sources, err := s.db.GetSourcesForNewsTopic(nt.ID)
+		if err != nil {
+			slog.Error("Failed to list news sources", "topic_id", nt.ID, "error", err)
+			http.Error(w, "Internal error", 500)
+			return
+		}
+		category := opmlOutline{Text: nt.Name, Title: nt.Name}
+		for _, src := range sources {
+			name := src.Name
+			if name == "" {
+				name = src.URL
+			}
+			category.Outlines = append(category.Outlines, opmlOutline{
+				Text:   name,
+				Title:  name,
+				Type:   "rss",
+				XMLURL: src.URL,
+			})
+		}
+		doc.Body = append(doc.Body, category)
+	}
+
+	out, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		slog.Error("Failed to encode OPML", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+	w.Header().Set("Content-Type", "text/x-opml; charset=utf-8")
+	w.Header().Set("Content-Disposition", `attachment; filename="kibble-sources.opml"`)
+	w.Write([]byte(xml.Header))
+	w.Write(out)
 }
 
 func (s *Server) handleNewsTopicDiscover(w http.ResponseWriter, r *http.Request) {
@@ -242,9 +522,11 @@ func (s *Server) handleNewsTopicDiscover(w http.ResponseWriter, r *http.Request)
 	// Return updated sources list
 	nt, _ := s.db.GetNewsTopic(id)
 	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
 	data := models.NewsTopicWithSources{
 		NewsTopic: nt,
 		Sources:   sources,
+		Webhooks:  webhooks,
 	}
 	s.renderPartial(w, "news_topic_row", data)
 }
@@ -270,18 +552,136 @@ func (s *Server) handleNewsSourceAdd(w http.ResponseWriter, r *http.Request) {
 		name = url
 	}
 
-	if _, err := s.db.AddNewsSource(id, url, name, true); err != nil {
+	sourceID, err := s.db.AddNewsSource(id, url, name, true)
+	if err != nil {
 		slog.Error("Failed to add news source", "error", err)
 		http.Error(w, "Failed to add source", 500)
 		return
 	}
+	if r.FormValue("force_feed") == "1" {
+		if err := s.db.SetNewsSourceForceFeed(sourceID, true); err != nil {
+			slog.Error("Failed to mark news source as feed", "error", err)
+		}
+	}
+	// A source added by hand is the intervention a needs-attention topic asks for
+	if err := s.db.ClearNewsTopicAttention(id); err != nil {
+		slog.Error("Failed to clear news topic attention", "error", err)
+	}
 
 	// Return updated topic row with sources
 	nt, _ := s.db.GetNewsTopic(id)
 	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+func (s *Server) handleNewsSourceForceFeed(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid source ID", 400)
+		return
+	}
+
+	source, err := s.db.GetNewsSource(id)
+	if err != nil {
+		http.Error(w, "Source not found", 404)
+		return
+	}
+
+	if err := s.db.SetNewsSourceForceFeed(id, r.FormValue("force_feed") == "true"); err != nil {
+		slog.Error("Failed to update news source", "error", err)
+		http.Error(w, "Failed to update source", 500)
+		return
+	}
+
+	nt, _ := s.db.GetNewsTopic(source.NewsTopicID)
+	sources, _ := s.db.GetSourcesForNewsTopic(source.NewsTopicID)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(source.NewsTopicID)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+// handleNewsSourceFetchOptions sets a source's request timeout and how many
+// times transient fetch failures are retried before they count as a failure.
+func (s *Server) handleNewsSourceFetchOptions(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid source ID", 400)
+		return
+	}
+
+	source, err := s.db.GetNewsSource(id)
+	if err != nil {
+		http.Error(w, "Source not found", 404)
+		return
+	}
+
+	timeoutSeconds, err := strconv.Atoi(cmp.Or(r.FormValue("timeout_seconds"), "0"))
+	if err != nil || timeoutSeconds < 0 || timeoutSeconds > 300 {
+		http.Error(w, "Timeout must be between 0 and 300 seconds", 400)
+		return
+	}
+	maxRetries, err := strconv.Atoi(cmp.Or(r.FormValue("max_retries"), "0"))
+	if err != nil || maxRetries < 0 || maxRetries > 5 {
+		http.Error(w, "Retries must be between 0 and 5", 400)
+		return
+	}
+
+	if err := s.db.SetNewsSourceFetchOptions(id, timeoutSeconds, maxRetries); err != nil {
+		slog.Error("Failed to update news source", "error", err)
+		http.Error(w, "Failed to update source", 500)
+		return
+	}
+
+	nt, _ := s.db.GetNewsTopic(source.NewsTopicID)
+	sources, _ := s.db.GetSourcesForNewsTopic(source.NewsTopicID)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(source.NewsTopicID)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+// handleNewsSourceResetFailures clears a source's failure count and last error and
+// reactivates it, rescuing a good source that failed during a transient outage.
+func (s *Server) handleNewsSourceResetFailures(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid source ID", 400)
+		return
+	}
+
+	source, err := s.db.GetNewsSource(id)
+	if err != nil {
+		http.Error(w, "Source not found", 404)
+		return
+	}
+
+	if err := s.db.UpdateNewsSourceStatus(id, true, 0, ""); err != nil {
+		slog.Error("Failed to reset news source failures", "error", err)
+		http.Error(w, "Failed to update source", 500)
+		return
+	}
+	slog.Info("Reset news source failures", "source", source.URL, "failure_count", source.FailureCount)
+
+	nt, _ := s.db.GetNewsTopic(source.NewsTopicID)
+	sources, _ := s.db.GetSourcesForNewsTopic(source.NewsTopicID)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(source.NewsTopicID)
 	data := models.NewsTopicWithSources{
 		NewsTopic: nt,
 		Sources:   sources,
+		Webhooks:  webhooks,
 	}
 	s.renderPartial(w, "news_topic_row", data)
 }
@@ -301,3 +701,75 @@ func (s *Server) handleNewsSourceDelete(w http.ResponseWriter, r *http.Request)
 
 	w.WriteHeader(200)
 }
+
+func (s *Server) handleWebhookAdd(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	url := strings.TrimSpace(r.FormValue("url"))
+	if url == "" {
+		http.Error(w, "URL is required", 400)
+		return
+	}
+	if err := scraper.ValidateURL(url); err != nil {
+		http.Error(w, "Invalid URL: "+err.Error(), 400)
+		return
+	}
+
+	if _, err := s.db.AddWebhook(id, url); err != nil {
+		slog.Error("Failed to add webhook", "error", err)
+		http.Error(w, "Failed to add webhook", 500)
+		return
+	}
+
+	nt, _ := s.db.GetNewsTopic(id)
+	sources, _ := s.db.GetSourcesForNewsTopic(id)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(id)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+func (s *Server) handleWebhookDelete(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid webhook ID", 400)
+		return
+	}
+
+	topicID, err := s.db.GetWebhookTopicID(id)
+	if err != nil {
+		http.Error(w, "Webhook not found", 404)
+		return
+	}
+	if err := s.db.DeleteWebhook(id); err != nil {
+		slog.Error("Failed to delete webhook", "error", err)
+		http.Error(w, "Failed to delete webhook", 500)
+		return
+	}
+
+	nt, _ := s.db.GetNewsTopic(topicID)
+	sources, _ := s.db.GetSourcesForNewsTopic(topicID)
+	webhooks, _ := s.db.ListWebhooksForNewsTopic(topicID)
+	data := models.NewsTopicWithSources{
+		NewsTopic: nt,
+		Sources:   sources,
+		Webhooks:  webhooks,
+	}
+	s.renderPartial(w, "news_topic_row", data)
+}
+
+// newsVoice returns the submitted editorial voice if it is a preset, otherwise
+// ai.VoiceCustom.
+func newsVoice(v string) string {
+	if ai.IsVoice(v) {
+		return v
+	}
+	return ai.VoiceCustom
+}
@@ -3,10 +3,18 @@ package server
 import (
 	"fmt"
 	"html/template"
+	"io"
 	"log/slog"
 	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
 
+	"github.com/thinkscotty/kibble/internal/ai"
 	"github.com/thinkscotty/kibble/internal/apikey"
+	"github.com/thinkscotty/kibble/internal/scheduler"
+	"github.com/thinkscotty/kibble/internal/scraper"
 )
 
 func (s *Server) handleSettingsPage(w http.ResponseWriter, r *http.Request) {
@@ -53,17 +61,63 @@ func (s *Server) handleSettingsUpdate(w http.ResponseWriter, r *http.Request) {
 		"ollama_model",
 		"chutes_api_key",
 		"chutes_model",
+		"openai_api_key",
+		"openai_model",
 		"ai_custom_instructions",
 		"ai_tone_instructions",
 		"news_sourcing_instructions",
 		"news_summarizing_instructions",
 		"news_tone_instructions",
 		"theme_mode",
+		"theme_rotation_minutes",
 		"text_size",
 		"card_columns",
+		"public_dashboard",
 		"facts_per_topic_display",
 		"stories_per_topic_display",
 		"similarity_threshold",
+		"similarity_mode",
+		"dedup_scope",
+		"embedding_provider",
+		"enforce_max_words",
+		"feed_content_mode",
+		"feed_max_bytes",
+		"scrape_max_depth",
+		"news_relaxed_retry",
+		"ai_cache_ttl_minutes",
+		"ai_max_retries",
+		"fact_refresh_concurrency",
+		"news_refresh_concurrency",
+		"startup_jitter_minutes",
+		"update_require_signature",
+		"update_channel",
+		"backup_keep",
+		"fact_shortfall_retries",
+		"news_max_per_domain",
+		"wiki_search_concurrency",
+		"news_discovery_cooldown_hours",
+		"bulk_validate_concurrency",
+		"source_validation_min_chars",
+		"source_grace_hours",
+		"news_shared_scrape",
+		"feed_parse_authors",
+		"feed_decode_entities",
+		"max_total_stories",
+		"story_order",
+		"enforce_unique_topic_names",
+		"archive_custom_facts",
+		"fact_relevance_check",
+		"fact_relevance_threshold",
+		"auth_failure_threshold",
+		"auth_failure_window_minutes",
+		"snapshot_interval_minutes",
+	}
+
+	// A new API key is taken as the fix for an authentication alert
+	apiKeySettings := []string{"gemini_api_key", "chutes_api_key", "openai_api_key"}
+	oldKeys := make(map[string]string)
+	for _, key := range apiKeySettings {
+		oldKeys[key], _ = s.db.GetSetting(key)
 	}
 
 	for _, key := range settingsKeys {
@@ -79,6 +133,122 @@ func (s *Server) handleSettingsUpdate(w http.ResponseWriter, r *http.Request) {
 		s.db.SetSetting("theme_mode", r.FormValue("theme_mode"))
 	}
 
+	// Checkbox lists come with a hidden empty field, so unchecking every box still
+	// reaches here and clears the setting (turning theme rotation or cleanup off)
+	for _, key := range []string{"theme_rotation", "content_cleanup"} {
+		if !r.Form.Has(key) {
+			continue
+		}
+		var values []string
+		for _, v := range r.Form[key] {
+			if v != "" {
+				values = append(values, v)
+			}
+		}
+		s.db.SetSetting(key, strings.Join(values, ","))
+	}
+
+	for _, key := range apiKeySettings {
+		if v := r.FormValue(key); v != "" && v != oldKeys[key] {
+			if err := s.sched.AcknowledgeAuthAlert(); err != nil {
+				slog.Error("Failed to clear authentication alert", "error", err)
+			}
+			break
+		}
+	}
+
+	// The alert webhook and the snapshot and backup directories are saved even
+	// when empty, since clearing them turns notifications, snapshots, and
+	// backups off
+	for _, key := range []string{"alert_webhook_url", "snapshot_dir", "backup_dir"} {
+		if r.Form.Has(key) {
+			s.db.SetSetting(key, strings.TrimSpace(r.FormValue(key)))
+		}
+	}
+
+	// Per-purpose providers are saved even when empty, since "" means use the primary provider
+	for _, key := range []string{"discovery_provider", "discovery_fallback_provider", "ai_provider_facts", "ai_provider_news"} {
+		if r.Form.Has(key) {
+			s.db.SetSetting(key, r.FormValue(key))
+		}
+	}
+
+	// Provider weights are saved even when empty, since clearing them turns
+	// weighted selection off. An invalid value is rejected and the old one kept.
+	var weightsErr error
+	if r.Form.Has("ai_provider_weights") {
+		value := strings.TrimSpace(r.FormValue("ai_provider_weights"))
+		if _, weightsErr = ai.ParseProviderWeights(value); weightsErr == nil {
+			s.db.SetSetting("ai_provider_weights", value)
+		}
+	}
+
+	// Cost rates are saved even when empty, since clearing them stops cost
+	// estimates. An invalid value is rejected and the old one kept.
+	var costErr error
+	for _, key := range []string{"ai_cost_per_1k_input", "ai_cost_per_1k_output"} {
+		if !r.Form.Has(key) {
+			continue
+		}
+		value := strings.TrimSpace(r.FormValue(key))
+		if _, err := ai.ParseCostRates(value); err != nil {
+			costErr = err
+			continue
+		}
+		s.db.SetSetting(key, value)
+	}
+
+	// embedding_model is saved even when empty, since "" means the provider's default model
+	if r.Form.Has("embedding_model") {
+		s.db.SetSetting("embedding_model", strings.TrimSpace(r.FormValue("embedding_model")))
+	}
+
+	// The scraper proxy is saved even when empty, since clearing it turns the
+	// proxy off. An invalid URL is rejected and the old one kept.
+	var proxyErr error
+	if r.Form.Has("scraper_proxy_url") {
+		value := strings.TrimSpace(r.FormValue("scraper_proxy_url"))
+		if _, proxyErr = scraper.ParseProxyURL(value); proxyErr == nil {
+			s.db.SetSetting("scraper_proxy_url", value)
+		}
+	}
+	if r.Form.Has("scraper_no_proxy") {
+		s.db.SetSetting("scraper_no_proxy", strings.TrimSpace(r.FormValue("scraper_no_proxy")))
+	}
+
+	// User-Agent overrides are saved even when empty, since clearing them
+	// restores Kibble's own User-Agent
+	for _, key := range []string{"scraper_user_agent", "scraper_user_agents"} {
+		if r.Form.Has(key) {
+			s.db.SetSetting(key, strings.TrimSpace(r.FormValue(key)))
+		}
+	}
+
+	// Boilerplate phrases are saved even when empty, since clearing them turns filtering off
+	if r.Form.Has("scrape_boilerplate_phrases") {
+		s.db.SetSetting("scrape_boilerplate_phrases", strings.TrimSpace(r.FormValue("scrape_boilerplate_phrases")))
+	}
+
+	// ai_audit_log is saved even when empty, since clearing it turns auditing
+	// off. A path to a file that isn't an audit log is rejected and the old one kept.
+	var auditErr error
+	if r.Form.Has("ai_audit_log") {
+		value := strings.TrimSpace(r.FormValue("ai_audit_log"))
+		if value != "" {
+			auditErr = ai.CheckAuditLogPath(value)
+		}
+		if auditErr == nil {
+			s.db.SetSetting("ai_audit_log", value)
+		}
+	}
+
+	// Prompt policy text is saved even when empty, so it can be removed
+	for _, key := range []string{"global_prompt_prefix", "global_prompt_suffix"} {
+		if r.Form.Has(key) {
+			s.db.SetSetting(key, strings.TrimSpace(r.FormValue(key)))
+		}
+	}
+
 	// Return success indicator for HTMX
 	w.Header().Set("HX-Trigger", "settings-saved")
 	settings, _ := s.db.GetAllSettings()
@@ -87,6 +257,22 @@ func (s *Server) handleSettingsUpdate(w http.ResponseWriter, r *http.Request) {
 		"Settings": settings,
 		"Success":  "Settings saved successfully",
 	}
+	var warnings []string
+	if weightsErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Provider weights were not saved: %v.", weightsErr))
+	}
+	if costErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Cost rates were not saved: %v.", costErr))
+	}
+	if proxyErr != nil {
+		warnings = append(warnings, fmt.Sprintf("Scraper proxy was not saved: %v.", proxyErr))
+	}
+	if auditErr != nil {
+		warnings = append(warnings, fmt.Sprintf("AI audit log path was not saved: %v.", auditErr))
+	}
+	if len(warnings) > 0 {
+		data["Warning"] = strings.Join(warnings, " ")
+	}
 	s.render(w, "settings", data)
 }
 
@@ -194,6 +380,28 @@ func (s *Server) handleChutesTest(w http.ResponseWriter, r *http.Request) {
 	w.Write([]byte(`<span class="text-success">API key is valid!</span>`))
 }
 
+func (s *Server) handleOpenAITest(w http.ResponseWriter, r *http.Request) {
+	apiKey := r.FormValue("openai_api_key")
+	if apiKey == "" {
+		w.Write([]byte(`<span class="text-error">Please enter an API key first</span>`))
+		return
+	}
+
+	// Save the model so the test uses the current value
+	if model := r.FormValue("openai_model"); model != "" {
+		s.db.SetSetting("openai_model", model)
+	}
+
+	err := s.ai.TestOpenAIKey(r.Context(), apiKey)
+	if err != nil {
+		slog.Error("OpenAI API key test failed", "error", err)
+		w.Write([]byte(`<span class="text-error">API key test failed: ` + template.HTMLEscapeString(err.Error()) + `</span>`))
+		return
+	}
+
+	w.Write([]byte(`<span class="text-success">API key is valid!</span>`))
+}
+
 func (s *Server) handleAPIKeyRegenerate(w http.ResponseWriter, r *http.Request) {
 	newKey, err := apikey.Generate()
 	if err != nil {
@@ -213,3 +421,67 @@ func (s *Server) handleAPIKeyRegenerate(w http.ResponseWriter, r *http.Request)
 		<span class="text-success text-sm" style="margin-left: 0.5rem;">Key regenerated!</span>`,
 		template.HTMLEscapeString(newKey))
 }
+
+func (s *Server) handleAPIWriteKeyRegenerate(w http.ResponseWriter, r *http.Request) {
+	newKey, err := apikey.Generate()
+	if err != nil {
+		slog.Error("Failed to generate write API key", "error", err)
+		http.Error(w, "Failed to generate key", 500)
+		return
+	}
+
+	if err := s.db.SetSetting("api_write_key", newKey); err != nil {
+		slog.Error("Failed to save write API key", "error", err)
+		http.Error(w, "Failed to save key", 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprintf(w, `<code id="api-write-key-value" style="word-break: break-all;">%s</code>
+		<span class="text-success text-sm" style="margin-left: 0.5rem;">Write key generated!</span>`,
+		template.HTMLEscapeString(newKey))
+}
+
+func (s *Server) handleAPIWriteKeyRevoke(w http.ResponseWriter, r *http.Request) {
+	if err := s.db.SetSetting("api_write_key", ""); err != nil {
+		slog.Error("Failed to revoke write API key", "error", err)
+		http.Error(w, "Failed to revoke key", 500)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	fmt.Fprint(w, `<code id="api-write-key-value" class="text-muted">Disabled</code>`)
+}
+
+// handleBackupDownload sends a fresh backup of the database as a download.
+func (s *Server) handleBackupDownload(w http.ResponseWriter, r *http.Request) {
+	dir, err := os.MkdirTemp("", "kibble-backup-")
+	if err != nil {
+		slog.Error("Failed to create backup directory", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+	defer os.RemoveAll(dir)
+
+	name := scheduler.BackupFileName(time.Now())
+	path := filepath.Join(dir, name)
+	if err := s.db.Backup(path); err != nil {
+		slog.Error("Failed to back up database", "error", err)
+		http.Error(w, "Backup failed: "+err.Error(), 500)
+		return
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		slog.Error("Failed to open database backup", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+	defer f.Close()
+	if info, err := f.Stat(); err == nil {
+		w.Header().Set("Content-Length", fmt.Sprint(info.Size()))
+	}
+	w.Header().Set("Content-Type", "application/vnd.sqlite3")
+	w.Header().Set("Content-Disposition", `attachment; filename="`+name+`"`)
+	io.Copy(w, f)
+}
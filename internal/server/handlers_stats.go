@@ -1,8 +1,14 @@
 package server
 
 import (
+	"encoding/json"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"time"
+
+	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/similarity"
 )
 
 func (s *Server) handleStatsPage(w http.ResponseWriter, r *http.Request) {
@@ -23,11 +29,197 @@ func (s *Server) handleStatsPage(w http.ResponseWriter, r *http.Request) {
 		slog.Error("Failed to get refresh logs", "error", err)
 	}
 
+	monthlySpend, err := s.db.MonthlySpend()
+	if err != nil {
+		slog.Error("Failed to get monthly spend", "error", err)
+	}
+
 	data := map[string]any{
-		"Page":        "stats",
-		"Stats":       stats,
-		"RecentUsage": recentUsage,
-		"RefreshLogs": refreshLogs,
+		"Page":         "stats",
+		"Stats":        stats,
+		"RecentUsage":  recentUsage,
+		"RefreshLogs":  refreshLogs,
+		"MonthlySpend": monthlySpend,
 	}
 	s.render(w, "stats", data)
 }
+
+func (s *Server) handleHealthPage(w http.ResponseWriter, r *http.Request) {
+	report, err := s.db.TopicHealthReport()
+	if err != nil {
+		slog.Error("Failed to build health report", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+
+	var overdue, failing int
+	for _, h := range report {
+		if h.Overdue {
+			overdue++
+		}
+		if h.LastStatus == "error" {
+			failing++
+		}
+	}
+
+	data := map[string]any{
+		"Page":    "stats",
+		"Health":  report,
+		"Overdue": overdue,
+		"Failing": failing,
+	}
+	s.render(w, "health", data)
+}
+
+// handleSchema reports the database's migration status and live schema as JSON,
+// so upgrades can be audited.
+func (s *Server) handleSchema(w http.ResponseWriter, r *http.Request) {
+	current, latest, err := s.db.SchemaVersion()
+	if err != nil {
+		slog.Error("Failed to get schema version", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+	migrations, err := s.db.ListMigrations()
+	if err != nil {
+		slog.Error("Failed to list migrations", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+	objects, err := s.db.ListSchemaObjects()
+	if err != nil {
+		slog.Error("Failed to read schema", "error", err)
+		http.Error(w, "Internal error", 500)
+		return
+	}
+
+	pending := 0
+	for _, m := range migrations {
+		if m.AppliedAt == nil {
+			pending++
+		}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Version       int                      `json:"version"`
+		LatestVersion int                      `json:"latest_version"`
+		Pending       int                      `json:"pending"`
+		Migrations    []models.SchemaMigration `json:"migrations"`
+		Schema        []models.SchemaObject    `json:"schema"`
+	}{current, latest, pending, migrations, objects})
+}
+
+// trigramRebuildBatch is how many facts handleRebuildTrigrams updates per transaction.
+const trigramRebuildBatch = 500
+
+// handleRebuildTrigrams recomputes every fact's stored trigrams with the current
+// similarity settings, so duplicate checks stay consistent after the n-gram size
+// changes. htmx requests get a status line; others get a JSON summary.
+func (s *Server) handleRebuildTrigrams(w http.ResponseWriter, r *http.Request) {
+	start := time.Now()
+	compute := func(content string) string {
+		return s.sim.TrigramsToJSON(s.sim.Trigrams(content))
+	}
+	batches := 0
+	progress := func(done, total int) {
+		batches++
+		slog.Info("Rebuilding fact trigrams", "done", done, "total", total)
+	}
+
+	updated, err := s.db.RebuildFactTrigrams(compute, trigramRebuildBatch, progress)
+	if err != nil {
+		slog.Error("Failed to rebuild trigrams", "updated", updated, "error", err)
+		http.Error(w, "Failed to rebuild trigrams", 500)
+		return
+	}
+	elapsed := time.Since(start)
+	slog.Info("Rebuilt fact trigrams", "facts", updated, "batches", batches, "duration", elapsed)
+
+	if r.Header.Get("HX-Request") == "true" {
+		fmt.Fprintf(w, `<span class="text-success">Rebuilt trigrams for %d facts.</span>`, updated)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Facts      int   `json:"facts"`
+		Batches    int   `json:"batches"`
+		DurationMs int64 `json:"duration_ms"`
+	}{updated, batches, elapsed.Milliseconds()})
+}
+
+// handleBackfillEmbeddings embeds every unarchived fact that has no embedding,
+// so facts saved before similarity_mode was "embedding" take part in paraphrase
+// checks. It needs the embedding similarity mode, and stops at the first failed
+// request; running it again picks up where it stopped. htmx requests get a
+// status line; others get a JSON summary.
+func (s *Server) handleBackfillEmbeddings(w http.ResponseWriter, r *http.Request) {
+	if mode, _ := s.db.GetSetting("similarity_mode"); mode != "embedding" {
+		http.Error(w, "Set the similarity mode to embedding first", 400)
+		return
+	}
+
+	start := time.Now()
+	tokens := 0
+	embed := func(content string) (string, error) {
+		vec, used, err := s.ai.Embed(r.Context(), content)
+		tokens += used
+		if err != nil {
+			return "", err
+		}
+		return similarity.EmbeddingToJSON(vec), nil
+	}
+	progress := func(done, total int) {
+		if done%100 == 0 || done == total {
+			slog.Info("Backfilling fact embeddings", "done", done, "total", total)
+		}
+	}
+
+	updated, err := s.db.BackfillFactEmbeddings(embed, progress)
+	if err != nil {
+		slog.Error("Failed to backfill embeddings", "updated", updated, "error", err)
+		http.Error(w, fmt.Sprintf("Embedded %d facts, then failed: %v", updated, err), 500)
+		return
+	}
+	elapsed := time.Since(start)
+	slog.Info("Backfilled fact embeddings", "facts", updated, "tokens", tokens, "duration", elapsed)
+
+	if r.Header.Get("HX-Request") == "true" {
+		fmt.Fprintf(w, `<span class="text-success">Embedded %d facts.</span>`, updated)
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(struct {
+		Facts      int   `json:"facts"`
+		Tokens     int   `json:"tokens"`
+		DurationMs int64 `json:"duration_ms"`
+	}{updated, tokens, elapsed.Milliseconds()})
+}
+
+// handleMaintenance runs the cleanup passes immediately instead of waiting for
+// the scheduler. htmx requests get a status line; others get a JSON summary.
+func (s *Server) handleMaintenance(w http.ResponseWriter, r *http.Request) {
+	report, err := s.sched.RunMaintenance()
+	if err != nil {
+		slog.Error("Failed to run maintenance", "error", err)
+		http.Error(w, "Maintenance failed: "+err.Error(), 500)
+		return
+	}
+
+	if r.Header.Get("HX-Request") == "true" {
+		fmt.Fprintf(w, `<span class="text-success">Removed %d expired sessions, %d old refresh log entries, and %d stories. Database size: %s → %s.</span>`,
+			report.SessionsDeleted, report.RefreshLogsDeleted, report.StoriesPruned,
+			formatBytes(report.SizeBeforeBytes), formatBytes(report.SizeAfterBytes))
+		return
+	}
+	w.Header().Set("Content-Type", "application/json")
+	enc := json.NewEncoder(w)
+	enc.SetIndent("", "  ")
+	enc.Encode(report)
+}
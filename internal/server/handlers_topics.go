@@ -1,13 +1,21 @@
 package server
 
 import (
+	"cmp"
 	"context"
+	"database/sql"
 	"encoding/json"
+	"errors"
+	"fmt"
 	"log/slog"
 	"net/http"
+	"slices"
 	"strconv"
+	"time"
 
+	"github.com/thinkscotty/kibble/internal/database"
 	"github.com/thinkscotty/kibble/internal/models"
+	"github.com/thinkscotty/kibble/internal/scheduler"
 )
 
 func (s *Server) handleTopicsPage(w http.ResponseWriter, r *http.Request) {
@@ -31,6 +39,9 @@ func (s *Server) handleTopicCreate(w http.ResponseWriter, r *http.Request) {
 		http.Error(w, "Topic name is required", 400)
 		return
 	}
+	if s.rejectDuplicateTopicName(w, name, 0) {
+		return
+	}
 
 	factsPerRefresh := 5
 	if v := r.FormValue("facts_per_refresh"); v != "" {
@@ -39,6 +50,33 @@ func (s *Server) handleTopicCreate(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 
+	var targetFactCount int
+	if v := r.FormValue("target_fact_count"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			targetFactCount = n
+		}
+	}
+
+	var maxFacts int
+	if v := r.FormValue("max_facts"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxFacts = n
+		}
+	}
+
+	var temperature float64
+	if v := r.FormValue("temperature"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 2 {
+			temperature = f
+		}
+	}
+	var maxTokens int
+	if v := r.FormValue("max_tokens"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			maxTokens = n
+		}
+	}
+
 	refreshInterval := 1440
 	if v := r.FormValue("refresh_interval_minutes"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
@@ -63,11 +101,18 @@ func (s *Server) handleTopicCreate(w http.ResponseWriter, r *http.Request) {
 		Description:            r.FormValue("description"),
 		IsActive:               true,
 		FactsPerRefresh:        factsPerRefresh,
+		TargetFactCount:        targetFactCount,
+		MaxFacts:               maxFacts,
+		Temperature:            temperature,
+		MaxTokens:              maxTokens,
 		RefreshIntervalMinutes: refreshInterval,
 		SummaryMinWords:        summaryMinWords,
 		SummaryMaxWords:        summaryMaxWords,
 		AIProvider:             r.FormValue("ai_provider"),
 		IsNiche:                r.FormValue("is_niche") == "1",
+		UseResearch:            r.FormValue("use_research") == "1",
+		RequireVerifiable:      r.FormValue("require_verifiable") == "1",
+		SeriesMode:             r.FormValue("series_mode") == "1",
 	}
 
 	if err := s.db.CreateTopic(topic); err != nil {
@@ -79,6 +124,25 @@ func (s *Server) handleTopicCreate(w http.ResponseWriter, r *http.Request) {
 	s.renderPartial(w, "topic_row", topic)
 }
 
+// rejectDuplicateTopicName writes a 409 response and returns true if
+// enforce_unique_topic_names is on and another fact topic already uses name.
+func (s *Server) rejectDuplicateTopicName(w http.ResponseWriter, name string, excludeID int64) bool {
+	if v, _ := s.db.GetSetting("enforce_unique_topic_names"); v != "true" {
+		return false
+	}
+	exists, err := s.db.TopicNameExists(name, excludeID)
+	if err != nil {
+		slog.Error("Failed to check topic name", "error", err)
+		http.Error(w, "Internal error", 500)
+		return true
+	}
+	if exists {
+		http.Error(w, fmt.Sprintf("A topic named %q already exists", name), http.StatusConflict)
+		return true
+	}
+	return false
+}
+
 func (s *Server) handleTopicEditForm(w http.ResponseWriter, r *http.Request) {
 	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
 	if err != nil {
@@ -109,6 +173,9 @@ func (s *Server) handleTopicUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 
 	if name := r.FormValue("name"); name != "" {
+		if s.rejectDuplicateTopicName(w, name, id) {
+			return
+		}
 		topic.Name = name
 	}
 	topic.Description = r.FormValue("description")
@@ -118,11 +185,36 @@ func (s *Server) handleTopicUpdate(w http.ResponseWriter, r *http.Request) {
 			topic.FactsPerRefresh = n
 		}
 	}
+	if v := r.FormValue("target_fact_count"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			topic.TargetFactCount = n
+		}
+	}
+	if v := r.FormValue("max_facts"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			topic.MaxFacts = n
+		}
+	}
+	if v := r.FormValue("temperature"); v != "" {
+		if f, err := strconv.ParseFloat(v, 64); err == nil && f >= 0 && f <= 2 {
+			topic.Temperature = f
+		}
+	}
+	if v := r.FormValue("max_tokens"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			topic.MaxTokens = n
+		}
+	}
 	if v := r.FormValue("refresh_interval_minutes"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n > 0 {
 			topic.RefreshIntervalMinutes = n
 		}
 	}
+	if v := r.FormValue("schedule_offset_minutes"); v != "" {
+		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
+			topic.ScheduleOffsetMinutes = n
+		}
+	}
 	if v := r.FormValue("summary_min_words"); v != "" {
 		if n, err := strconv.Atoi(v); err == nil && n >= 0 {
 			topic.SummaryMinWords = n
@@ -135,6 +227,9 @@ func (s *Server) handleTopicUpdate(w http.ResponseWriter, r *http.Request) {
 	}
 	topic.AIProvider = r.FormValue("ai_provider")
 	topic.IsNiche = r.FormValue("is_niche") == "1"
+	topic.UseResearch = r.FormValue("use_research") == "1"
+	topic.RequireVerifiable = r.FormValue("require_verifiable") == "1"
+	topic.SeriesMode = r.FormValue("series_mode") == "1"
 
 	if err := s.db.UpdateTopic(&topic); err != nil {
 		slog.Error("Failed to update topic", "error", err)
@@ -152,7 +247,16 @@ func (s *Server) handleTopicDelete(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.db.DeleteTopic(id); err != nil {
+	err = s.db.DeleteTopic(id, r.FormValue("include_custom") == "true")
+	if errors.Is(err, database.ErrTopicHasCustomFacts) {
+		// Keep the row and offer an explicit delete that includes the custom facts
+		w.Header().Set("HX-Retarget", fmt.Sprintf("#topic-refresh-status-%d", id))
+		w.Header().Set("HX-Reswap", "innerHTML")
+		fmt.Fprintf(w, `<span class="text-muted text-sm">This topic has custom facts.</span>
+<button class="btn btn-sm btn-danger" hx-delete="/topics/%d" hx-vals='{"include_custom": "true"}' hx-target="#topic-row-%d" hx-swap="outerHTML" hx-confirm="Also delete this topic's custom facts? They can't be recovered.">Delete with custom facts</button>`, id, id)
+		return
+	}
+	if err != nil {
 		slog.Error("Failed to delete topic", "error", err)
 		http.Error(w, "Failed to delete topic", 500)
 		return
@@ -179,6 +283,70 @@ func (s *Server) handleTopicToggle(w http.ResponseWriter, r *http.Request) {
 	s.renderPartial(w, "topic_row", &topic)
 }
 
+func (s *Server) handleTopicSnooze(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	until, err := snoozeUntil(r.FormValue("duration"))
+	if err != nil {
+		http.Error(w, err.Error(), 400)
+		return
+	}
+
+	if err := s.db.SnoozeTopic(id, until); err != nil {
+		slog.Error("Failed to snooze topic", "error", err)
+		http.Error(w, "Failed to snooze topic", 500)
+		return
+	}
+
+	topic, _ := s.db.GetTopic(id)
+	s.renderPartial(w, "topic_row", &topic)
+}
+
+// handleTopicPause pauses or resumes a topic's scheduled refreshes. A paused
+// topic stays on the dashboard and in the API, and can still be refreshed by hand.
+func (s *Server) handleTopicPause(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	paused := r.FormValue("paused") == "true"
+
+	if err := s.db.PauseTopic(id, paused); err != nil {
+		slog.Error("Failed to pause topic", "error", err)
+		http.Error(w, "Failed to pause topic", 500)
+		return
+	}
+
+	topic, _ := s.db.GetTopic(id)
+	s.renderPartial(w, "topic_row", &topic)
+}
+
+// snoozeUntil converts a snooze preset ("day", "week", "month") to the time the
+// snooze expires. "clear" returns nil, which removes an existing snooze.
+func snoozeUntil(duration string) (*time.Time, error) {
+	now := time.Now()
+	var until time.Time
+	switch duration {
+	case "clear":
+		return nil, nil
+	case "day":
+		until = now.AddDate(0, 0, 1)
+	case "week":
+		until = now.AddDate(0, 0, 7)
+	case "month":
+		until = now.AddDate(0, 1, 0)
+	default:
+		return nil, fmt.Errorf("invalid snooze duration %q", duration)
+	}
+	return &until, nil
+}
+
 func (s *Server) handleTopicReorder(w http.ResponseWriter, r *http.Request) {
 	var ids []int64
 	if err := json.NewDecoder(r.Body).Decode(&ids); err != nil {
@@ -202,13 +370,119 @@ func (s *Server) handleTopicRefresh(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	if err := s.sched.RefreshNow(context.Background(), id); err != nil {
+	view := r.FormValue("view")
+	status, err := s.sched.RefreshNow(context.Background(), id)
+	if err != nil {
 		slog.Error("Failed to refresh topic", "error", err)
 		http.Error(w, "Failed to refresh: "+err.Error(), 500)
 		return
 	}
 
-	// Return updated topic card for the dashboard
+	if status == scheduler.RefreshAlreadyRunning {
+		// Show a live indicator that polls until the running refresh releases the topic
+		if view != "row" {
+			w.Header().Set("HX-Retarget", fmt.Sprintf("#topic-card-status-%d", id))
+			w.Header().Set("HX-Reswap", "innerHTML")
+		}
+		s.renderPartial(w, "refresh_status", map[string]any{
+			"Running": true,
+			"Message": "Refresh already in progress",
+			"PollURL": fmt.Sprintf("/topics/%d/refresh/status?view=%s", id, view),
+		})
+		return
+	}
+
+	if view == "row" {
+		s.renderPartial(w, "refresh_status", map[string]any{"Message": "Refreshed"})
+		return
+	}
+	s.renderTopicCard(w, id)
+}
+
+// handleTopicSynthesize regenerates a topic's overview paragraph from its recent
+// facts. htmx requests get the updated dashboard card; others get JSON.
+func (s *Server) handleTopicSynthesize(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	htmx := r.Header.Get("HX-Request") == "true"
+	overview, err := s.sched.SynthesizeOverview(r.Context(), id)
+	if err != nil {
+		status := 500
+		switch {
+		case errors.Is(err, sql.ErrNoRows):
+			status = 404
+		case errors.Is(err, scheduler.ErrNoFacts):
+			status = 400
+		default:
+			slog.Error("Failed to synthesize topic overview", "topic_id", id, "error", err)
+		}
+		if htmx {
+			http.Error(w, "Failed to synthesize overview: "+err.Error(), status)
+		} else {
+			jsonError(w, "Failed to synthesize overview: "+err.Error(), status)
+		}
+		return
+	}
+
+	if htmx {
+		s.renderTopicCard(w, id)
+		return
+	}
+	jsonResponse(w, map[string]any{"topic_id": id, "overview": overview})
+}
+
+// handleTopicRefreshStatus is polled while a refresh is in progress. Once the
+// topic is unlocked it returns the final state: the updated card on the dashboard,
+// or a completion message on the topics page.
+func (s *Server) handleTopicRefreshStatus(w http.ResponseWriter, r *http.Request) {
+	id, err := strconv.ParseInt(r.PathValue("id"), 10, 64)
+	if err != nil {
+		http.Error(w, "Invalid topic ID", 400)
+		return
+	}
+
+	view := r.FormValue("view")
+	if s.sched.IsTopicRefreshing(id) {
+		s.renderPartial(w, "refresh_status", map[string]any{
+			"Running": true,
+			"Message": "Refresh already in progress",
+			"PollURL": fmt.Sprintf("/topics/%d/refresh/status?view=%s", id, view),
+		})
+		return
+	}
+
+	if view == "row" {
+		s.renderPartial(w, "refresh_status", map[string]any{"Message": "Refresh complete"})
+		return
+	}
+	w.Header().Set("HX-Retarget", fmt.Sprintf("#topic-card-%d", id))
+	w.Header().Set("HX-Reswap", "outerHTML")
+	s.renderTopicCard(w, id)
+}
+
+// renderTopicCard renders the dashboard card for a topic with its latest facts.
+// seriesOrder puts a series topic's facts in reading order (series facts by
+// sequence, then any others newest first). Other topics' facts are unchanged.
+func seriesOrder(topic models.Topic, facts []models.Fact) []models.Fact {
+	if !topic.SeriesMode {
+		return facts
+	}
+	slices.SortStableFunc(facts, func(a, b models.Fact) int {
+		switch {
+		case a.SequenceIndex == 0 || b.SequenceIndex == 0:
+			return cmp.Compare(b.SequenceIndex, a.SequenceIndex)
+		default:
+			return cmp.Compare(a.SequenceIndex, b.SequenceIndex)
+		}
+	})
+	return facts
+}
+
+func (s *Server) renderTopicCard(w http.ResponseWriter, id int64) {
 	topic, _ := s.db.GetTopic(id)
 	settings, _ := s.db.GetAllSettings()
 	limit := 5
@@ -218,6 +492,6 @@ func (s *Server) handleTopicRefresh(w http.ResponseWriter, r *http.Request) {
 		}
 	}
 	facts, _ := s.db.ListFactsByTopic(id, limit)
-	data := models.TopicWithFacts{Topic: topic, Facts: facts}
+	data := models.TopicWithFacts{Topic: topic, Facts: seriesOrder(topic, facts)}
 	s.renderPartial(w, "topic_card", data)
 }
@@ -8,11 +8,13 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/thinkscotty/kibble/internal/scheduler"
 	"github.com/thinkscotty/kibble/internal/updater"
 )
 
 func (s *Server) handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
-	info, err := updater.CheckForUpdate(r.Context(), s.version)
+	channel, _ := s.db.GetSetting("update_channel")
+	info, err := updater.CheckForUpdate(r.Context(), s.version, channel)
 	if err != nil {
 		slog.Error("Update check failed", "error", err)
 		fmt.Fprintf(w, `<span class="text-error">Update check failed: %s</span>`,
@@ -25,21 +27,28 @@ func (s *Server) handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
-	// Truncate release notes for display
-	notes := info.Body
-	if len(notes) > 500 {
-		notes = notes[:500] + "..."
+	notes := updater.NotesHTML(info.Body)
+	if notes == "" {
+		notes = `<p>This release has no notes.</p>`
+	}
+
+	prerelease := ""
+	if info.Prerelease {
+		prerelease = `<span class="badge badge-prerelease" style="margin-left: 0.5rem;">Pre-release</span>`
 	}
 
 	fmt.Fprintf(w, `<div id="update-result">
 		<div style="margin-bottom: 0.75rem;">
 			<span class="badge badge-active">Update Available</span>
-			<strong style="margin-left: 0.5rem;">%s</strong>
+			<strong style="margin-left: 0.5rem;">%s</strong>%s
 		</div>
-		<div class="text-muted text-sm" style="margin-bottom: 0.75rem; white-space: pre-line;">%s</div>
-		<p class="text-muted text-sm" style="margin-bottom: 0.75rem;">Binary: %s (%s)</p>
+		<div class="text-sm release-notes">%s</div>
+		<p class="text-muted text-sm" style="margin-bottom: 0.75rem;">
+			Binary: %s (%s) &middot; <a href="%s" target="_blank" rel="noopener">View on GitHub</a>
+		</p>
 		<button type="button" class="btn btn-primary"
 				hx-post="/settings/update/install"
+				hx-vals='{"confirm_version": "%s"}'
 				hx-target="#update-result"
 				hx-swap="innerHTML"
 				hx-confirm="Install update %s? The service will restart automatically.">
@@ -47,9 +56,12 @@ func (s *Server) handleUpdateCheck(w http.ResponseWriter, r *http.Request) {
 		</button>
 	</div>`,
 		template.HTMLEscapeString(info.TagName),
-		template.HTMLEscapeString(notes),
+		prerelease,
+		notes,
 		template.HTMLEscapeString(info.AssetName),
 		updater.FormatBytes(info.AssetSize),
+		template.HTMLEscapeString(info.HTMLURL),
+		template.HTMLEscapeString(template.JSEscapeString(info.TagName)),
 		template.HTMLEscapeString(info.TagName),
 	)
 }
@@ -60,7 +72,8 @@ func (s *Server) handleUpdateInstall(w http.ResponseWriter, r *http.Request) {
 	defer cancel()
 
 	// Re-check for update to get fresh download URL
-	info, err := updater.CheckForUpdate(dlCtx, s.version)
+	channel, _ := s.db.GetSetting("update_channel")
+	info, err := updater.CheckForUpdate(dlCtx, s.version, channel)
 	if err != nil {
 		slog.Error("Update check failed during install", "error", err)
 		fmt.Fprintf(w, `<span class="text-error">Update check failed: %s</span>`,
@@ -72,8 +85,19 @@ func (s *Server) handleUpdateInstall(w http.ResponseWriter, r *http.Request) {
 		return
 	}
 
+	// Only install the release whose notes were shown by the update check
+	if confirmed := r.FormValue("confirm_version"); confirmed != info.TagName {
+		msg := "Check for updates and review the release notes before installing."
+		if confirmed != "" {
+			msg = fmt.Sprintf("%s was released after you checked. Check for updates again to review its notes.", info.TagName)
+		}
+		fmt.Fprintf(w, `<span class="text-error">%s</span>`, template.HTMLEscapeString(msg))
+		return
+	}
+
 	// Download and install
-	result, err := updater.DownloadAndInstall(dlCtx, info, s.version)
+	requireSignature, _ := s.db.GetSetting("update_require_signature")
+	result, err := updater.DownloadAndInstall(dlCtx, info, s.version, requireSignature == "true")
 	if err != nil {
 		slog.Error("Update install failed", "error", err)
 		fmt.Fprintf(w, `<span class="text-error">Installation failed: %s</span>`,
@@ -118,9 +142,13 @@ func (s *Server) handleUpdateInstall(w http.ResponseWriter, r *http.Request) {
 	// Give the response time to be sent and received before restarting
 	go func() {
 		time.Sleep(3 * time.Second)
+		// A self-exec restart skips the normal shutdown, so deliver pending
+		// notifications first
+		s.sched.Drain(scheduler.DrainTimeout)
 		slog.Info("Initiating service restart after update")
 		if err := updater.RestartService(); err != nil {
 			slog.Error("Failed to restart service", "error", err)
+			s.sched.Resume()
 		}
 	}()
 }
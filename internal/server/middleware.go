@@ -1,7 +1,10 @@
 package server
 
 import (
+	"bytes"
+	"crypto/sha256"
 	"crypto/subtle"
+	"encoding/hex"
 	"log/slog"
 	"net/http"
 	"strings"
@@ -99,28 +102,7 @@ func (s *Server) authFailed(w http.ResponseWriter, r *http.Request) {
 //   - Query parameter: ?api_key=<key> or ?apikey=<key>
 func (s *Server) requireAPIKey(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
-		var providedKey string
-
-		// Check Authorization: Bearer <key>
-		if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
-			providedKey = strings.TrimPrefix(auth, "Bearer ")
-		}
-		// Check X-API-Key header (common convention, used by AWS API Gateway)
-		if providedKey == "" {
-			providedKey = r.Header.Get("X-API-Key")
-		}
-		// Check Api-Key header (variation without X- prefix)
-		if providedKey == "" {
-			providedKey = r.Header.Get("Api-Key")
-		}
-		// Check query parameters
-		if providedKey == "" {
-			providedKey = r.URL.Query().Get("api_key")
-		}
-		if providedKey == "" {
-			providedKey = r.URL.Query().Get("apikey")
-		}
-
+		providedKey := apiKeyFromRequest(r)
 		if providedKey == "" {
 			jsonError(w, "API key required — use header 'X-API-Key', 'Authorization: Bearer <key>', or query param '?api_key=<key>'", http.StatusUnauthorized)
 			return
@@ -142,6 +124,117 @@ func (s *Server) requireAPIKey(next http.Handler) http.Handler {
 	})
 }
 
+// requireAPIWriteKey guards endpoints that modify data. It accepts the same
+// headers and query parameters as requireAPIKey, but only the separate
+// write-scoped key (api_write_key). Write endpoints stay disabled until that
+// key is generated on the Settings page.
+func (s *Server) requireAPIWriteKey(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		providedKey := apiKeyFromRequest(r)
+		if providedKey == "" {
+			jsonError(w, "Write API key required — use header 'X-API-Key', 'Authorization: Bearer <key>', or query param '?api_key=<key>'", http.StatusUnauthorized)
+			return
+		}
+
+		storedKey, _ := s.db.GetSetting("api_write_key")
+		if storedKey == "" {
+			jsonError(w, "Write API access is disabled — generate a write key on the Settings page", http.StatusForbidden)
+			return
+		}
+
+		if subtle.ConstantTimeCompare([]byte(providedKey), []byte(storedKey)) != 1 {
+			if readKey, _ := s.db.GetSetting("api_key"); readKey != "" &&
+				subtle.ConstantTimeCompare([]byte(providedKey), []byte(readKey)) == 1 {
+				jsonError(w, "This endpoint requires the write API key", http.StatusForbidden)
+				return
+			}
+			jsonError(w, "Invalid API key", http.StatusUnauthorized)
+			return
+		}
+
+		next.ServeHTTP(w, r)
+	})
+}
+
+// etagMiddleware buffers a successful response, sends a strong ETag made from
+// a hash of its body, and replies 304 Not Modified instead when the request's
+// If-None-Match already names that ETag. The body is still built each time,
+// but unchanged responses are not sent again, which saves bandwidth for
+// clients that poll on a timer.
+func etagMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		bw := &bufferedWriter{header: make(http.Header), status: http.StatusOK}
+		next.ServeHTTP(bw, r)
+
+		for k, v := range bw.header {
+			w.Header()[k] = v
+		}
+		if bw.status != http.StatusOK {
+			w.WriteHeader(bw.status)
+			w.Write(bw.body.Bytes())
+			return
+		}
+
+		sum := sha256.Sum256(bw.body.Bytes())
+		etag := `"` + hex.EncodeToString(sum[:16]) + `"`
+		w.Header().Set("ETag", etag)
+		if etagMatches(r.Header.Get("If-None-Match"), etag) {
+			w.Header().Del("Content-Type")
+			w.Header().Del("Content-Length")
+			w.WriteHeader(http.StatusNotModified)
+			return
+		}
+		w.Write(bw.body.Bytes())
+	})
+}
+
+// etagMatches reports whether an If-None-Match header names etag or is "*".
+// Weak validators match too, as RFC 9110 asks for GET requests.
+func etagMatches(ifNoneMatch, etag string) bool {
+	for _, tag := range strings.Split(ifNoneMatch, ",") {
+		tag = strings.TrimPrefix(strings.TrimSpace(tag), "W/")
+		if tag == "*" || tag == etag {
+			return true
+		}
+	}
+	return false
+}
+
+// bufferedWriter collects a response so etagMiddleware can hash it before
+// anything is sent.
+type bufferedWriter struct {
+	header http.Header
+	status int
+	body   bytes.Buffer
+}
+
+func (w *bufferedWriter) Header() http.Header { return w.header }
+
+func (w *bufferedWriter) WriteHeader(status int) { w.status = status }
+
+func (w *bufferedWriter) Write(b []byte) (int, error) { return w.body.Write(b) }
+
+// apiKeyFromRequest returns the API key sent with r, or "" if none was provided.
+func apiKeyFromRequest(r *http.Request) string {
+	// Check Authorization: Bearer <key>
+	if auth := r.Header.Get("Authorization"); strings.HasPrefix(auth, "Bearer ") {
+		return strings.TrimPrefix(auth, "Bearer ")
+	}
+	// Check X-API-Key header (common convention, used by AWS API Gateway)
+	if key := r.Header.Get("X-API-Key"); key != "" {
+		return key
+	}
+	// Check Api-Key header (variation without X- prefix)
+	if key := r.Header.Get("Api-Key"); key != "" {
+		return key
+	}
+	// Check query parameters
+	if key := r.URL.Query().Get("api_key"); key != "" {
+		return key
+	}
+	return r.URL.Query().Get("apikey")
+}
+
 type statusWriter struct {
 	http.ResponseWriter
 	status int
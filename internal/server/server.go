@@ -6,7 +6,10 @@ import (
 	"html/template"
 	"io/fs"
 	"log/slog"
+	"math"
 	"net/http"
+	"strconv"
+	"strings"
 	"sync/atomic"
 	"time"
 
@@ -16,6 +19,7 @@ import (
 	"github.com/thinkscotty/kibble/internal/database"
 	"github.com/thinkscotty/kibble/internal/scheduler"
 	"github.com/thinkscotty/kibble/internal/similarity"
+	"github.com/thinkscotty/kibble/internal/updater"
 )
 
 type Server struct {
@@ -90,18 +94,30 @@ func (s *Server) routes(mux *http.ServeMux) {
 	mux.HandleFunc("POST /logout", s.handleLogout)
 	mux.HandleFunc("GET /setup", s.handleSetupPage)
 	mux.HandleFunc("POST /setup", s.handleSetupSubmit)
+	mux.Handle("POST /setup/seed-examples", s.requireAuth(http.HandlerFunc(s.handleSeedExamples)))
 
-	// External Client API — protected by API key
-	mux.Handle("GET /api/v1/topics", s.requireAPIKey(http.HandlerFunc(s.handleAPITopics)))
-	mux.Handle("GET /api/v1/facts", s.requireAPIKey(http.HandlerFunc(s.handleAPIFacts)))
-	mux.Handle("GET /api/v1/facts/all", s.requireAPIKey(http.HandlerFunc(s.handleAPIAllFacts)))
-	mux.Handle("GET /api/v1/facts/recent", s.requireAPIKey(http.HandlerFunc(s.handleAPIRecentFacts)))
-	mux.Handle("GET /api/v1/facts/random", s.requireAPIKey(http.HandlerFunc(s.handleAPIRandomFact)))
+	// Read-only dashboard — public while the public_dashboard setting is on
+	mux.HandleFunc("GET /public", s.handlePublicDashboard)
+
+	// External Client API — protected by API key. Read-only endpoints send an
+	// ETag, so polling clients get a 304 when nothing changed.
+	mux.Handle("GET /api/v1/topics", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPITopics))))
+	mux.Handle("GET /api/v1/facts", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIFacts))))
+	mux.Handle("GET /api/v1/facts/all", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIAllFacts))))
+	mux.Handle("GET /api/v1/facts/recent", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIRecentFacts))))
+	mux.Handle("GET /api/v1/facts/random", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIRandomFact))))
+	mux.Handle("POST /api/v1/topics/{id}/facts", s.requireAPIWriteKey(http.HandlerFunc(s.handleAPIFactCreate)))
+	mux.Handle("GET /api/v1/topics/{id}/feed.xml", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPITopicFeed))))
 
 	// Story API — protected by API key
-	mux.Handle("GET /api/v1/stories", s.requireAPIKey(http.HandlerFunc(s.handleAPIStories)))
-	mux.Handle("GET /api/v1/stories/recent", s.requireAPIKey(http.HandlerFunc(s.handleAPIStoriesRecent)))
-	mux.Handle("GET /api/v1/stories/random", s.requireAPIKey(http.HandlerFunc(s.handleAPIRandomStory)))
+	mux.Handle("GET /api/v1/stories", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIStories))))
+	mux.Handle("GET /api/v1/stories/recent", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIStoriesRecent))))
+	mux.Handle("GET /api/v1/stories/random", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIRandomStory))))
+	mux.Handle("GET /api/v1/news-topics/{id}/sources", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPINewsTopicSources))))
+	mux.Handle("GET /api/v1/news-topics/{id}/feed.xml", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPINewsTopicFeed))))
+
+	// Refresh log API — protected by API key
+	mux.Handle("GET /api/v1/refresh-log", s.requireAPIKey(etagMiddleware(http.HandlerFunc(s.handleAPIRefreshLog))))
 
 	// All other routes — protected by session auth
 	mux.Handle("GET /{$}", s.requireAuth(http.HandlerFunc(s.handleDashboard)))
@@ -109,19 +125,30 @@ func (s *Server) routes(mux *http.ServeMux) {
 	mux.Handle("GET /news", s.requireAuth(http.HandlerFunc(s.handleNewsPage)))
 	mux.Handle("GET /settings", s.requireAuth(http.HandlerFunc(s.handleSettingsPage)))
 	mux.Handle("GET /stats", s.requireAuth(http.HandlerFunc(s.handleStatsPage)))
+	mux.Handle("GET /stats/health", s.requireAuth(http.HandlerFunc(s.handleHealthPage)))
+	mux.Handle("GET /admin/schema", s.requireAuth(http.HandlerFunc(s.handleSchema)))
+	mux.Handle("POST /admin/rebuild-trigrams", s.requireAuth(http.HandlerFunc(s.handleRebuildTrigrams)))
+	mux.Handle("POST /admin/backfill-embeddings", s.requireAuth(http.HandlerFunc(s.handleBackfillEmbeddings)))
+	mux.Handle("POST /admin/maintenance", s.requireAuth(http.HandlerFunc(s.handleMaintenance)))
+	mux.Handle("POST /admin/auth-alert/ack", s.requireAuth(http.HandlerFunc(s.handleAuthAlertAck)))
 
 	mux.Handle("POST /topics", s.requireAuth(http.HandlerFunc(s.handleTopicCreate)))
 	mux.Handle("GET /topics/{id}/edit", s.requireAuth(http.HandlerFunc(s.handleTopicEditForm)))
 	mux.Handle("PUT /topics/{id}", s.requireAuth(http.HandlerFunc(s.handleTopicUpdate)))
 	mux.Handle("DELETE /topics/{id}", s.requireAuth(http.HandlerFunc(s.handleTopicDelete)))
 	mux.Handle("PATCH /topics/{id}/toggle", s.requireAuth(http.HandlerFunc(s.handleTopicToggle)))
+	mux.Handle("PATCH /topics/{id}/snooze", s.requireAuth(http.HandlerFunc(s.handleTopicSnooze)))
+	mux.Handle("PATCH /topics/{id}/pause", s.requireAuth(http.HandlerFunc(s.handleTopicPause)))
 	mux.Handle("POST /topics/reorder", s.requireAuth(http.HandlerFunc(s.handleTopicReorder)))
 	mux.Handle("POST /topics/{id}/refresh", s.requireAuth(http.HandlerFunc(s.handleTopicRefresh)))
+	mux.Handle("GET /topics/{id}/refresh/status", s.requireAuth(http.HandlerFunc(s.handleTopicRefreshStatus)))
+	mux.Handle("POST /topics/{id}/synthesize", s.requireAuth(http.HandlerFunc(s.handleTopicSynthesize)))
 
 	mux.Handle("POST /facts", s.requireAuth(http.HandlerFunc(s.handleFactCreate)))
 	mux.Handle("GET /facts/{id}/edit", s.requireAuth(http.HandlerFunc(s.handleFactEditForm)))
 	mux.Handle("PUT /facts/{id}", s.requireAuth(http.HandlerFunc(s.handleFactUpdate)))
 	mux.Handle("DELETE /facts/{id}", s.requireAuth(http.HandlerFunc(s.handleFactDelete)))
+	mux.Handle("PATCH /facts/{id}/archive", s.requireAuth(http.HandlerFunc(s.handleFactArchive)))
 	mux.Handle("GET /facts/search", s.requireAuth(http.HandlerFunc(s.handleFactSearch)))
 
 	// News topic CRUD
@@ -130,25 +157,46 @@ func (s *Server) routes(mux *http.ServeMux) {
 	mux.Handle("PUT /news-topics/{id}", s.requireAuth(http.HandlerFunc(s.handleNewsTopicUpdate)))
 	mux.Handle("DELETE /news-topics/{id}", s.requireAuth(http.HandlerFunc(s.handleNewsTopicDelete)))
 	mux.Handle("PATCH /news-topics/{id}/toggle", s.requireAuth(http.HandlerFunc(s.handleNewsTopicToggle)))
+	mux.Handle("PATCH /news-topics/{id}/snooze", s.requireAuth(http.HandlerFunc(s.handleNewsTopicSnooze)))
 	mux.Handle("POST /news-topics/{id}/refresh", s.requireAuth(http.HandlerFunc(s.handleNewsTopicRefresh)))
+	mux.Handle("GET /news-topics/{id}/refresh/status", s.requireAuth(http.HandlerFunc(s.handleNewsTopicRefreshStatus)))
 	mux.Handle("POST /news-topics/{id}/discover", s.requireAuth(http.HandlerFunc(s.handleNewsTopicDiscover)))
+	mux.Handle("POST /news-topics/{id}/dry-run", s.requireAuth(http.HandlerFunc(s.handleNewsTopicDryRun)))
+	mux.Handle("GET /news/suggest-feeds", s.requireAuth(http.HandlerFunc(s.handleNewsSuggestFeeds)))
+	mux.Handle("GET /news/export.opml", s.requireAuth(http.HandlerFunc(s.handleNewsExportOPML)))
 
 	// Source management
 	mux.Handle("POST /news-topics/{id}/sources", s.requireAuth(http.HandlerFunc(s.handleNewsSourceAdd)))
+	mux.Handle("PATCH /sources/{id}/force-feed", s.requireAuth(http.HandlerFunc(s.handleNewsSourceForceFeed)))
+	mux.Handle("PATCH /sources/{id}/fetch-options", s.requireAuth(http.HandlerFunc(s.handleNewsSourceFetchOptions)))
+	mux.Handle("POST /sources/{id}/reset-failures", s.requireAuth(http.HandlerFunc(s.handleNewsSourceResetFailures)))
 	mux.Handle("DELETE /sources/{id}", s.requireAuth(http.HandlerFunc(s.handleNewsSourceDelete)))
+	mux.Handle("POST /news-topics/{id}/webhooks", s.requireAuth(http.HandlerFunc(s.handleWebhookAdd)))
+	mux.Handle("DELETE /webhooks/{id}", s.requireAuth(http.HandlerFunc(s.handleWebhookDelete)))
 
 	mux.Handle("POST /settings", s.requireAuth(http.HandlerFunc(s.handleSettingsUpdate)))
+	mux.Handle("GET /settings/sessions", s.requireAuth(http.HandlerFunc(s.handleSessionsPage)))
+	mux.Handle("POST /settings/sessions/{id}/revoke", s.requireAuth(http.HandlerFunc(s.handleSessionRevoke)))
+	mux.Handle("POST /settings/sessions/revoke-all", s.requireAuth(http.HandlerFunc(s.handleSessionsRevokeAll)))
 	mux.Handle("POST /settings/apikey/test", s.requireAuth(http.HandlerFunc(s.handleAPIKeyTest)))
 	mux.Handle("POST /settings/apikey/regenerate", s.requireAuth(http.HandlerFunc(s.handleAPIKeyRegenerate)))
+	mux.Handle("POST /settings/apikey/write/regenerate", s.requireAuth(http.HandlerFunc(s.handleAPIWriteKeyRegenerate)))
+	mux.Handle("POST /settings/apikey/write/revoke", s.requireAuth(http.HandlerFunc(s.handleAPIWriteKeyRevoke)))
 	mux.Handle("POST /settings/ollama/test", s.requireAuth(http.HandlerFunc(s.handleOllamaTest)))
 	mux.Handle("GET /settings/ollama/models", s.requireAuth(http.HandlerFunc(s.handleOllamaModels)))
 	mux.Handle("POST /settings/chutes/test", s.requireAuth(http.HandlerFunc(s.handleChutesTest)))
+	mux.Handle("POST /settings/openai/test", s.requireAuth(http.HandlerFunc(s.handleOpenAITest)))
 	mux.Handle("POST /settings/update/check", s.requireAuth(http.HandlerFunc(s.handleUpdateCheck)))
 	mux.Handle("POST /settings/update/install", s.requireAuth(http.HandlerFunc(s.handleUpdateInstall)))
+	mux.Handle("POST /settings/backup", s.requireAuth(http.HandlerFunc(s.handleBackupDownload)))
 }
 
 func (s *Server) loadTemplates() error {
 	funcMap := template.FuncMap{
+		"webhookSecret": func(newsTopicID int64) string {
+			apiKey, _ := s.db.GetSetting("api_key")
+			return scheduler.WebhookSecret(apiKey, newsTopicID)
+		},
 		"safe": func(str string) template.HTML {
 			return template.HTML(str)
 		},
@@ -168,6 +216,34 @@ func (s *Server) loadTemplates() error {
 				return fmt.Sprintf("%dd ago", int(d.Hours()/24))
 			}
 		},
+		"snoozeLeft": func(t *time.Time) string {
+			if t == nil {
+				return ""
+			}
+			d := time.Until(*t)
+			switch {
+			case d <= 0:
+				return ""
+			case d < time.Hour:
+				return fmt.Sprintf("%dm left", int(math.Ceil(d.Minutes())))
+			case d < 24*time.Hour:
+				return fmt.Sprintf("%dh left", int(math.Ceil(d.Hours())))
+			default:
+				return fmt.Sprintf("%dd left", int(math.Ceil(d.Hours()/24)))
+			}
+		},
+		// wordRange reports whether a word count falls "short" of or "long" past a
+		// topic's configured summary range. Zero bounds mean no limit.
+		"wordRange": func(count, min, max int) string {
+			switch {
+			case min > 0 && count < min:
+				return "short"
+			case max > 0 && count > max:
+				return "long"
+			default:
+				return ""
+			}
+		},
 		"boolChecked": func(b bool) string {
 			if b {
 				return "checked"
@@ -181,29 +257,27 @@ func (s *Server) loadTemplates() error {
 			}
 			return s
 		},
+		// inList reports whether item is one of the entries in a comma-separated list.
+		"inList": func(item, list string) bool {
+			for _, v := range strings.Split(list, ",") {
+				if strings.TrimSpace(v) == item {
+					return true
+				}
+			}
+			return false
+		},
 		"divFloat": func(a int64, b int) float64 {
 			if b == 0 {
 				return 0
 			}
 			return float64(a) / float64(b)
 		},
-		"formatBytes": func(b int64) string {
-			const unit = 1024
-			if b < unit {
-				return fmt.Sprintf("%d B", b)
-			}
-			div, exp := int64(unit), 0
-			for n := b / unit; n >= unit; n /= unit {
-				div *= unit
-				exp++
-			}
-			return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
-		},
+		"formatBytes": formatBytes,
 	}
 
 	s.pages = make(map[string]*template.Template)
 
-	pageNames := []string{"dashboard", "topics", "news", "settings", "stats", "login", "setup"}
+	pageNames := []string{"dashboard", "public", "topics", "news", "settings", "stats", "health", "sessions", "login", "setup"}
 	for _, page := range pageNames {
 		t, err := template.New("base.html").Funcs(funcMap).ParseFS(kibble.TemplateFS,
 			"web/templates/layouts/base.html",
@@ -241,6 +315,7 @@ func (s *Server) render(w http.ResponseWriter, page string, data map[string]any)
 	// Inject version info
 	data["Version"] = s.version
 	data["BuildTime"] = s.buildTime
+	data["UpdateSigningKey"] = updater.PublicKey != ""
 
 	// Resolve the active theme and inject CSS variables + logo choice
 	s.injectThemeData(data)
@@ -252,6 +327,8 @@ func (s *Server) render(w http.ResponseWriter, page string, data map[string]any)
 }
 
 // injectThemeData resolves the selected theme and adds ThemeCSS and ThemeLogo to the data map.
+// When theme rotation is on, the rendered theme follows the rotation while
+// CurrentTheme stays the fixed theme_mode, so saving Settings doesn't pin a rotated theme.
 func (s *Server) injectThemeData(data map[string]any) {
 	settings, _ := data["Settings"].(map[string]string)
 	themeID := ""
@@ -260,11 +337,41 @@ func (s *Server) injectThemeData(data map[string]any) {
 	}
 
 	theme := s.findTheme(themeID)
-	data["ThemeCSS"] = template.CSS(config.ResolveThemeCSS(theme))
+	rendered := theme
+	if rotated, ok := s.rotationTheme(settings, time.Now()); ok {
+		rendered = rotated
+	}
+	data["ThemeCSS"] = template.CSS(config.ResolveThemeCSS(rendered))
 	data["Themes"] = s.themes
 	data["CurrentTheme"] = theme.ID
 }
 
+// rotationTheme picks the theme for the current time slot from the comma-separated
+// theme_rotation setting, switching every theme_rotation_minutes. Slots are aligned
+// to local midnight. Unknown theme IDs are ignored; ok is false when rotation is off.
+func (s *Server) rotationTheme(settings map[string]string, now time.Time) (config.Theme, bool) {
+	var themes []config.Theme
+	for _, id := range strings.Split(settings["theme_rotation"], ",") {
+		for _, t := range s.themes {
+			if t.ID == strings.TrimSpace(id) {
+				themes = append(themes, t)
+				break
+			}
+		}
+	}
+	if len(themes) == 0 {
+		return config.Theme{}, false
+	}
+
+	minutes, err := strconv.Atoi(settings["theme_rotation_minutes"])
+	if err != nil || minutes <= 0 {
+		minutes = 60
+	}
+	_, offset := now.Zone()
+	slot := (now.Unix() + int64(offset)) / int64(minutes*60)
+	return themes[slot%int64(len(themes))], true
+}
+
 // findTheme looks up a theme by ID, falling back to the first available theme.
 // If the requested theme is not found, it logs a warning and updates the database
 // to the fallback theme.
@@ -304,3 +411,17 @@ func (s *Server) renderPartial(w http.ResponseWriter, name string, data any) {
 		http.Error(w, "Template error", 500)
 	}
 }
+
+// formatBytes renders a byte count with a binary unit suffix (e.g. "1.5 MB").
+func formatBytes(b int64) string {
+	const unit = 1024
+	if b < unit {
+		return fmt.Sprintf("%d B", b)
+	}
+	div, exp := int64(unit), 0
+	for n := b / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(b)/float64(div), "KMGTPE"[exp])
+}
@@ -0,0 +1,148 @@
+package server
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+	"unicode"
+
+	kibble "github.com/thinkscotty/kibble"
+	"github.com/thinkscotty/kibble/internal/models"
+)
+
+// snapshotTopic is a news topic's stories as written to a snapshot, with the
+// base name of its files.
+type snapshotTopic struct {
+	models.NewsTopicWithStories
+	File string
+}
+
+// snapshotJSON is the JSON file written for each topic in a snapshot.
+type snapshotJSON struct {
+	TopicID     int64          `json:"topic_id"`
+	Topic       string         `json:"topic"`
+	Description string         `json:"description"`
+	TakenAt     time.Time      `json:"taken_at"`
+	Stories     []models.Story `json:"stories"`
+}
+
+// WriteSnapshots writes every active news topic's stories to a directory
+// named for now's date under dir, as an HTML page and a JSON file per topic
+// plus an index page, and copies the stylesheet to dir. A later snapshot the
+// same day replaces that day's files, so the archive keeps each day's last
+// snapshot. It returns how many topics were written.
+func (s *Server) WriteSnapshots(dir string, now time.Time) (int, error) {
+	settings, _ := s.db.GetAllSettings()
+	newsTopics, err := s.db.ListActiveNewsTopics()
+	if err != nil {
+		return 0, fmt.Errorf("list news topics: %w", err)
+	}
+
+	dayDir := filepath.Join(dir, now.Format("2006-01-02"))
+	if err := os.MkdirAll(dayDir, 0o755); err != nil {
+		return 0, err
+	}
+	css, err := kibble.StaticFS.ReadFile("web/static/css/style.css")
+	if err != nil {
+		return 0, fmt.Errorf("read stylesheet: %w", err)
+	}
+	if err := writeFileAtomic(filepath.Join(dir, "style.css"), css); err != nil {
+		return 0, err
+	}
+
+	data := map[string]any{"Settings": settings, "Date": now.Format("2006-01-02"), "TakenAt": now}
+	s.injectThemeData(data)
+
+	var topics []snapshotTopic
+	for _, nt := range newsTopics {
+		stories, err := s.db.ListStoriesByNewsTopic(nt.ID, 0, settings["story_order"])
+		if err != nil {
+			return len(topics), fmt.Errorf("list stories for %q: %w", nt.Name, err)
+		}
+		topic := snapshotTopic{
+			NewsTopicWithStories: models.NewsTopicWithStories{NewsTopic: nt, Stories: stories},
+			File:                 snapshotFileName(nt),
+		}
+
+		data["Topic"] = topic
+		if err := s.writeSnapshotPage(filepath.Join(dayDir, topic.File+".html"), data); err != nil {
+			return len(topics), err
+		}
+		if stories == nil {
+			stories = []models.Story{}
+		}
+		body, err := json.MarshalIndent(snapshotJSON{
+			TopicID: nt.ID, Topic: nt.Name, Description: nt.Description, TakenAt: now, Stories: stories,
+		}, "", "  ")
+		if err != nil {
+			return len(topics), err
+		}
+		if err := writeFileAtomic(filepath.Join(dayDir, topic.File+".json"), body); err != nil {
+			return len(topics), err
+		}
+		topics = append(topics, topic)
+	}
+
+	delete(data, "Topic")
+	data["Topics"] = topics
+	if err := s.writeSnapshotPage(filepath.Join(dayDir, "index.html"), data); err != nil {
+		return len(topics), err
+	}
+	return len(topics), nil
+}
+
+// writeSnapshotPage renders the snapshot_page template to path.
+func (s *Server) writeSnapshotPage(path string, data map[string]any) error {
+	var buf bytes.Buffer
+	if err := s.partials.ExecuteTemplate(&buf, "snapshot_page", data); err != nil {
+		return fmt.Errorf("render %s: %w", filepath.Base(path), err)
+	}
+	return writeFileAtomic(path, buf.Bytes())
+}
+
+// snapshotFileName returns the base file name for a topic's snapshot files:
+// its ID and a lowercase, hyphenated form of its name, e.g. "3-world-news".
+func snapshotFileName(nt models.NewsTopic) string {
+	var sb strings.Builder
+	hyphen := false
+	for _, r := range strings.ToLower(nt.Name) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			sb.WriteRune(r)
+			hyphen = false
+		} else if !hyphen && sb.Len() > 0 {
+			sb.WriteRune('-')
+			hyphen = true
+		}
+	}
+	name := strings.TrimSuffix(sb.String(), "-")
+	if name == "" {
+		return fmt.Sprintf("%d", nt.ID)
+	}
+	return fmt.Sprintf("%d-%s", nt.ID, name)
+}
+
+// writeFileAtomic writes data to a temporary file beside path and renames it
+// into place, so a web server or backup reading the directory never sees a
+// half-written file.
+func writeFileAtomic(path string, data []byte) error {
+	tmp, err := os.CreateTemp(filepath.Dir(path), ".snapshot-*")
+	if err != nil {
+		return err
+	}
+	defer os.Remove(tmp.Name())
+	if _, err := tmp.Write(data); err != nil {
+		tmp.Close()
+		return err
+	}
+	if err := tmp.Close(); err != nil {
+		return err
+	}
+	if err := os.Chmod(tmp.Name(), 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp.Name(), path)
+}
@@ -2,23 +2,31 @@ package similarity
 
 import (
 	"encoding/json"
+	"math"
 	"strings"
 	"unicode"
 )
 
 // StoredTrigrams holds minimal data for similarity comparison.
 type StoredTrigrams struct {
-	ID       int64
-	Trigrams string
+	ID        int64
+	Trigrams  string
+	Embedding []float32 // nil for facts stored without an embedding
+
+	set map[string]struct{} // parsed Trigrams, filled by Prepare
 }
 
 type Checker struct {
-	threshold float64
-	ngramSize int
+	threshold          float64
+	ngramSize          int
+	embeddingThreshold float64
 }
 
-func New(threshold float64, ngramSize int) *Checker {
-	return &Checker{threshold: threshold, ngramSize: ngramSize}
+// New creates a Checker. threshold is the trigram Jaccard similarity, and
+// embeddingThreshold the embedding cosine similarity, at or above which two
+// facts count as duplicates.
+func New(threshold float64, ngramSize int, embeddingThreshold float64) *Checker {
+	return &Checker{threshold: threshold, ngramSize: ngramSize, embeddingThreshold: embeddingThreshold}
 }
 
 // normalize lowercases, removes punctuation, and collapses whitespace.
@@ -70,6 +78,16 @@ func (c *Checker) TrigramsFromJSON(data string) map[string]struct{} {
 	return set
 }
 
+// Prepare parses the stored trigrams of facts up front, so that checking many
+// new facts against them does not parse each one again for every check.
+func (c *Checker) Prepare(facts []StoredTrigrams) {
+	for i := range facts {
+		if facts[i].set == nil {
+			facts[i].set = c.TrigramsFromJSON(facts[i].Trigrams)
+		}
+	}
+}
+
 // JaccardSimilarity computes |A intersection B| / |A union B|.
 func (c *Checker) JaccardSimilarity(a, b map[string]struct{}) float64 {
 	if len(a) == 0 && len(b) == 0 {
@@ -90,13 +108,81 @@ func (c *Checker) JaccardSimilarity(a, b map[string]struct{}) float64 {
 	return float64(intersection) / float64(union)
 }
 
-// IsTooSimilar checks if newFact is too similar to any existing fact.
-func (c *Checker) IsTooSimilar(newFactContent string, existingFacts []StoredTrigrams) bool {
+// EmbeddingToJSON serializes an embedding for database storage. A nil
+// embedding is stored as "".
+func EmbeddingToJSON(embedding []float32) string {
+	if len(embedding) == 0 {
+		return ""
+	}
+	data, _ := json.Marshal(embedding)
+	return string(data)
+}
+
+// EmbeddingFromJSON deserializes a stored embedding, returning nil for "".
+func EmbeddingFromJSON(data string) []float32 {
+	if data == "" {
+		return nil
+	}
+	var embedding []float32
+	json.Unmarshal([]byte(data), &embedding)
+	return embedding
+}
+
+// CosineSimilarity computes the cosine of the angle between two embeddings.
+// It is 0 when either is empty or their lengths differ, as they do between
+// embedding models.
+func CosineSimilarity(a, b []float32) float64 {
+	if len(a) == 0 || len(a) != len(b) {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += float64(a[i]) * float64(b[i])
+		normA += float64(a[i]) * float64(a[i])
+		normB += float64(b[i]) * float64(b[i])
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
+
+// IsTooSimilar checks if newFact is too similar to any existing fact. Trigram
+// overlap catches near-identical wording. When newEmbedding is given, facts
+// that also have an embedding are compared by cosine similarity too, which
+// catches paraphrases that share few trigrams.
+func (c *Checker) IsTooSimilar(newFactContent string, newEmbedding []float32, existingFacts []StoredTrigrams) bool {
 	newTrigrams := c.Trigrams(newFactContent)
 	for _, existing := range existingFacts {
-		existingSet := c.TrigramsFromJSON(existing.Trigrams)
-		sim := c.JaccardSimilarity(newTrigrams, existingSet)
-		if sim >= c.threshold {
+		existingSet := existing.set
+		if existingSet == nil {
+			existingSet = c.TrigramsFromJSON(existing.Trigrams)
+		}
+		// Jaccard similarity is at most the ratio of the set sizes, so facts of
+		// very different lengths are skipped without counting the overlap
+		smaller, larger := min(len(newTrigrams), len(existingSet)), max(len(newTrigrams), len(existingSet))
+		if larger == 0 || float64(smaller)/float64(larger) >= c.threshold {
+			if c.JaccardSimilarity(newTrigrams, existingSet) >= c.threshold {
+				return true
+			}
+		}
+		if newEmbedding != nil && existing.Embedding != nil &&
+			CosineSimilarity(newEmbedding, existing.Embedding) >= c.embeddingThreshold {
+			return true
+		}
+	}
+	return false
+}
+
+// IsTooSimilarEmbedding checks newEmbedding against the facts that also have an
+// embedding, without repeating the trigram comparison. Use it after IsTooSimilar
+// has already passed the fact's wording.
+func (c *Checker) IsTooSimilarEmbedding(newEmbedding []float32, existingFacts []StoredTrigrams) bool {
+	if newEmbedding == nil {
+		return false
+	}
+	for _, existing := range existingFacts {
+		if existing.Embedding != nil && CosineSimilarity(newEmbedding, existing.Embedding) >= c.embeddingThreshold {
 			return true
 		}
 	}
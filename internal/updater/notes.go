@@ -0,0 +1,149 @@
+package updater
+
+import (
+	"html"
+	"regexp"
+	"strings"
+)
+
+// Release notes are GitHub-flavored markdown. Only the parts GitHub's
+// generated notes use are handled: headings, lists, fenced code, paragraphs,
+// and inline code, bold, links, and bare URLs. Anything else passes through as
+// text.
+
+var (
+	notesHeading  = regexp.MustCompile(`^#{1,6}\s+(.*?)\s*#*$`)
+	notesBullet   = regexp.MustCompile(`^\s*[-*+]\s+(.*)$`)
+	notesNumbered = regexp.MustCompile(`^\s*\d+[.)]\s+(.*)$`)
+	notesLink     = regexp.MustCompile(`\[([^\]]+)\]\((https?://[^)\s]+)\)`)
+	notesBold     = regexp.MustCompile(`\*\*([^*]+)\*\*`)
+	notesBareURL  = regexp.MustCompile(`(^|[\s(])(https?://[^\s<)]+)`)
+)
+
+// NotesHTML renders release notes as HTML. All text is escaped, and links are
+// limited to http and https URLs.
+func NotesHTML(md string) string {
+	var b strings.Builder
+	var para []string
+	list := "" // "ul" or "ol" while inside a list
+	inCode := false
+
+	flushPara := func() {
+		if len(para) > 0 {
+			b.WriteString("<p>" + notesInline(strings.Join(para, " ")) + "</p>\n")
+			para = nil
+		}
+	}
+	closeList := func() {
+		if list != "" {
+			b.WriteString("</" + list + ">\n")
+			list = ""
+		}
+	}
+	openList := func(tag string) {
+		if list != tag {
+			closeList()
+			b.WriteString("<" + tag + ">\n")
+			list = tag
+		}
+	}
+
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inCode {
+				b.WriteString("</code></pre>\n")
+			} else {
+				flushPara()
+				closeList()
+				b.WriteString("<pre><code>")
+			}
+			inCode = !inCode
+			continue
+		}
+		if inCode {
+			b.WriteString(html.EscapeString(line) + "\n")
+			continue
+		}
+
+		trimmed := strings.TrimSpace(line)
+		switch {
+		case trimmed == "":
+			flushPara()
+			closeList()
+		case notesHeading.MatchString(trimmed):
+			flushPara()
+			closeList()
+			m := notesHeading.FindStringSubmatch(trimmed)
+			b.WriteString("<p><strong>" + notesInline(m[1]) + "</strong></p>\n")
+		case notesBullet.MatchString(line):
+			flushPara()
+			openList("ul")
+			b.WriteString("<li>" + notesInline(notesBullet.FindStringSubmatch(line)[1]) + "</li>\n")
+		case notesNumbered.MatchString(line):
+			flushPara()
+			openList("ol")
+			b.WriteString("<li>" + notesInline(notesNumbered.FindStringSubmatch(line)[1]) + "</li>\n")
+		default:
+			closeList()
+			para = append(para, trimmed)
+		}
+	}
+	if inCode {
+		b.WriteString("</code></pre>\n")
+	}
+	flushPara()
+	closeList()
+	return b.String()
+}
+
+// notesInline renders inline code, bold, links, and bare URLs in one line of
+// markdown. Code spans are split out first so their contents stay literal.
+func notesInline(s string) string {
+	var b strings.Builder
+	for i, part := range strings.Split(s, "`") {
+		if i%2 == 1 {
+			b.WriteString("<code>" + html.EscapeString(part) + "</code>")
+			continue
+		}
+		part = html.EscapeString(part)
+		part = notesBold.ReplaceAllString(part, "<strong>$1</strong>")
+		// The URLs in the <a> tags added here follow a quote or ">", which
+		// notesBareURL does not match, so they are not linked twice
+		part = notesLink.ReplaceAllString(part, `<a href="$2" target="_blank" rel="noopener">$1</a>`)
+		part = notesBareURL.ReplaceAllString(part, `$1<a href="$2" target="_blank" rel="noopener">$2</a>`)
+		b.WriteString(part)
+	}
+	return b.String()
+}
+
+// NotesText renders release notes as plain text for a terminal: markup is
+// dropped, links become "text (url)", and each line is indented.
+func NotesText(md string) string {
+	var lines []string
+	for _, line := range strings.Split(strings.ReplaceAll(md, "\r\n", "\n"), "\n") {
+		line = strings.TrimRight(line, " \t")
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			continue
+		}
+		if m := notesHeading.FindStringSubmatch(strings.TrimSpace(line)); m != nil {
+			line = m[1]
+		}
+		line = notesLink.ReplaceAllString(line, "$1 ($2)")
+		line = notesBold.ReplaceAllString(line, "$1")
+		line = strings.ReplaceAll(line, "`", "")
+		// Collapse runs of blank lines
+		if line == "" && (len(lines) == 0 || lines[len(lines)-1] == "") {
+			continue
+		}
+		lines = append(lines, line)
+	}
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+	for i, line := range lines {
+		if line != "" {
+			lines[i] = "  " + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,165 @@
+package updater
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Installing an update leaves two files next to the binary:
+//
+//   - kibble.bak, a copy of the binary that was replaced, which Rollback
+//     restores.
+//   - kibble.update-status, the startup marker. It holds JSON such as
+//     {"state":"pending","version":"0.9.1","attempts":1}. Install writes it with
+//     state "pending". CheckStartup counts each start of the new binary in
+//     attempts, and MarkStartupOK sets state "ok" once the binary has run for
+//     StartupGracePeriod. A binary still "pending" after maxStartupAttempts
+//     starts is in a crash loop, and the backup is restored.
+const (
+	backupSuffix = ".bak"
+	statusSuffix = ".update-status"
+
+	maxStartupAttempts = 3
+)
+
+// StartupGracePeriod is how long a newly installed binary must run before its
+// startup counts as OK.
+const StartupGracePeriod = 30 * time.Second
+
+type startupStatus struct {
+	State    string `json:"state"` // "pending" or "ok"
+	Version  string `json:"version"`
+	Attempts int    `json:"attempts"`
+}
+
+// executablePath returns the running binary's path with symlinks resolved.
+func executablePath() (string, error) {
+	execPath, err := os.Executable()
+	if err != nil {
+		return "", fmt.Errorf("find executable path: %w", err)
+	}
+	execPath, err = filepath.EvalSymlinks(execPath)
+	if err != nil {
+		return "", fmt.Errorf("resolve symlinks: %w", err)
+	}
+	return execPath, nil
+}
+
+func readStatus(execPath string) (startupStatus, error) {
+	var st startupStatus
+	data, err := os.ReadFile(execPath + statusSuffix)
+	if err != nil {
+		return st, err
+	}
+	err = json.Unmarshal(data, &st)
+	return st, err
+}
+
+func writeStatus(execPath string, st startupStatus) error {
+	data, _ := json.Marshal(st)
+	return os.WriteFile(execPath+statusSuffix, data, 0644)
+}
+
+// CheckStartup counts a start of a newly installed binary. After
+// maxStartupAttempts starts without MarkStartupOK, it restores the previous
+// binary and reports true; the caller should then restart. It does nothing
+// when no update is pending.
+func CheckStartup() (rolledBack bool, err error) {
+	execPath, err := executablePath()
+	if err != nil {
+		return false, err
+	}
+	st, err := readStatus(execPath)
+	if errors.Is(err, fs.ErrNotExist) || (err == nil && st.State != "pending") {
+		return false, nil
+	}
+	if err != nil {
+		return false, fmt.Errorf("read startup marker: %w", err)
+	}
+
+	st.Attempts++
+	if st.Attempts <= maxStartupAttempts {
+		return false, writeStatus(execPath, st)
+	}
+
+	slog.Error("Updated binary failed to start, restoring the previous version",
+		"version", st.Version, "attempts", st.Attempts-1)
+	if err := Rollback(); err != nil {
+		return false, err
+	}
+	return true, nil
+}
+
+// MarkStartupOK records that a newly installed binary started successfully, so
+// later restarts are not counted toward a rollback.
+func MarkStartupOK() error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+	st, err := readStatus(execPath)
+	if err != nil || st.State != "pending" {
+		return nil
+	}
+	st.State = "ok"
+	slog.Info("Update startup OK", "version", st.Version)
+	return writeStatus(execPath, st)
+}
+
+// Rollback replaces the binary with kibble.bak, the copy saved by the last
+// update, and clears the startup marker.
+func Rollback() error {
+	execPath, err := executablePath()
+	if err != nil {
+		return err
+	}
+	backupPath := execPath + backupSuffix
+	if _, err := os.Stat(backupPath); err != nil {
+		return fmt.Errorf("no backup to restore at %s", backupPath)
+	}
+
+	tmpPath := execPath + ".rollback.tmp"
+	if err := copyFile(backupPath, tmpPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("copy backup: %w", err)
+	}
+	preserveSELinuxContext(execPath, tmpPath)
+	if err := os.Rename(tmpPath, execPath); err != nil {
+		os.Remove(tmpPath)
+		return fmt.Errorf("restore binary: %w", err)
+	}
+	os.Remove(execPath + statusSuffix)
+
+	slog.Info("Restored previous binary", "path", execPath, "from", backupPath)
+	return nil
+}
+
+// copyFile copies src to dst with src's permissions, replacing dst.
+func copyFile(src, dst string) error {
+	in, err := os.Open(src)
+	if err != nil {
+		return err
+	}
+	defer in.Close()
+	info, err := in.Stat()
+	if err != nil {
+		return err
+	}
+
+	out, err := os.OpenFile(dst, os.O_CREATE|os.O_WRONLY|os.O_TRUNC, info.Mode().Perm())
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(out, in); err != nil {
+		out.Close()
+		return err
+	}
+	return out.Close()
+}
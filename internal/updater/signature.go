@@ -0,0 +1,143 @@
+package updater
+
+import (
+	"bytes"
+	"context"
+	"crypto/ed25519"
+	"encoding/base64"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/blake2b"
+)
+
+// PublicKey is the minisign public key release binaries are signed with: the
+// base64 line of a minisign .pub file. It is embedded at build time with
+//
+//	-ldflags "-X github.com/thinkscotty/kibble/internal/updater.PublicKey=RW..."
+//
+// Builds without it cannot verify update signatures.
+var PublicKey string
+
+// signatureSuffix names a binary's detached minisign signature asset, e.g.
+// kibble-linux-amd64.minisig.
+const signatureSuffix = ".minisig"
+
+// minisign signature algorithms: "Ed" signs the file itself, "ED" signs its
+// BLAKE2b-512 hash (the default since minisign 0.10).
+const (
+	sigAlgPure      = "Ed"
+	sigAlgPrehashed = "ED"
+)
+
+// minisignPublicKey is a parsed minisign public key.
+type minisignPublicKey struct {
+	keyID [8]byte
+	key   ed25519.PublicKey
+}
+
+// parsePublicKey parses a minisign public key, given as its base64 line or as
+// the whole .pub file.
+func parsePublicKey(s string) (*minisignPublicKey, error) {
+	var line string
+	for _, l := range strings.Split(strings.TrimSpace(s), "\n") {
+		if l = strings.TrimSpace(l); l != "" && !strings.HasPrefix(l, "untrusted comment:") {
+			line = l
+			break
+		}
+	}
+	raw, err := base64.StdEncoding.DecodeString(line)
+	if err != nil || len(raw) != 2+8+ed25519.PublicKeySize || string(raw[:2]) != sigAlgPure {
+		return nil, fmt.Errorf("invalid minisign public key")
+	}
+	pk := &minisignPublicKey{key: ed25519.PublicKey(raw[10:])}
+	copy(pk.keyID[:], raw[2:10])
+	return pk, nil
+}
+
+// verifyMinisign checks a minisign signature file against the contents of the
+// file at path, including the signature over its trusted comment.
+func verifyMinisign(pk *minisignPublicKey, sigFile []byte, path string) error {
+	lines := strings.Split(strings.ReplaceAll(string(sigFile), "\r\n", "\n"), "\n")
+	if len(lines) < 4 || !strings.HasPrefix(lines[2], "trusted comment: ") {
+		return fmt.Errorf("malformed signature file")
+	}
+	sig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[1]))
+	if err != nil || len(sig) != 2+8+ed25519.SignatureSize {
+		return fmt.Errorf("malformed signature")
+	}
+	globalSig, err := base64.StdEncoding.DecodeString(strings.TrimSpace(lines[3]))
+	if err != nil || len(globalSig) != ed25519.SignatureSize {
+		return fmt.Errorf("malformed trusted comment signature")
+	}
+
+	alg, keyID, signature := string(sig[:2]), sig[2:10], sig[10:]
+	if !bytes.Equal(keyID, pk.keyID[:]) {
+		return fmt.Errorf("signed with key %X, not this build's key %X", reverse(keyID), reverse(pk.keyID[:]))
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	var message []byte
+	switch alg {
+	case sigAlgPrehashed:
+		h, _ := blake2b.New512(nil)
+		if _, err := io.Copy(h, f); err != nil {
+			return err
+		}
+		message = h.Sum(nil)
+	case sigAlgPure:
+		if message, err = io.ReadAll(f); err != nil {
+			return err
+		}
+	default:
+		return fmt.Errorf("unsupported signature algorithm %q", alg)
+	}
+
+	if !ed25519.Verify(pk.key, message, signature) {
+		return fmt.Errorf("signature does not match the download")
+	}
+	trusted := strings.TrimPrefix(lines[2], "trusted comment: ")
+	if !ed25519.Verify(pk.key, append(signature, trusted...), globalSig) {
+		return fmt.Errorf("trusted comment signature is invalid")
+	}
+	return nil
+}
+
+// reverse returns b in reverse order; minisign prints key IDs little-endian.
+func reverse(b []byte) []byte {
+	r := make([]byte, len(b))
+	for i := range b {
+		r[len(b)-1-i] = b[i]
+	}
+	return r
+}
+
+// fetchSignature downloads a detached signature asset.
+func fetchSignature(ctx context.Context, url string) ([]byte, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "kibble-updater")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("download signature: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return nil, fmt.Errorf("download signature: status %d", resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+}
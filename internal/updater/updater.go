@@ -1,7 +1,12 @@
 package updater
 
 import (
+	"bufio"
+	"bytes"
+	"cmp"
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"io"
@@ -10,6 +15,7 @@ import (
 	"os"
 	"os/exec"
 	"path/filepath"
+	"regexp"
 	"runtime"
 	"strconv"
 	"strings"
@@ -20,14 +26,17 @@ import (
 
 // ReleaseInfo holds the result of a version check against GitHub.
 type ReleaseInfo struct {
-	TagName     string
-	Version     string // TagName with leading "v" stripped
-	PublishedAt string
-	HTMLURL     string
-	Body        string // release notes (markdown)
-	AssetURL    string // direct download URL for the correct binary
-	AssetName   string
-	AssetSize   int64
+	TagName      string
+	Version      string // TagName with leading "v" stripped
+	PublishedAt  string
+	HTMLURL      string
+	Body         string // release notes (markdown)
+	AssetURL     string // direct download URL for the correct binary
+	AssetName    string
+	AssetSize    int64
+	ChecksumURL  string // download URL of the release's checksums file, "" if it has none
+	SignatureURL string // download URL of the binary's minisign signature, "" if it has none
+	Prerelease   bool
 }
 
 // UpdateResult describes what happened during an install attempt.
@@ -44,6 +53,8 @@ type ghRelease struct {
 	Body        string    `json:"body"`
 	HTMLURL     string    `json:"html_url"`
 	PublishedAt string    `json:"published_at"`
+	Prerelease  bool      `json:"prerelease"`
+	Draft       bool      `json:"draft"`
 	Assets      []ghAsset `json:"assets"`
 }
 
@@ -55,14 +66,73 @@ type ghAsset struct {
 
 var installMu sync.Mutex
 
-const githubAPI = "https://api.github.com/repos/thinkscotty/kibble/releases/latest"
+const (
+	githubAPI         = "https://api.github.com/repos/thinkscotty/kibble/releases/latest"
+	githubReleasesAPI = "https://api.github.com/repos/thinkscotty/kibble/releases?per_page=30"
+)
+
+// Update channels. Stable follows GitHub's latest release, which never
+// includes pre-releases; beta follows the newest release of any kind.
+const (
+	ChannelStable = "stable"
+	ChannelBeta   = "beta"
+)
+
+// checksumsAssetName is the release asset listing the SHA-256 of each binary,
+// in the format written by sha256sum.
+const checksumsAssetName = "kibble-checksums.txt"
 
 // CheckForUpdate queries the GitHub releases API and returns info about the
-// latest release, or nil if the current version is already up-to-date.
-func CheckForUpdate(ctx context.Context, currentVersion string) (*ReleaseInfo, error) {
-	req, err := http.NewRequestWithContext(ctx, "GET", githubAPI, nil)
+// latest release on the given channel, or nil if the current version is
+// already up-to-date.
+func CheckForUpdate(ctx context.Context, currentVersion, channel string) (*ReleaseInfo, error) {
+	var release ghRelease
+	if channel == ChannelBeta {
+		var releases []ghRelease
+		if err := getGitHub(ctx, githubReleasesAPI, &releases); err != nil {
+			return nil, err
+		}
+		newest, ok := newestRelease(releases)
+		if !ok {
+			return nil, nil // no release has a binary for this platform
+		}
+		release = newest
+	} else {
+		if err := getGitHub(ctx, githubAPI, &release); err != nil {
+			return nil, err
+		}
+	}
+
+	asset, ok := matchAsset(release.Assets)
+	if !ok {
+		return nil, fmt.Errorf("no binary found for %s/%s in release %s", runtime.GOOS, runtime.GOARCH, release.TagName)
+	}
+
+	latestVersion := strings.TrimPrefix(release.TagName, "v")
+	if !isNewer(currentVersion, latestVersion) {
+		return nil, nil // already up-to-date
+	}
+
+	return &ReleaseInfo{
+		TagName:      release.TagName,
+		Version:      latestVersion,
+		PublishedAt:  release.PublishedAt,
+		HTMLURL:      release.HTMLURL,
+		Body:         release.Body,
+		AssetURL:     asset.BrowserDownloadURL,
+		AssetName:    asset.Name,
+		AssetSize:    asset.Size,
+		ChecksumURL:  checksumURL(release.Assets),
+		SignatureURL: signatureURL(release.Assets, asset.Name),
+		Prerelease:   release.Prerelease,
+	}, nil
+}
+
+// getGitHub fetches a GitHub API URL and decodes the JSON response into out.
+func getGitHub(ctx context.Context, url string, out any) error {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
 	if err != nil {
-		return nil, fmt.Errorf("create request: %w", err)
+		return fmt.Errorf("create request: %w", err)
 	}
 	req.Header.Set("Accept", "application/vnd.github+json")
 	req.Header.Set("User-Agent", "kibble-updater")
@@ -70,57 +140,57 @@ func CheckForUpdate(ctx context.Context, currentVersion string) (*ReleaseInfo, e
 	client := &http.Client{Timeout: 15 * time.Second}
 	resp, err := client.Do(req)
 	if err != nil {
-		return nil, fmt.Errorf("GitHub API request failed: %w", err)
+		return fmt.Errorf("GitHub API request failed: %w", err)
 	}
 	defer resp.Body.Close()
 
 	if resp.StatusCode != 200 {
 		body, _ := io.ReadAll(resp.Body)
-		return nil, fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
+		return fmt.Errorf("GitHub API returned status %d: %s", resp.StatusCode, string(body))
 	}
 
-	var release ghRelease
-	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
-		return nil, fmt.Errorf("parse GitHub response: %w", err)
-	}
-
-	asset, ok := matchAsset(release.Assets)
-	if !ok {
-		return nil, fmt.Errorf("no binary found for %s/%s in release %s", runtime.GOOS, runtime.GOARCH, release.TagName)
+	if err := json.NewDecoder(resp.Body).Decode(out); err != nil {
+		return fmt.Errorf("parse GitHub response: %w", err)
 	}
+	return nil
+}
 
-	latestVersion := strings.TrimPrefix(release.TagName, "v")
-	if !isNewer(currentVersion, latestVersion) {
-		return nil, nil // already up-to-date
+// newestRelease picks the release with the highest version from a release
+// list, pre-releases included. Drafts, tags that are not versions, and
+// releases without a binary for this platform are skipped.
+func newestRelease(releases []ghRelease) (ghRelease, bool) {
+	var newest ghRelease
+	found := false
+	for _, r := range releases {
+		version := strings.TrimPrefix(r.TagName, "v")
+		if base, _ := splitPrerelease(version); r.Draft || !isSemver(base) {
+			continue
+		}
+		if _, ok := matchAsset(r.Assets); !ok {
+			continue
+		}
+		if !found || isNewer(strings.TrimPrefix(newest.TagName, "v"), version) {
+			newest, found = r, true
+		}
 	}
-
-	return &ReleaseInfo{
-		TagName:     release.TagName,
-		Version:     latestVersion,
-		PublishedAt: release.PublishedAt,
-		HTMLURL:     release.HTMLURL,
-		Body:        release.Body,
-		AssetURL:    asset.BrowserDownloadURL,
-		AssetName:   asset.Name,
-		AssetSize:   asset.Size,
-	}, nil
+	return newest, found
 }
 
 // DownloadAndInstall downloads the release asset and atomically replaces the
-// running binary.
-func DownloadAndInstall(ctx context.Context, info *ReleaseInfo, currentVersion string) (*UpdateResult, error) {
+// running binary. When the release has a checksums file, the download's SHA-256
+// must match the one listed for the asset, or the update is aborted. When this
+// build has a PublicKey and the release signs the binary, the signature must
+// verify too. requireSignature refuses any update whose signature cannot be
+// verified, including one without a signature.
+func DownloadAndInstall(ctx context.Context, info *ReleaseInfo, currentVersion string, requireSignature bool) (*UpdateResult, error) {
 	if !installMu.TryLock() {
 		return nil, fmt.Errorf("an update is already in progress")
 	}
 	defer installMu.Unlock()
 
-	execPath, err := os.Executable()
+	execPath, err := executablePath()
 	if err != nil {
-		return nil, fmt.Errorf("find executable path: %w", err)
-	}
-	execPath, err = filepath.EvalSymlinks(execPath)
-	if err != nil {
-		return nil, fmt.Errorf("resolve symlinks: %w", err)
+		return nil, err
 	}
 
 	dir := filepath.Dir(execPath)
@@ -128,6 +198,22 @@ func DownloadAndInstall(ctx context.Context, info *ReleaseInfo, currentVersion s
 		return nil, fmt.Errorf("cannot update: no write permission to %s", dir)
 	}
 
+	// Fetch the expected checksum first, so a bad checksums file fails before the download
+	var wantSum string
+	if info.ChecksumURL != "" {
+		wantSum, err = fetchChecksum(ctx, info.ChecksumURL, info.AssetName)
+		if err != nil {
+			return nil, fmt.Errorf("get checksum: %w", err)
+		}
+	} else {
+		slog.Warn("Release has no checksums file, skipping checksum verification", "release", info.TagName)
+	}
+
+	sigFile, pubKey, err := prepareSignature(ctx, info, requireSignature)
+	if err != nil {
+		return nil, err
+	}
+
 	tmpPath := execPath + ".update.tmp"
 	os.Remove(tmpPath) // clean up any stale temp file
 
@@ -155,7 +241,8 @@ func DownloadAndInstall(ctx context.Context, info *ReleaseInfo, currentVersion s
 		return nil, fmt.Errorf("create temp file: %w", err)
 	}
 
-	written, copyErr := io.Copy(f, resp.Body)
+	hash := sha256.New()
+	written, copyErr := io.Copy(io.MultiWriter(f, hash), resp.Body)
 	f.Close()
 
 	if copyErr != nil {
@@ -168,18 +255,44 @@ func DownloadAndInstall(ctx context.Context, info *ReleaseInfo, currentVersion s
 		return nil, fmt.Errorf("download size mismatch: expected %d bytes, got %d", info.AssetSize, written)
 	}
 
+	if wantSum != "" {
+		if gotSum := hex.EncodeToString(hash.Sum(nil)); gotSum != wantSum {
+			os.Remove(tmpPath)
+			return nil, fmt.Errorf("checksum mismatch for %s: expected SHA-256 %s, got %s; the download may be corrupt or tampered with", info.AssetName, wantSum, gotSum)
+		}
+		slog.Info("Checksum verified", "sha256", wantSum)
+	}
+
+	if sigFile != nil {
+		if err := verifyMinisign(pubKey, sigFile, tmpPath); err != nil {
+			os.Remove(tmpPath)
+			return nil, fmt.Errorf("signature verification failed for %s: %w", info.AssetName, err)
+		}
+		slog.Info("Signature verified", "asset", info.AssetName)
+	}
+
 	slog.Info("Download complete", "bytes", written)
 
 	// Preserve SELinux context if applicable
 	preserveSELinuxContext(execPath, tmpPath)
 
+	// Keep the current binary so Rollback can restore it
+	if err := copyFile(execPath, execPath+backupSuffix); err != nil {
+		os.Remove(tmpPath)
+		return nil, fmt.Errorf("back up current binary: %w", err)
+	}
+
 	// Atomic replace
 	if err := os.Rename(tmpPath, execPath); err != nil {
 		os.Remove(tmpPath)
 		return nil, fmt.Errorf("replace binary: %w", err)
 	}
 
-	slog.Info("Binary replaced successfully", "path", execPath)
+	slog.Info("Binary replaced successfully", "path", execPath, "backup", execPath+backupSuffix)
+
+	if err := writeStatus(execPath, startupStatus{State: "pending", Version: info.Version}); err != nil {
+		slog.Warn("Could not write startup marker; a failed start will not roll back automatically", "error", err)
+	}
 
 	return &UpdateResult{
 		OldVersion: currentVersion,
@@ -312,7 +425,105 @@ func matchAsset(assets []ghAsset) (ghAsset, bool) {
 	return ghAsset{}, false
 }
 
-// isNewer returns true if latest is newer than current.
+// prepareSignature fetches the binary's signature and parses this build's
+// public key before anything is downloaded. It returns a nil signature when
+// there is nothing to verify, which is an error if requireSignature is set.
+func prepareSignature(ctx context.Context, info *ReleaseInfo, requireSignature bool) ([]byte, *minisignPublicKey, error) {
+	if PublicKey == "" {
+		if requireSignature {
+			return nil, nil, fmt.Errorf("signatures are required, but this build has no update public key to verify them with")
+		}
+		return nil, nil, nil
+	}
+	pubKey, err := parsePublicKey(PublicKey)
+	if err != nil {
+		return nil, nil, fmt.Errorf("update public key: %w", err)
+	}
+	if info.SignatureURL == "" {
+		if requireSignature {
+			return nil, nil, fmt.Errorf("signatures are required, but release %s has no %s%s", info.TagName, info.AssetName, signatureSuffix)
+		}
+		slog.Warn("Release has no signature, skipping signature verification", "release", info.TagName)
+		return nil, nil, nil
+	}
+	sigFile, err := fetchSignature(ctx, info.SignatureURL)
+	if err != nil {
+		return nil, nil, err
+	}
+	return sigFile, pubKey, nil
+}
+
+// signatureURL returns the download URL of assetName's signature, or "".
+func signatureURL(assets []ghAsset, assetName string) string {
+	for _, a := range assets {
+		if a.Name == assetName+signatureSuffix {
+			return a.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// checksumURL returns the download URL of the release's checksums file, or "".
+func checksumURL(assets []ghAsset) string {
+	for _, a := range assets {
+		if a.Name == checksumsAssetName {
+			return a.BrowserDownloadURL
+		}
+	}
+	return ""
+}
+
+// fetchChecksum downloads a checksums file and returns the SHA-256 it lists
+// for assetName.
+func fetchChecksum(ctx context.Context, url, assetName string) (string, error) {
+	req, err := http.NewRequestWithContext(ctx, "GET", url, nil)
+	if err != nil {
+		return "", fmt.Errorf("create request: %w", err)
+	}
+	req.Header.Set("User-Agent", "kibble-updater")
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("download %s: %w", checksumsAssetName, err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode != 200 {
+		return "", fmt.Errorf("download %s: status %d", checksumsAssetName, resp.StatusCode)
+	}
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+	if err != nil {
+		return "", fmt.Errorf("read %s: %w", checksumsAssetName, err)
+	}
+	return parseChecksum(data, assetName)
+}
+
+// parseChecksum finds assetName in sha256sum output ("<hex>  <name>", or
+// "<hex> *<name>" for binary mode) and returns its hash in lowercase.
+func parseChecksum(data []byte, assetName string) (string, error) {
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		fields := strings.Fields(scanner.Text())
+		if len(fields) != 2 || strings.TrimPrefix(fields[1], "*") != assetName {
+			continue
+		}
+		sum := strings.ToLower(fields[0])
+		if _, err := hex.DecodeString(sum); err != nil || len(sum) != sha256.Size*2 {
+			return "", fmt.Errorf("invalid SHA-256 %q for %s in %s", fields[0], assetName, checksumsAssetName)
+		}
+		return sum, nil
+	}
+	return "", fmt.Errorf("%s has no entry for %s", checksumsAssetName, assetName)
+}
+
+// gitDescribeSuffix matches the "-3-gabcdef1" that git describe appends to
+// builds made after a tag.
+var gitDescribeSuffix = regexp.MustCompile(`-\d+-g[0-9a-f]+$`)
+
+// isNewer returns true if latest is newer than current. Versions may carry a
+// pre-release suffix such as "-beta.1", which ranks below the same version
+// without one.
 func isNewer(current, latest string) bool {
 	current = strings.TrimPrefix(current, "v")
 	latest = strings.TrimPrefix(latest, "v")
@@ -321,10 +532,10 @@ func isNewer(current, latest string) bool {
 	current = strings.TrimSuffix(current, "-dirty")
 
 	// Handle git describe output like "0.8.2-3-gabcdef1"
-	// Extract just the version number before the dash
-	if idx := strings.Index(current, "-"); idx > 0 {
-		current = current[:idx]
-	}
+	current = gitDescribeSuffix.ReplaceAllString(current, "")
+
+	current, curPre := splitPrerelease(current)
+	latest, latPre := splitPrerelease(latest)
 
 	// If current is "dev" or a commit hash, any release is newer
 	if current == "dev" || current == "unknown" || !isSemver(current) {
@@ -342,7 +553,47 @@ func isNewer(current, latest string) bool {
 			return false
 		}
 	}
-	return false // equal
+	return comparePrerelease(latPre, curPre) > 0
+}
+
+// splitPrerelease splits "0.9.0-beta.1" into "0.9.0" and "beta.1".
+func splitPrerelease(version string) (base, pre string) {
+	base, pre, _ = strings.Cut(version, "-")
+	return base, pre
+}
+
+// comparePrerelease orders pre-release suffixes by semver rules: no suffix
+// ranks highest, numeric identifiers compare as numbers and below
+// alphanumeric ones, and a shorter list of equal identifiers ranks lower.
+func comparePrerelease(a, b string) int {
+	switch {
+	case a == b:
+		return 0
+	case a == "":
+		return 1
+	case b == "":
+		return -1
+	}
+	as, bs := strings.Split(a, "."), strings.Split(b, ".")
+	for i := 0; i < len(as) && i < len(bs); i++ {
+		an, aErr := strconv.Atoi(as[i])
+		bn, bErr := strconv.Atoi(bs[i])
+		var c int
+		switch {
+		case aErr == nil && bErr == nil:
+			c = cmp.Compare(an, bn)
+		case aErr == nil:
+			c = -1
+		case bErr == nil:
+			c = 1
+		default:
+			c = strings.Compare(as[i], bs[i])
+		}
+		if c != 0 {
+			return c
+		}
+	}
+	return cmp.Compare(len(as), len(bs))
 }
 
 func isSemver(s string) bool {
@@ -1,6 +1,17 @@
 package updater
 
-import "testing"
+import (
+	"bytes"
+	"crypto/ed25519"
+	"encoding/base64"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"testing"
+
+	"golang.org/x/crypto/blake2b"
+)
 
 func TestIsNewer(t *testing.T) {
 	tests := []struct {
@@ -51,6 +62,18 @@ func TestIsNewer(t *testing.T) {
 		{"1.2.3", "1.2.4", true},
 		{"1.2.3", "1.3.0", true},
 		{"1.2.3", "2.0.0", true},
+
+		// Pre-releases
+		{"0.9.0-beta.1", "0.9.0", true},
+		{"0.9.0", "0.9.0-beta.1", false},
+		{"0.8.2", "0.9.0-beta.1", true},
+		{"0.9.0-beta.1", "0.9.0-beta.2", true},
+		{"0.9.0-beta.2", "0.9.0-beta.1", false},
+		{"0.9.0-alpha", "0.9.0-beta", true},
+		{"0.9.0-beta.2", "0.9.0-beta.10", true},
+		{"0.9.0-beta", "0.9.0-beta.1", true},
+		{"0.9.0-beta.1-3-gabcdef1", "0.9.0-beta.1", false},
+		{"0.9.0-beta.1-3-gabcdef1-dirty", "0.9.0", true},
 	}
 
 	for _, tt := range tests {
@@ -63,6 +86,58 @@ func TestIsNewer(t *testing.T) {
 	}
 }
 
+func TestNewestRelease(t *testing.T) {
+	asset := []ghAsset{{Name: "kibble-" + runtime.GOOS + "-" + runtime.GOARCH}}
+	tests := []struct {
+		name     string
+		releases []ghRelease
+		want     string
+		wantOK   bool
+	}{
+		{
+			name: "pre-release newer than stable",
+			releases: []ghRelease{
+				{TagName: "v0.8.2", Assets: asset},
+				{TagName: "v0.9.0-beta.1", Prerelease: true, Assets: asset},
+			},
+			want: "v0.9.0-beta.1", wantOK: true,
+		},
+		{
+			name: "stable release of the same version wins",
+			releases: []ghRelease{
+				{TagName: "v0.9.0-beta.2", Prerelease: true, Assets: asset},
+				{TagName: "v0.9.0", Assets: asset},
+				{TagName: "v0.9.0-beta.1", Prerelease: true, Assets: asset},
+			},
+			want: "v0.9.0", wantOK: true,
+		},
+		{
+			name: "drafts, non-version tags and releases without a binary are skipped",
+			releases: []ghRelease{
+				{TagName: "v0.8.2", Assets: asset},
+				{TagName: "v0.9.0", Draft: true, Assets: asset},
+				{TagName: "nightly", Prerelease: true, Assets: asset},
+				{TagName: "v0.9.1-beta.1", Prerelease: true},
+			},
+			want: "v0.8.2", wantOK: true,
+		},
+		{
+			name:     "nothing usable",
+			releases: []ghRelease{{TagName: "v0.9.0"}},
+			wantOK:   false,
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, ok := newestRelease(tt.releases)
+			if ok != tt.wantOK || got.TagName != tt.want {
+				t.Errorf("newestRelease() = %q, %v, want %q, %v", got.TagName, ok, tt.want, tt.wantOK)
+			}
+		})
+	}
+}
+
 func TestIsSemver(t *testing.T) {
 	tests := []struct {
 		version string
@@ -172,3 +247,139 @@ func TestMatchAsset(t *testing.T) {
 		})
 	}
 }
+
+func TestParseChecksum(t *testing.T) {
+	sumA := "9f86d081884c7d659a2feaa0c55ad015a3bf4f1b2b0b822cd15d6c15b0f00a08"
+	sumB := "60303ae22b998861bce3b28f33eec1be758a213c86c93c076dbe9f558c11c752"
+	data := []byte(sumA + "  kibble-linux-amd64\n" +
+		strings.ToUpper(sumB) + " *kibble-windows-amd64.exe\n" +
+		"not-a-hash  kibble-linux-arm\n")
+
+	tests := []struct {
+		name    string
+		asset   string
+		want    string
+		wantErr bool
+	}{
+		{"Text mode", "kibble-linux-amd64", sumA, false},
+		{"Binary mode, uppercase", "kibble-windows-amd64.exe", sumB, false},
+		{"Invalid hash", "kibble-linux-arm", "", true},
+		{"Missing asset", "kibble-darwin-arm64", "", true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseChecksum(data, tt.asset)
+			if (err != nil) != tt.wantErr {
+				t.Fatalf("parseChecksum(%q) error = %v, wantErr %v", tt.asset, err, tt.wantErr)
+			}
+			if got != tt.want {
+				t.Errorf("parseChecksum(%q) = %q, want %q", tt.asset, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestVerifyMinisign(t *testing.T) {
+	pub, priv, _ := ed25519.GenerateKey(nil)
+	keyID := []byte{1, 2, 3, 4, 5, 6, 7, 8}
+	pubKey, err := parsePublicKey("untrusted comment: minisign public key\n" +
+		base64.StdEncoding.EncodeToString(append(append([]byte("Ed"), keyID...), pub...)) + "\n")
+	if err != nil {
+		t.Fatalf("parsePublicKey: %v", err)
+	}
+
+	binary := []byte("new kibble binary")
+	path := filepath.Join(t.TempDir(), "kibble.update.tmp")
+	os.WriteFile(path, binary, 0o644)
+
+	// sign builds a minisign signature file the way minisign -S does
+	sign := func(alg string, id, message []byte, trusted string) []byte {
+		if alg == sigAlgPrehashed {
+			h := blake2b.Sum512(message)
+			message = h[:]
+		}
+		sig := ed25519.Sign(priv, message)
+		global := ed25519.Sign(priv, append(append([]byte{}, sig...), trusted...))
+		return []byte("untrusted comment: signature from minisign secret key\n" +
+			base64.StdEncoding.EncodeToString(append(append([]byte(alg), id...), sig...)) + "\n" +
+			"trusted comment: " + trusted + "\n" +
+			base64.StdEncoding.EncodeToString(global) + "\n")
+	}
+
+	tampered := sign(sigAlgPrehashed, keyID, binary, "timestamp:1700000000")
+	tampered = bytes.Replace(tampered, []byte("timestamp:1700000000"), []byte("timestamp:1800000000"), 1)
+
+	tests := []struct {
+		name    string
+		sigFile []byte
+		wantErr bool
+	}{
+		{"Prehashed", sign(sigAlgPrehashed, keyID, binary, "timestamp:1700000000"), false},
+		{"Legacy", sign(sigAlgPure, keyID, binary, "timestamp:1700000000"), false},
+		{"Different file", sign(sigAlgPrehashed, keyID, []byte("other binary"), "timestamp:1700000000"), true},
+		{"Other key", sign(sigAlgPrehashed, []byte{8, 7, 6, 5, 4, 3, 2, 1}, binary, "timestamp:1700000000"), true},
+		{"Edited trusted comment", tampered, true},
+		{"Malformed", []byte("not a signature"), true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := verifyMinisign(pubKey, tt.sigFile, path)
+			if (err != nil) != tt.wantErr {
+				t.Errorf("verifyMinisign() error = %v, wantErr %v", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+func TestNotesHTML(t *testing.T) {
+	tests := []struct {
+		name string
+		md   string
+		want string
+	}{
+		{
+			name: "heading and list",
+			md:   "## What's Changed\n* Fix the `news` page by @someone\n* Add **beta** channel",
+			want: "<p><strong>What&#39;s Changed</strong></p>\n<ul>\n<li>Fix the <code>news</code> page by @someone</li>\n<li>Add <strong>beta</strong> channel</li>\n</ul>\n",
+		},
+		{
+			name: "links and bare URLs",
+			md:   "See [the docs](https://example.com/a?b=1&c=2).\n\n**Full Changelog**: https://github.com/x/y/compare/v1...v2",
+			want: "<p>See <a href=\"https://example.com/a?b=1&amp;c=2\" target=\"_blank\" rel=\"noopener\">the docs</a>.</p>\n" +
+				"<p><strong>Full Changelog</strong>: <a href=\"https://github.com/x/y/compare/v1...v2\" target=\"_blank\" rel=\"noopener\">https://github.com/x/y/compare/v1...v2</a></p>\n",
+		},
+		{
+			name: "markup is escaped",
+			md:   "<script>alert(1)</script> [x](javascript:alert(1))",
+			want: "<p>&lt;script&gt;alert(1)&lt;/script&gt; [x](javascript:alert(1))</p>\n",
+		},
+		{
+			name: "fenced code",
+			md:   "```\nkibble -update <b>\n```",
+			want: "<pre><code>kibble -update &lt;b&gt;\n</code></pre>\n",
+		},
+		{
+			name: "empty",
+			md:   "  \n",
+			want: "",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := NotesHTML(tt.md); got != tt.want {
+				t.Errorf("NotesHTML() =\n%s\nwant\n%s", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNotesText(t *testing.T) {
+	md := "## What's Changed\r\n* Add **beta** channel in [#42](https://github.com/x/y/pull/42)\r\n\r\n\r\n```\r\nkibble -update\r\n```\r\n"
+	want := "  What's Changed\n  * Add beta channel in #42 (https://github.com/x/y/pull/42)\n\n  kibble -update"
+	if got := NotesText(md); got != want {
+		t.Errorf("NotesText() =\n%q\nwant\n%q", got, want)
+	}
+}
@@ -76,6 +76,11 @@ func (c *Client) Search(ctx context.Context, query string, limit int) ([]SearchR
 	return result.Query.Search, nil
 }
 
+// ArticleURL returns the canonical English Wikipedia URL for an article title.
+func ArticleURL(title string) string {
+	return "https://en.wikipedia.org/wiki/" + url.PathEscape(strings.ReplaceAll(title, " ", "_"))
+}
+
 // GetSummary fetches a concise article summary using the REST API.
 func (c *Client) GetSummary(ctx context.Context, title string) (string, error) {
 	encoded := url.PathEscape(strings.ReplaceAll(title, " ", "_"))